@@ -0,0 +1,209 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/gin-gonic/gin"
+)
+
+var errDisableSelf = errors.New("admin can't disable own account")
+
+// likePatternReplacer escapes wildcards of LIKE pattern, so search matches them literally.
+// Backslash is the default escape character of postgres
+var likePatternReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type adminUserResponse struct {
+	userProfileResponse
+	Role       string     `json:"role"`
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+func newAdminUserResponse(user db.User) adminUserResponse {
+	response := adminUserResponse{
+		userProfileResponse: newUserProfileResponse(user),
+		Role:                user.Role,
+	}
+
+	if user.DisabledAt.Valid {
+		response.DisabledAt = &user.DisabledAt.Time
+	}
+
+	return response
+}
+
+type listUsersData struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=50"`
+	Search   string `form:"search"`
+}
+
+type listUsersResponse struct {
+	Users []adminUserResponse `json:"users"`
+}
+
+// listUsers returns page of users ordered by id, search matches part of username or email
+func (s *Server) listUsers(ctx *gin.Context) {
+	var data listUsersData
+	if err := ctx.ShouldBindQuery(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.ListUsersParams{
+		Search: sql.NullString{
+			String: likePatternReplacer.Replace(data.Search),
+			Valid:  data.Search != "",
+		},
+		Limit:  data.PageSize,
+		Offset: (data.PageID - 1) * data.PageSize,
+	}
+
+	users, err := s.store.ListUsers(ctx, params)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := listUsersResponse{
+		Users: make([]adminUserResponse, len(users)),
+	}
+
+	for i, user := range users {
+		response.Users[i] = newAdminUserResponse(user)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getUser(ctx *gin.Context) {
+	user, ok := s.getRequestedUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// getUserUsage returns how many lists, tasks, active sessions and personal access tokens user has
+func (s *Server) getUserUsage(ctx *gin.Context) {
+	usage, err := s.store.GetUserUsage(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, usage)
+}
+
+// disableUser suspends account of user keeping its data, all its sessions are ended
+func (s *Server) disableUser(ctx *gin.Context) {
+	userId := ctx.MustGet(userIdKey).(int32)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if userId == authPayload.UserID {
+		ctx.JSON(http.StatusConflict, errorResponse(errDisableSelf, ""))
+		return
+	}
+
+	result, err := s.store.DisableUserTx(ctx, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(result.User))
+}
+
+func (s *Server) enableUser(ctx *gin.Context) {
+	user, err := s.store.EnableUser(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// logoutUser blocks all sessions of user and rejects its access tokens issued before
+func (s *Server) logoutUser(ctx *gin.Context) {
+	user, ok := s.getRequestedUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.store.BlockUserSessions(ctx, user.Username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// unlockLogin forgets failed login attempts of username or client IP, so login isn't delayed or locked out anymore.
+// With in-memory attempt store only attempts kept by this server instance are forgotten
+func (s *Server) unlockLogin(ctx *gin.Context) {
+	// key is matched by wildcard since username can contain slashes
+	value := strings.TrimPrefix(ctx.Param("key"), "/")
+	if value == "" {
+		err := errors.New("key is missing")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	var key string
+
+	switch ctx.Param("kind") {
+	case "user":
+		key = lockout.UsernameKey(value)
+	case "ip":
+		key = lockout.IPKey(value)
+	default:
+		err := errors.New(`kind of key must be "user" or "ip"`)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if err := s.loginLimiter.Reset(ctx, key); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (s *Server) getRequestedUser(ctx *gin.Context) (db.User, bool) {
+	user, err := s.store.GetUserByID(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return db.User{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return db.User{}, false
+	}
+
+	return user, true
+}
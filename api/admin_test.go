@@ -0,0 +1,636 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// adminCalls expects calls made by auth middlewares for the requests of admin
+func adminCalls(store *mockdb.MockStore, admin util.FullUserInfo, role string) *gomock.Call {
+	return store.EXPECT().
+		GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).
+		Times(1).
+		Return(db.User{ID: admin.ID, Username: admin.Username, Role: role}, nil).
+		After(authorizedCalls(store, admin))
+}
+
+func adminAuth(admin util.FullUserInfo) setupAuthFunc {
+	return func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, admin.ID, time.Minute)
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	admin := util.RandomUser()
+
+	testCases := []*apiTestCase{
+		{
+			name:          "NotAdmin",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleUser)
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "MissingScope",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				access := token.Access{Scopes: []string{token.ScopeListsRead}}
+				addScopedAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, admin.ID, access, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, admin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "LegacyToken",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, 0, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, admin)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(db.User{ID: admin.ID, Username: admin.Username, Role: db.RoleAdmin}, nil)
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.User{}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "AdminDoesNotExist",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, admin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, admin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "Unauthorized",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestListUsersAPI(t *testing.T) {
+	admin := util.RandomUser()
+
+	users := []db.User{
+		{ID: util.RandomID(), Username: util.RandomUsername(), Role: db.RoleUser},
+		{
+			ID:         util.RandomID(),
+			Username:   util.RandomUsername(),
+			Role:       db.RoleUser,
+			DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=2&page_size=5&search=john",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				params := db.ListUsersParams{
+					Search: sql.NullString{String: "john", Valid: true},
+					Limit:  5,
+					Offset: 5,
+				}
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hash")
+
+				response := unmarshal[listUsersResponse](t, recorder.Body)
+				require.Len(t, response.Users, len(users))
+				require.Nil(t, response.Users[0].DisabledAt)
+				require.NotNil(t, response.Users[1].DisabledAt)
+			},
+		},
+		{
+			name:          "SearchWithWildcards",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5&search=" + url.QueryEscape(`j%_\n`),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				params := db.ListUsersParams{
+					Search: sql.NullString{String: `j\%\_\\n`, Valid: true},
+					Limit:  5,
+					Offset: 0,
+				}
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return([]db.User{}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "WithoutSearch",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Eq(db.ListUsersParams{Limit: 5, Offset: 0})).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "InvalidPage",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=0&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodGet,
+			requestUrl:    "/admin/users?page_id=1&page_size=5",
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					ListUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetUserUsageAPI(t *testing.T) {
+	admin := util.RandomUser()
+	userId := util.RandomID()
+
+	usage := db.GetUserUsageRow{
+		Lists:                2,
+		Tasks:                10,
+		ActiveSessions:       1,
+		PersonalAccessTokens: 3,
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/usage", userId),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					GetUserUsage(gomock.Any(), gomock.Eq(userId)).
+					Times(1).
+					Return(usage, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, usage, *unmarshal[db.GetUserUsageRow](t, recorder.Body))
+			},
+		},
+		{
+			name:          "NotFound",
+			requestMethod: http.MethodGet,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/usage", userId),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					GetUserUsage(gomock.Any(), gomock.Eq(userId)).
+					Times(1).
+					Return(db.GetUserUsageRow{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDisableUserAPI(t *testing.T) {
+	admin := util.RandomUser()
+	user := util.RandomUser()
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/disable", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				result := db.DisableUserTxResult{
+					User: db.User{
+						ID:         user.ID,
+						Username:   user.Username,
+						DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+					},
+				}
+
+				store.EXPECT().
+					DisableUserTx(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[adminUserResponse](t, recorder.Body)
+				require.NotNil(t, response.DisabledAt)
+			},
+		},
+		{
+			name:          "Self",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/disable", admin.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					DisableUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "NotFound",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/disable", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					DisableUserTx(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.DisableUserTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/disable", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					DisableUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DisableUserTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestEnableUserAPI(t *testing.T) {
+	admin := util.RandomUser()
+	user := util.RandomUser()
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/enable", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					EnableUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[adminUserResponse](t, recorder.Body)
+				require.Nil(t, response.DisabledAt)
+			},
+		},
+		{
+			name:          "NotFound",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/enable", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					EnableUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestLogoutUserAPI(t *testing.T) {
+	admin := util.RandomUser()
+	user := util.RandomUser()
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodDelete,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/sessions", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username}, nil)
+
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "NotFound",
+			requestMethod: http.MethodDelete,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/sessions", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodDelete,
+			requestUrl:    fmt.Sprintf("/admin/users/%d/sessions", user.ID),
+			setupAuth:     adminAuth(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				adminCalls(store, admin, db.RoleAdmin)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username}, nil)
+
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestUnlockLoginAPI(t *testing.T) {
+	admin := util.RandomUser()
+
+	testCases := []struct {
+		name          string
+		url           string
+		key           string // locked before the request
+		role          string
+		checkResponse checkResponseFunc
+		unlocked      bool
+	}{
+		{
+			name:          "User",
+			url:           "/admin/login-locks/user/john",
+			key:           lockout.UsernameKey("john"),
+			role:          db.RoleAdmin,
+			checkResponse: requierResponseCode(http.StatusNoContent),
+			unlocked:      true,
+		},
+		{
+			name:          "UsernameWithSlash",
+			url:           "/admin/login-locks/user/john/doe",
+			key:           lockout.UsernameKey("john/doe"),
+			role:          db.RoleAdmin,
+			checkResponse: requierResponseCode(http.StatusNoContent),
+			unlocked:      true,
+		},
+		{
+			name:          "IP",
+			url:           "/admin/login-locks/ip/2001:db8::1",
+			key:           lockout.IPKey("2001:db8::1"),
+			role:          db.RoleAdmin,
+			checkResponse: requierResponseCode(http.StatusNoContent),
+			unlocked:      true,
+		},
+		{
+			name:          "UnknownKind",
+			url:           "/admin/login-locks/email/john",
+			key:           lockout.UsernameKey("john"),
+			role:          db.RoleAdmin,
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NoKey",
+			url:           "/admin/login-locks/user/",
+			key:           lockout.UsernameKey("john"),
+			role:          db.RoleAdmin,
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NotAdmin",
+			url:           "/admin/login-locks/user/john",
+			key:           lockout.UsernameKey("john"),
+			role:          db.RoleUser,
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			adminCalls(store, admin, tc.role)
+
+			// test server keeps attempts in memory
+			server := newTestServer(t, store)
+
+			for i := int32(0); i < lockout.DefaultPolicy.MaxAttempts; i++ {
+				require.NoError(t, server.loginLimiter.Fail(context.Background(), tc.key))
+			}
+
+			request, err := http.NewRequest(http.MethodDelete, tc.url, nil)
+			require.NoError(t, err)
+
+			adminAuth(admin)(t, request, server.tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+
+			retryAfter, err := server.loginLimiter.Check(context.Background(), tc.key)
+			require.NoError(t, err)
+			require.Equal(t, tc.unlocked, retryAfter == 0)
+		})
+	}
+}
+
+func TestUnlockLoginAPIPostgresStore(t *testing.T) {
+	admin := util.RandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		adminCalls(store, admin, db.RoleAdmin),
+
+		store.EXPECT().
+			DeleteLoginAttempts(gomock.Any(), gomock.Eq(lockout.UsernameKey("john"))).
+			Times(1).
+			Return(nil),
+	)
+
+	config := newTestConfig()
+	config.LoginAttemptStore = loginAttemptStorePostgres
+
+	server := newTestServerWithConfig(t, store, config)
+
+	request, err := http.NewRequest(http.MethodDelete, "/admin/login-locks/user/john", nil)
+	require.NoError(t, err)
+
+	adminAuth(admin)(t, request, server.tokenMaker)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
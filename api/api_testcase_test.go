@@ -47,9 +47,10 @@ func apiTestingFunc(tc *apiTestCase) func(*testing.T) {
 		request, err := http.NewRequest(tc.requestMethod, tc.requestUrl, bytes.NewReader(data))
 		require.NoError(t, err)
 
-		tc.setupAuth(t, request, server.pasetoMaker)
+		tc.setupAuth(t, request, server.tokenMaker)
 
 		server.router.ServeHTTP(recorder, request)
+		server.background.Wait()
 
 		tc.checkResponse(t, recorder)
 	}
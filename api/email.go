@@ -0,0 +1,170 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/gin-gonic/gin"
+)
+
+const emailVerificationSubject = "Email verification"
+
+var (
+	errNoEmail              = errors.New("user doesn't have email")
+	errEmailAlreadyVerified = errors.New("email is already verified")
+)
+
+// sendEmailVerification mails verification token to the new email of user
+func (s *Server) sendEmailVerification(email, username, verificationToken string) error {
+	body := fmt.Sprintf(
+		"Hello, %s!\n\nUse this token to verify your email: %s\nIt expires in %s. If you didn't set this email for your account, ignore this email.",
+		username, verificationToken, s.config.EmailVerificationTokenDuration,
+	)
+
+	return s.mailer.SendEmail(email, emailVerificationSubject, body)
+}
+
+type changeEmailData struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// changeEmail mails verification token to the new email of user,
+// email of user is changed only when the token is used
+func (s *Server) changeEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var data changeEmailData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err := util.CheckPassword(data.Password, user.Hash); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err, "wrong actual password"))
+		return
+	}
+
+	verificationToken, err := util.GenerateMailToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
+		return
+	}
+
+	params := db.ChangeEmailTxParams{
+		UserID:                user.ID,
+		Email:                 data.Email,
+		VerificationTokenHash: util.HashMailToken(verificationToken),
+		VerificationExpiresAt: time.Now().Add(s.config.EmailVerificationTokenDuration),
+	}
+
+	if _, err := s.store.ChangeEmailTx(ctx, params); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err := s.sendEmailVerification(data.Email, user.Username, verificationToken); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot send email"))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
+
+// resendEmailVerification mails a new verification token to unverified email of user
+func (s *Server) resendEmailVerification(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if !user.Email.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(errNoEmail, ""))
+		return
+	}
+
+	if user.EmailVerified {
+		ctx.JSON(http.StatusConflict, errorResponse(errEmailAlreadyVerified, ""))
+		return
+	}
+
+	verificationToken, err := util.GenerateMailToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
+		return
+	}
+
+	params := db.CreateEmailVerificationTokenParams{
+		TokenHash: util.HashMailToken(verificationToken),
+		UserID:    user.ID,
+		Email:     user.Email.String,
+		ExpiresAt: time.Now().Add(s.config.EmailVerificationTokenDuration),
+	}
+
+	if _, err := s.store.CreateEmailVerificationToken(ctx, params); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err := s.sendEmailVerification(user.Email.String, user.Username, verificationToken); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot send email"))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
+
+type verifyEmailData struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// verifyEmail marks email which the token has been sent to as verified
+func (s *Server) verifyEmail(ctx *gin.Context) {
+	var data verifyEmailData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if _, err := s.store.VerifyEmailTx(ctx, util.HashMailToken(data.Token)); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err, "invalid or expired verification token"))
+			return
+		}
+
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "email is used by other user"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,400 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/mail"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// mailedToken finds hex encoded token in mailbox
+func mailedToken(t *testing.T, mailbox *bytes.Buffer) string {
+	mailToken := regexp.MustCompile(`[0-9a-f]{64}`).FindString(mailbox.String())
+	require.NotEmpty(t, mailToken)
+
+	return mailToken
+}
+
+func TestChangeEmailAPI(t *testing.T) {
+	user := util.RandomUser()
+	email := util.RandomEmail()
+
+	defaultSettings := struct {
+		methodPut string
+		url       string
+		body      requestBody
+		setupAuth setupAuthFunc
+	}{
+		methodPut: http.MethodPut,
+		url:       fmt.Sprintf("/users/%d/email", user.ID),
+		body:      requestBody{"email": email, "password": user.Password},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getUserCall(store, user),
+
+					store.EXPECT().
+						ChangeEmailTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.ChangeEmailTxParams) (db.ChangeEmailTxResult, error) {
+							require.Equal(t, user.ID, arg.UserID)
+							require.Equal(t, email, arg.Email)
+							require.NotEmpty(t, arg.VerificationTokenHash)
+							require.WithinDuration(t, time.Now().Add(time.Minute), arg.VerificationExpiresAt, time.Second)
+
+							return db.ChangeEmailTxResult{}, nil
+						}),
+				)
+
+				// email of user isn't changed before verification
+				store.EXPECT().
+					SetVerifiedUserEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "InvalidEmail",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"email": "not an email", "password": user.Password},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					ChangeEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NoPassword",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"email": email},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					ChangeEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "WrongPassword",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"email": email, "password": util.RandomPassword()},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getUserCall(store, user),
+				)
+
+				store.EXPECT().
+					ChangeEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getUserCall(store, user),
+
+					store.EXPECT().
+						ChangeEmailTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.ChangeEmailTxResult{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "Unauthorized",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangeEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestResendEmailVerificationAPI(t *testing.T) {
+	user := util.RandomUser()
+	email := sql.NullString{String: util.RandomEmail(), Valid: true}
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        fmt.Sprintf("/users/%d/email/verification", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	getUserCallWith := func(store *mockdb.MockStore, email sql.NullString, verified bool) {
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(db.User{ID: user.ID, Username: user.Username, Email: email, EmailVerified: verified}, nil)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+				getUserCallWith(store, email, false)
+
+				store.EXPECT().
+					CreateEmailVerificationToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateEmailVerificationTokenParams) (db.EmailVerificationToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, email.String, arg.Email)
+						require.NotEmpty(t, arg.TokenHash)
+
+						return db.EmailVerificationToken{}, nil
+					})
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "NoEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+				getUserCallWith(store, sql.NullString{}, false)
+
+				store.EXPECT().
+					CreateEmailVerificationToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "AlreadyVerified",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+				getUserCallWith(store, email, true)
+
+				store.EXPECT().
+					CreateEmailVerificationToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+				getUserCallWith(store, email, false)
+
+				store.EXPECT().
+					CreateEmailVerificationToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EmailVerificationToken{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	verificationToken, err := util.GenerateMailToken()
+	require.NoError(t, err)
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		body       requestBody
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        "/users/email/verify",
+		body:       requestBody{"token": verificationToken},
+		setupAuth:  func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Eq(util.HashMailToken(verificationToken))).
+					Times(1).
+					Return(db.VerifyEmailTxResult{}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "NoToken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InvalidToken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:          "EmailTaken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyEmailTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestCreateUserVerificationMail(t *testing.T) {
+	user := util.RandomUser()
+	email := util.RandomEmail()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	var tokenHash []byte
+
+	store.EXPECT().
+		CreateUserTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+			tokenHash = arg.VerificationTokenHash
+			return db.CreateUserTxResult{User: db.User{ID: user.ID, Username: arg.Username, Email: arg.Email}}, nil
+		})
+
+	server := newTestServer(t, store)
+
+	var mailbox bytes.Buffer
+	server.mailer = mail.NewFileMailer(&mailbox, "noreply@example.com")
+
+	data, err := json.Marshal(requestBody{"username": user.Username, "password": user.Password, "email": email})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.Contains(t, mailbox.String(), "To: "+email)
+
+	// mailed token is the one stored
+	require.Equal(t, util.HashMailToken(mailedToken(t, &mailbox)), tokenHash)
+}
@@ -2,9 +2,11 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
 	"github.com/gin-gonic/gin"
 )
 
@@ -38,13 +40,65 @@ type getUserListsResponse struct {
 }
 
 func (s *Server) getUserLists(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
 	lists, err := s.store.GetLists(ctx, ctx.MustGet(userIdKey).(int32))
 	if err != nil && err != sql.ErrNoRows {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, getUserListsResponse{Lists: lists})
+	// token restricted to some lists doesn't see the others
+	allowedLists := make([]db.GetListsRow, 0, len(lists))
+	for _, list := range lists {
+		if authPayload.AllowsList(list.ID) {
+			allowedLists = append(allowedLists, list)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getUserListsResponse{Lists: allowedLists})
+}
+
+type updateUserListData struct {
+	Header      *string `json:"header" binding:"omitempty,min=1"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	Color       *string `json:"color" binding:"omitempty,hexcolor"`
+	Icon        *string `json:"icon" binding:"omitempty,max=32"`
+	Archived    *bool   `json:"archived"`
+}
+
+// updateUserList changes only the given attributes of list
+func (s *Server) updateUserList(ctx *gin.Context) {
+	var data updateUserListData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.UpdateListParams{
+		ID:          ctx.MustGet(listIdKey).(int32),
+		Header:      toNullString(data.Header),
+		Description: toNullString(data.Description),
+		Color:       toNullString(data.Color),
+		Icon:        toNullString(data.Icon),
+	}
+
+	if data.Archived != nil {
+		params.Archived = sql.NullBool{Bool: *data.Archived, Valid: true}
+	}
+
+	list, err := s.store.UpdateList(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "list doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
 }
 
 func (s *Server) deleteUserList(ctx *gin.Context) {
@@ -58,3 +112,42 @@ func (s *Server) deleteUserList(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// moveData is the body of reorder requests, item is placed after the sibling with id After
+// or becomes the first one without it
+type moveData struct {
+	After int32 `json:"after" binding:"omitempty,min=1"`
+}
+
+func (s *Server) moveUserList(ctx *gin.Context) {
+	listId := ctx.MustGet(listIdKey).(int32)
+
+	var data moveData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.After == listId {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("list cannot be placed after itself"), ""))
+		return
+	}
+
+	result, err := s.store.MoveListTx(ctx, db.MoveListTxParams{ID: listId, After: data.After})
+	if err != nil {
+		if err == db.ErrNotSibling {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+			return
+		}
+
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "list doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.List)
+}
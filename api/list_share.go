@@ -0,0 +1,170 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type createListShareData struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type listShareResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type createListShareResponse struct {
+	listShareResponse
+	Token string `json:"token"`
+}
+
+type getListSharesResponse struct {
+	Shares []listShareResponse `json:"shares"`
+}
+
+func newListShareResponse(share db.GetListSharesRow) listShareResponse {
+	response := listShareResponse{
+		ID:        share.ID,
+		CreatedAt: share.CreatedAt,
+	}
+
+	if share.ExpiresAt.Valid {
+		response.ExpiresAt = &share.ExpiresAt.Time
+	}
+
+	return response
+}
+
+// createListShare creates read-only share link of list, the token is returned only once
+func (s *Server) createListShare(ctx *gin.Context) {
+	// body is optional since share link may have no expiry
+	var data createListShareData
+	if err := ctx.ShouldBindJSON(&data); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return
+	}
+
+	shareToken, err := token.GenerateShareToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
+		return
+	}
+
+	params := db.CreateListShareParams{
+		ID:        id,
+		ListID:    ctx.MustGet(listIdKey).(int32),
+		TokenHash: token.HashShareToken(shareToken),
+	}
+
+	if data.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *data.ExpiresAt, Valid: true}
+	}
+
+	share, err := s.store.CreateListShare(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createListShareResponse{
+		listShareResponse: newListShareResponse(db.GetListSharesRow{
+			ID:        share.ID,
+			ListID:    share.ListID,
+			ExpiresAt: share.ExpiresAt,
+			CreatedAt: share.CreatedAt,
+		}),
+		Token: shareToken,
+	})
+}
+
+func (s *Server) getListShares(ctx *gin.Context) {
+	shares, err := s.store.GetListShares(ctx, ctx.MustGet(listIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := getListSharesResponse{
+		Shares: make([]listShareResponse, len(shares)),
+	}
+
+	for i, share := range shares {
+		response.Shares[i] = newListShareResponse(share)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// deleteListShare revokes share link, its token stops working right away
+func (s *Server) deleteListShare(ctx *gin.Context) {
+	params := db.DeleteListShareParams{
+		ID:     ctx.MustGet(listShareIdKey).(uuid.UUID),
+		ListID: ctx.MustGet(listIdKey).(int32),
+	}
+
+	deleted, err := s.store.DeleteListShare(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if deleted == 0 {
+		err := errors.New("list doesn't have this share link")
+		ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type getSharedListResponse struct {
+	db.GetSharedListRow
+	Tasks []db.Task `json:"tasks"`
+}
+
+// getSharedList returns list with its tasks to anyone who has share token, no authorization is required
+func (s *Server) getSharedList(ctx *gin.Context) {
+	list, err := s.store.GetSharedList(ctx, token.HashShareToken(ctx.Param("token")))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "share link doesn't exist or expired"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	tasks, err := s.store.GetTasks(ctx, list.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getSharedListResponse{
+		GetSharedListRow: list,
+		Tasks:            tasks,
+	})
+}
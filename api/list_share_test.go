@@ -0,0 +1,386 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateListShareAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        fmt.Sprintf("/users/%d/lists/%d/shares", user.ID, listId),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   nil,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						CreateListShare(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.CreateListShareParams) (db.ListShare, error) {
+							require.Equal(t, listId, arg.ListID)
+							require.NotEmpty(t, arg.TokenHash)
+							require.False(t, arg.ExpiresAt.Valid)
+
+							return db.ListShare{ID: arg.ID, ListID: arg.ListID, TokenHash: arg.TokenHash}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response := unmarshal[createListShareResponse](t, recorder.Body)
+				require.NotEmpty(t, response.Token)
+				require.Nil(t, response.ExpiresAt)
+			},
+		},
+		{
+			name:          "WithExpiry",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"expires_at": expiresAt},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						CreateListShare(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.CreateListShareParams) (db.ListShare, error) {
+							require.True(t, arg.ExpiresAt.Valid)
+							require.True(t, expiresAt.Equal(arg.ExpiresAt.Time))
+
+							return db.ListShare{ID: arg.ID, ListID: arg.ListID, ExpiresAt: arg.ExpiresAt}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response := unmarshal[createListShareResponse](t, recorder.Body)
+				require.NotNil(t, response.ExpiresAt)
+				require.True(t, expiresAt.Equal(*response.ExpiresAt))
+			},
+		},
+		{
+			name:          "ExpiresInPast",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"expires_at": time.Now().Add(-time.Hour)},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						CreateListShare(gomock.Any(), gomock.Any()).
+						Times(0),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NotListAuthor",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   nil,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId+1),
+
+					store.EXPECT().
+						CreateListShare(gomock.Any(), gomock.Any()).
+						Times(0),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   nil,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						CreateListShare(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.ListShare{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetListSharesAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+
+	url := fmt.Sprintf("/users/%d/lists/%d/shares", user.ID, listId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	shares := []db.GetListSharesRow{
+		{ID: uuid.New(), ListID: listId, CreatedAt: time.Now().UTC().Truncate(time.Second)},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetListShares(gomock.Any(), gomock.Eq(listId)).
+						Times(1).
+						Return(shares, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[getListSharesResponse](t, recorder.Body)
+				require.Len(t, response.Shares, 1)
+				require.Equal(t, shares[0].ID, response.Shares[0].ID)
+			},
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetListShares(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(nil, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeleteListShareAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	shareId := uuid.New()
+
+	url := fmt.Sprintf("/users/%d/lists/%d/shares/%s", user.ID, listId, shareId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	params := db.DeleteListShareParams{ID: shareId, ListID: listId}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						DeleteListShare(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(int64(1), nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvalidShareId",
+			requestMethod: http.MethodDelete,
+			requestUrl:    fmt.Sprintf("/users/%d/lists/%d/shares/abc", user.ID, listId),
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						DeleteListShare(gomock.Any(), gomock.Any()).
+						Times(0),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "ShareDoesNotExist",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						DeleteListShare(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(int64(0), nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetSharedListAPI(t *testing.T) {
+	shareToken, err := token.GenerateShareToken()
+	require.NoError(t, err)
+
+	list := db.GetSharedListRow{ID: util.RandomID(), Header: util.RandomString(8)}
+	tasks := []db.Task{{ID: util.RandomID(), ListID: list.ID, Task: util.RandomString(10)}}
+
+	url := "/shared/" + shareToken
+	noAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     noAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSharedList(gomock.Any(), gomock.Eq(token.HashShareToken(shareToken))).
+						Times(1).
+						Return(list, nil),
+
+					store.EXPECT().
+						GetTasks(gomock.Any(), gomock.Eq(list.ID)).
+						Times(1).
+						Return(tasks, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[getSharedListResponse](t, recorder.Body)
+				require.Equal(t, list, response.GetSharedListRow)
+				require.Equal(t, tasks, response.Tasks)
+			},
+		},
+		{
+			name:          "UnknownOrExpiredToken",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     noAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSharedList(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetSharedListRow{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					GetTasks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     noAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSharedList(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(list, nil),
+
+					store.EXPECT().
+						GetTasks(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(nil, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -31,8 +31,8 @@ func TestAddListToUserAPI(t *testing.T) {
 		body: requestBody{
 			"header": newListHeader,
 		},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -53,7 +53,7 @@ func TestAddListToUserAPI(t *testing.T) {
 					AddList(gomock.Any(), gomock.Eq(addListParams)).
 					Times(1).
 					Return(db.List{}, nil).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusCreated),
 		},
@@ -67,7 +67,7 @@ func TestAddListToUserAPI(t *testing.T) {
 				store.EXPECT().
 					AddList(gomock.Any(), gomock.Any()).
 					Times(0).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusBadRequest),
 		},
@@ -87,7 +87,7 @@ func TestAddListToUserAPI(t *testing.T) {
 					AddList(gomock.Any(), gomock.Eq(addListParams)).
 					Times(1).
 					Return(db.List{}, sql.ErrConnDone).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
 		},
@@ -121,8 +121,8 @@ func TestGetUserListsAPI(t *testing.T) {
 		methodGet: http.MethodGet,
 		url:       fmt.Sprintf("/users/%d/lists", user.ID),
 		body:      requestBody{},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -138,7 +138,7 @@ func TestGetUserListsAPI(t *testing.T) {
 					GetLists(gomock.Any(), gomock.Eq(user.ID)).
 					Times(1).
 					Return(userLists, nil).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				lists := unmarshal[getUserListsResponse](t, recorder.Body)
@@ -153,6 +153,29 @@ func TestGetUserListsAPI(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:          "RestrictedToken",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				access := token.Access{Scopes: []string{token.ScopeListsRead}, ListIDs: []int32{userLists[0].ID}}
+				addScopedAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, access, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLists(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(userLists, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				lists := unmarshal[getUserListsResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, userLists[:1], lists.Lists)
+			},
+		},
 		{
 			name:          "NoLists",
 			requestMethod: defaultSettings.methodGet,
@@ -164,7 +187,7 @@ func TestGetUserListsAPI(t *testing.T) {
 					GetLists(gomock.Any(), gomock.Eq(user.ID)).
 					Times(1).
 					Return([]db.GetListsRow{}, sql.ErrNoRows).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				lists := unmarshal[getUserListsResponse](t, recorder.Body)
@@ -184,7 +207,252 @@ func TestGetUserListsAPI(t *testing.T) {
 					GetLists(gomock.Any(), gomock.Eq(user.ID)).
 					Times(1).
 					Return([]db.GetListsRow{}, sql.ErrConnDone).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestUpdateUserListAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	newHeader := util.RandomString(8)
+
+	updatedList := db.List{
+		ID:       listId,
+		Author:   user.ID,
+		Header:   newHeader,
+		Color:    "#ff8800",
+		Archived: true,
+	}
+
+	defaultSettings := struct {
+		methodPatch string
+		url         string
+		body        requestBody
+		setupAuth   setupAuthFunc
+	}{
+		methodPatch: http.MethodPatch,
+		url:         fmt.Sprintf("/users/%d/lists/%d", user.ID, listId),
+		body: requestBody{
+			"header":   newHeader,
+			"color":    updatedList.Color,
+			"archived": true,
+		},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.UpdateListParams{
+					ID:       listId,
+					Header:   sql.NullString{String: newHeader, Valid: true},
+					Color:    sql.NullString{String: updatedList.Color, Valid: true},
+					Archived: sql.NullBool{Bool: true, Valid: true},
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						UpdateList(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(updatedList, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, updatedList, *unmarshal[db.List](t, recorder.Body))
+			},
+		},
+		{
+			name:          "InvalidColor",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"color": "orange"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						UpdateList(gomock.Any(), gomock.Any()).
+						Times(0),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "EmptyHeader",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"header": ""},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						UpdateList(gomock.Any(), gomock.Any()).
+						Times(0),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						UpdateList(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.List{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestMoveUserListAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	afterId := listId + 1
+
+	url := fmt.Sprintf("/users/%d/lists/%d/position", user.ID, listId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.MoveListTxParams{ID: listId, After: afterId}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						MoveListTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.MoveListTxResult{List: db.List{ID: listId, Author: user.ID, Position: 1536}}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				list := unmarshal[db.List](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, listId, list.ID)
+				require.Equal(t, float64(1536), list.Position)
+			},
+		},
+		{
+			name:          "OK(First)",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.MoveListTxParams{ID: listId}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						MoveListTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.MoveListTxResult{List: db.List{ID: listId, Author: user.ID}}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "AfterItself",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": listId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+				)
+
+				store.EXPECT().
+					MoveListTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NotSibling",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						MoveListTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.MoveListTxResult{}, db.ErrNotSibling),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						MoveListTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.MoveListTxResult{}, sql.ErrConnDone),
+				)
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
 		},
@@ -208,8 +476,8 @@ func TestDeleteUserListAPI(t *testing.T) {
 		methodDelete: http.MethodDelete,
 		url:          fmt.Sprintf("/users/%d/lists/%d", user.ID, listId),
 		body:         requestBody{},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -222,7 +490,7 @@ func TestDeleteUserListAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -241,7 +509,7 @@ func TestDeleteUserListAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
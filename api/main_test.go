@@ -12,6 +12,7 @@ import (
 
 	mockdb "github.com/PYTNAG/simpletodo/db/mock"
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/mail"
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/gin-gonic/gin"
@@ -19,18 +20,27 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
-type setupAuthFunc func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker)
+type setupAuthFunc func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 type buildStubsFunc func(store *mockdb.MockStore)
 type checkResponseFunc func(t *testing.T, recorder *httptest.ResponseRecorder)
 type getMiddlewareFunc func(*Server, db.Store) gin.HandlerFunc
 
-func newTestServer(t *testing.T, store db.Store) *Server {
-	config := util.Config{
-		TokenSymmetricKey:   token.RandomSymmetricKey,
-		AccessTokenDuration: time.Minute,
+func newTestConfig() util.Config {
+	return util.Config{
+		TokenSymmetricKey:              token.RandomKey,
+		AccessTokenDuration:            time.Minute,
+		PasswordResetTokenDuration:     time.Minute,
+		EmailVerificationTokenDuration: time.Minute,
+		LoginAttemptStore:              loginAttemptStoreMemory,
 	}
+}
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	return newTestServerWithConfig(t, store, newTestConfig())
+}
 
-	server, err := NewServer(config, store)
+func newTestServerWithConfig(t *testing.T, store db.Store, config util.Config) *Server {
+	server, err := NewServer(config, store, mail.NewFileMailer(io.Discard, "noreply@example.com"))
 	require.NoError(t, err)
 
 	return server
@@ -60,6 +70,18 @@ func getUserCall(store *mockdb.MockStore, user util.FullUserInfo) *gomock.Call {
 		Return(getUserResult, nil)
 }
 
+func getSessionCall(store *mockdb.MockStore, username string) *gomock.Call {
+	return store.EXPECT().
+		GetSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Session{Username: username}, nil)
+}
+
+// authorizedCalls expects calls made by auth middlewares for the requests of user to its own resources
+func authorizedCalls(store *mockdb.MockStore, user util.FullUserInfo) *gomock.Call {
+	return getSessionCall(store, user.Username)
+}
+
 func getListsCall(store *mockdb.MockStore, userId int32, returnedListId int32) *gomock.Call {
 	return store.EXPECT().
 		GetLists(gomock.Any(), gomock.Eq(userId)).
@@ -80,6 +102,18 @@ func getTasksCall(store *mockdb.MockStore, listId int32, returnedTaskId int32) *
 			}, nil)
 }
 
+func getWorkspaceMemberCall(store *mockdb.MockStore, workspaceId int32, userId int32, role string) *gomock.Call {
+	params := db.GetWorkspaceMemberParams{
+		WorkspaceID: workspaceId,
+		UserID:      userId,
+	}
+
+	return store.EXPECT().
+		GetWorkspaceMember(gomock.Any(), gomock.Eq(params)).
+		Times(1).
+		Return(db.WorkspaceMember{WorkspaceID: workspaceId, UserID: userId, Role: role}, nil)
+}
+
 func unmarshal[T any](t *testing.T, body *bytes.Buffer) *T {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -7,10 +7,12 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
@@ -19,7 +21,7 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(pasetoMaker token.PasetoMaker) gin.HandlerFunc {
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHaderKey)
 		if len(authorizationHeader) == 0 {
@@ -43,9 +45,16 @@ func authMiddleware(pasetoMaker token.PasetoMaker) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		payload, err := pasetoMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+
+		var payload *token.Payload
+		var ok bool
+		if token.IsPersonalAccessToken(accessToken) {
+			payload, ok = verifyPersonalAccessToken(ctx, store, accessToken)
+		} else {
+			payload, ok = verifySessionToken(ctx, tokenMaker, store, accessToken)
+		}
+
+		if !ok {
 			return
 		}
 
@@ -54,6 +63,166 @@ func authMiddleware(pasetoMaker token.PasetoMaker) gin.HandlerFunc {
 	}
 }
 
+// verifySessionToken checks PASETO access token against its session and aborts request if it's not valid.
+// Session in db is the only revocation source shared by all server instances: deleting the user deletes its sessions,
+// disabling it or admin logout blocks them, so their access tokens are rejected by the next request
+func verifySessionToken(ctx *gin.Context, tokenMaker token.Maker, store db.Store, accessToken string) (*token.Payload, bool) {
+	payload, err := tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, false
+	}
+
+	if payload.HasScope(token.ScopeLoginChallenge) {
+		err := errors.New("login challenge token can't be used as access token")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, false
+	}
+
+	session, err := store.GetSession(ctx, payload.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, "session doesn't exist"))
+			return nil, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return nil, false
+	}
+
+	if session.IsBlocked {
+		err := errors.New("blocked session")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, false
+	}
+
+	if session.Username != payload.Username {
+		err := errors.New("incorrect session user")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, false
+	}
+
+	return payload, true
+}
+
+// verifyPersonalAccessToken looks up personal access token by its hash, records its usage
+// and aborts request if it's not valid
+func verifyPersonalAccessToken(ctx *gin.Context, store db.Store, accessToken string) (*token.Payload, bool) {
+	pat, err := store.GetPersonalAccessTokenByHash(ctx, token.HashPersonalAccessToken(accessToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, "token doesn't exist"))
+			return nil, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return nil, false
+	}
+
+	if pat.ExpiresAt.Valid && time.Now().After(pat.ExpiresAt.Time) {
+		err := errors.New("expired token")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, false
+	}
+
+	// sessions of disabled user are blocked, but its personal access tokens are kept
+	if pat.DisabledAt.Valid {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errAccountDisabled, ""))
+		return nil, false
+	}
+
+	params := db.UpdatePersonalAccessTokenUsageParams{
+		ID:         pat.ID,
+		LastUsedIp: ctx.ClientIP(),
+	}
+
+	if err := store.UpdatePersonalAccessTokenUsage(ctx, params); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return nil, false
+	}
+
+	payload := &token.Payload{
+		ID:       pat.ID,
+		Username: pat.Username,
+		UserID:   pat.UserID,
+		Access: token.Access{
+			Scopes:  pat.Scopes,
+			ListIDs: pat.ListIds,
+		},
+		IssuedAt:  pat.CreatedAt,
+		ExpiredAt: pat.ExpiresAt.Time,
+	}
+
+	return payload, true
+}
+
+// scopeMiddleware rejects requests which token doesn't have scope for
+func scopeMiddleware(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !authPayload.HasScope(scope) {
+			err := fmt.Errorf("token doesn't have scope %s", scope)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// adminMiddleware lets through only users with admin role. Role is read from db on each request,
+// so taking it away works right away
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		var user db.User
+		var err error
+
+		// tokens created before user id was added to payload
+		if authPayload.UserID == 0 {
+			user, err = store.GetUser(ctx, authPayload.Username)
+		} else {
+			user, err = store.GetUserByID(ctx, authPayload.UserID)
+		}
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, "authorized user doesn't exist"))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+			return
+		}
+
+		if user.Role != db.RoleAdmin {
+			err := errors.New("admin role is required")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// listAccessMiddleware rejects requests to lists which token is restricted from
+func listAccessMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestedListId := ctx.MustGet(listIdKey).(int32)
+
+		if !authPayload.AllowsList(requestedListId) {
+			err := fmt.Errorf("token doesn't have access to list %d", requestedListId)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
 func idRequestMiddleware(key string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		value := ctx.Param(key)
@@ -75,23 +244,43 @@ func idRequestMiddleware(key string) gin.HandlerFunc {
 	}
 }
 
+func uuidRequestMiddleware(key string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param(key))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err, key+" must be UUID"))
+			return
+		}
+
+		ctx.Set(key, id)
+		ctx.Next()
+	}
+}
+
 func compareRequestedIdMiddleware(store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 		requestedUserId := ctx.MustGet(userIdKey).(int32)
 
-		user, err := store.GetUser(ctx, authPayload.Username)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, "authorized user doesn't exist"))
+		userId := authPayload.UserID
+
+		// tokens created before user id was added to payload
+		if userId == 0 {
+			user, err := store.GetUser(ctx, authPayload.Username)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, "authorized user doesn't exist"))
+					return
+				}
+
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
 				return
 			}
 
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
-			return
+			userId = user.ID
 		}
 
-		if user.ID != requestedUserId {
+		if userId != requestedUserId {
 			err := fmt.Errorf("you can't update other user")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err, ""))
 			return
@@ -129,16 +318,17 @@ func checkListAuthorMiddleware(store db.Store) gin.HandlerFunc {
 	}
 }
 
+// checkTaskParentListMiddleware rejects requests to tasks of other lists,
+// list itself is checked by previous middleware of user or workspace route
 func checkTaskParentListMiddleware(store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		requestedUserId := ctx.MustGet(userIdKey).(int32)
 		requestedListId := ctx.MustGet(listIdKey).(int32)
 		requestedTaskId := ctx.MustGet(taskIdKey).(int32)
 
 		tasks, err := store.GetTasks(ctx, requestedListId)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				additionalMsg := fmt.Sprintf("user %d doesn't have any tasks in list %d", requestedUserId, requestedListId)
+				additionalMsg := fmt.Sprintf("list %d doesn't have any tasks", requestedListId)
 				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, additionalMsg))
 				return
 			}
@@ -154,7 +344,98 @@ func checkTaskParentListMiddleware(store db.Store) gin.HandlerFunc {
 			}
 		}
 
-		err = fmt.Errorf("user %d doesn't have task %d in list %d", requestedUserId, requestedTaskId, requestedListId)
+		err = fmt.Errorf("list %d doesn't have task %d", requestedListId, requestedTaskId)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err, ""))
+	}
+}
+
+// workspaceMemberMiddleware rejects requests to workspaces which authorized user isn't member of,
+// membership of user is passed to next handlers
+func workspaceMemberMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestedWorkspaceId := ctx.MustGet(workspaceIdKey).(int32)
+
+		userId := authPayload.UserID
+
+		// tokens created before user id was added to payload
+		if userId == 0 {
+			user, err := store.GetUser(ctx, authPayload.Username)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, "authorized user doesn't exist"))
+					return
+				}
+
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+				return
+			}
+
+			userId = user.ID
+		}
+
+		params := db.GetWorkspaceMemberParams{
+			WorkspaceID: requestedWorkspaceId,
+			UserID:      userId,
+		}
+
+		member, err := store.GetWorkspaceMember(ctx, params)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err := fmt.Errorf("user %d isn't member of workspace %d", userId, requestedWorkspaceId)
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, ""))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+			return
+		}
+
+		ctx.Set(workspaceMemberKey, member)
+		ctx.Next()
+	}
+}
+
+var workspaceRoleRanks = map[string]int{
+	db.WorkspaceRoleMember: 1,
+	db.WorkspaceRoleAdmin:  2,
+	db.WorkspaceRoleOwner:  3,
+}
+
+// workspaceRoleMiddleware rejects requests of workspace members with lower role than required
+func workspaceRoleMiddleware(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+
+		if workspaceRoleRanks[member.Role] < workspaceRoleRanks[role] {
+			err := fmt.Errorf("workspace role %s is required", role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func checkWorkspaceListMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestedWorkspaceId := ctx.MustGet(workspaceIdKey).(int32)
+		requestedListId := ctx.MustGet(listIdKey).(int32)
+
+		workspaceLists, err := store.GetWorkspaceLists(ctx, requestedWorkspaceId)
+		if err != nil && err != sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err, ""))
+			return
+		}
+
+		for _, list := range workspaceLists {
+			if list.ID == requestedListId {
+				ctx.Next()
+				return
+			}
+		}
+
+		err = fmt.Errorf("workspace %d doesn't have list %d", requestedWorkspaceId, requestedListId)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err, ""))
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -19,19 +20,41 @@ import (
 func addAuthorization(
 	t *testing.T,
 	request *http.Request,
-	pasetoMaker *token.PasetoMaker,
+	tokenMaker token.Maker,
 	authorizationType string,
 	username string,
+	userID int32,
 	duration time.Duration,
 ) {
-	token, payload, err := pasetoMaker.CreateToken(username, duration)
+	addScopedAuthorization(t, request, tokenMaker, authorizationType, username, userID, token.FullAccess(), duration)
+}
+
+func addScopedAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	userID int32,
+	access token.Access,
+	duration time.Duration,
+) {
+	accessToken, payload, err := tokenMaker.CreateToken(username, userID, uuid.New(), access, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
-	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, accessToken)
 	request.Header.Set(authorizationHaderKey, authorizationHeader)
 }
 
+const testPersonalAccessToken = token.PersonalAccessTokenPrefix + "0123456789abcdef"
+
+func addPersonalAccessToken(pat string) setupAuthFunc {
+	return func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		request.Header.Set(authorizationHaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, pat))
+	}
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	defaultSettings := struct {
 		method        string
@@ -45,7 +68,7 @@ func TestAuthMiddleware(t *testing.T) {
 		buildStubs:   func(store *mockdb.MockStore) {},
 		setupContext: func(ctx *gin.Context) { ctx.Next() },
 		getMiddleware: func(server *Server, store db.Store) gin.HandlerFunc {
-			return authMiddleware(*server.pasetoMaker)
+			return authMiddleware(server.tokenMaker, store)
 		},
 	}
 
@@ -54,19 +77,236 @@ func TestAuthMiddleware(t *testing.T) {
 			name:        "OK",
 			requestPath: defaultSettings.url,
 			requestUrl:  defaultSettings.url,
-			setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-				addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, "user", time.Minute)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				getSessionCall(store, "user")
 			},
-			buildStubs:    defaultSettings.buildStubs,
 			checkResponse: requierResponseCode(http.StatusOK),
 			setupContext:  defaultSettings.setupContext,
 			getMiddleware: defaultSettings.getMiddleware,
 		},
+		{
+			name:        "SessionDoesNotExist",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "BlockedSession",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{Username: "user", IsBlocked: true}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "IncorrectSessionUser",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				getSessionCall(store, "other_user")
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "SessionInternalError",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "DeletedUser",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// sessions are deleted together with the user
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "LoginChallengeToken",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				access := token.Access{Scopes: []string{token.ScopeLoginChallenge}}
+				addScopedAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, access, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "PersonalAccessToken",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				pat := db.GetPersonalAccessTokenByHashRow{
+					ID:       uuid.New(),
+					Username: "user",
+				}
+
+				gomock.InOrder(
+					store.EXPECT().
+						GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(token.HashPersonalAccessToken(testPersonalAccessToken))).
+						Times(1).
+						Return(pat, nil),
+
+					store.EXPECT().
+						UpdatePersonalAccessTokenUsage(gomock.Any(), gomock.Eq(db.UpdatePersonalAccessTokenUsageParams{ID: pat.ID, LastUsedIp: ""})).
+						Times(1).
+						Return(nil),
+				)
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "PersonalAccessTokenOfDisabledUser",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				pat := db.GetPersonalAccessTokenByHashRow{
+					ID:         uuid.New(),
+					Username:   "user",
+					DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}
+
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pat, nil)
+
+				store.EXPECT().
+					UpdatePersonalAccessTokenUsage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "PersonalAccessTokenDoesNotExist",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetPersonalAccessTokenByHashRow{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					UpdatePersonalAccessTokenUsage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "ExpiredPersonalAccessToken",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				pat := db.GetPersonalAccessTokenByHashRow{
+					ID:        uuid.New(),
+					Username:  "user",
+					ExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+				}
+
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pat, nil)
+
+				store.EXPECT().
+					UpdatePersonalAccessTokenUsage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "PersonalAccessTokenInternalError",
+			requestPath: defaultSettings.url,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetPersonalAccessTokenByHashRow{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+			setupContext:  defaultSettings.setupContext,
+			getMiddleware: defaultSettings.getMiddleware,
+		},
 		{
 			name:          "NoAuthorization",
 			requestPath:   defaultSettings.url,
 			requestUrl:    defaultSettings.url,
-			setupAuth:     func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 			buildStubs:    defaultSettings.buildStubs,
 			checkResponse: requierResponseCode(http.StatusUnauthorized),
 			setupContext:  defaultSettings.setupContext,
@@ -76,8 +316,8 @@ func TestAuthMiddleware(t *testing.T) {
 			name:        "InvalidAuthorizationFormat",
 			requestPath: defaultSettings.url,
 			requestUrl:  defaultSettings.url,
-			setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-				addAuthorization(t, request, pasetoMaker, "", "user", time.Minute)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "", "user", 1, time.Minute)
 			},
 			buildStubs:    defaultSettings.buildStubs,
 			checkResponse: requierResponseCode(http.StatusUnauthorized),
@@ -88,8 +328,8 @@ func TestAuthMiddleware(t *testing.T) {
 			name:        "UnsupportedAuthorizationType",
 			requestPath: defaultSettings.url,
 			requestUrl:  defaultSettings.url,
-			setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-				addAuthorization(t, request, pasetoMaker, "unsupported", "user", time.Minute)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "unsupported", "user", 1, time.Minute)
 			},
 			buildStubs:    defaultSettings.buildStubs,
 			checkResponse: requierResponseCode(http.StatusUnauthorized),
@@ -100,8 +340,8 @@ func TestAuthMiddleware(t *testing.T) {
 			name:        "ExpiredToken",
 			requestPath: defaultSettings.url,
 			requestUrl:  defaultSettings.url,
-			setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-				addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, "user", -time.Minute)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", 1, -time.Minute)
 			},
 			buildStubs:    defaultSettings.buildStubs,
 			checkResponse: requierResponseCode(http.StatusUnauthorized),
@@ -129,7 +369,7 @@ func TestIdRequestMiddleware(t *testing.T) {
 		method:       http.MethodGet,
 		path:         fmt.Sprintf("/:%s", idKey),
 		buildStubs:   func(store *mockdb.MockStore) {},
-		setupAuth:    func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+		setupAuth:    func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 		setupContext: func(ctx *gin.Context) { ctx.Next() },
 		getMiddleware: func(server *Server, store db.Store) gin.HandlerFunc {
 			return idRequestMiddleware(idKey)
@@ -187,24 +427,29 @@ func TestIdRequestMiddleware(t *testing.T) {
 func TestCompareRequestedIdMiddleware(t *testing.T) {
 	user := util.RandomUser()
 
+	// setupPayload puts payload carrying userId into context like authMiddleware does
+	setupPayload := func(userId int32) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			payload, _ := token.NewPayload(user.Username, userId, uuid.New(), token.FullAccess(), time.Minute)
+
+			ctx.Set(authorizationPayloadKey, payload)
+			ctx.Set(userIdKey, user.ID)
+
+			ctx.Next()
+		}
+	}
+
 	defaultSettings := struct {
 		method        string
 		path          string
+		url           string
 		setupAuth     setupAuthFunc
-		setupContext  gin.HandlerFunc
 		getMiddleware getMiddlewareFunc
 	}{
 		method:    http.MethodGet,
 		path:      fmt.Sprintf("/:%s", userIdKey),
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
-		setupContext: func(ctx *gin.Context) {
-			token, _ := token.NewPayload(user.Username, time.Minute)
-
-			ctx.Set(authorizationPayloadKey, token)
-			ctx.Set(userIdKey, user.ID)
-
-			ctx.Next()
-		},
+		url:       fmt.Sprintf("/%d", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 		getMiddleware: func(server *Server, store db.Store) gin.HandlerFunc {
 			return compareRequestedIdMiddleware(store)
 		},
@@ -214,7 +459,35 @@ func TestCompareRequestedIdMiddleware(t *testing.T) {
 		{
 			name:        "OK",
 			requestPath: defaultSettings.path,
-			requestUrl:  fmt.Sprintf("/%d", user.ID),
+			requestUrl:  defaultSettings.url,
+			setupAuth:   defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+			setupContext:  setupPayload(user.ID),
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "AccessError",
+			requestPath: defaultSettings.path,
+			requestUrl:  defaultSettings.url,
+			setupAuth:   defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+			setupContext:  setupPayload(user.ID + 1),
+			getMiddleware: defaultSettings.getMiddleware,
+		},
+		{
+			name:        "LegacyTokenOK",
+			requestPath: defaultSettings.path,
+			requestUrl:  defaultSettings.url,
 			setupAuth:   defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -223,13 +496,13 @@ func TestCompareRequestedIdMiddleware(t *testing.T) {
 					Return(db.User{ID: user.ID}, nil)
 			},
 			checkResponse: requierResponseCode(http.StatusOK),
-			setupContext:  defaultSettings.setupContext,
+			setupContext:  setupPayload(0),
 			getMiddleware: defaultSettings.getMiddleware,
 		},
 		{
-			name:        "AuthorizedUserDoesNotExist",
+			name:        "LegacyTokenUserDoesNotExist",
 			requestPath: defaultSettings.path,
-			requestUrl:  fmt.Sprintf("/%d", user.ID),
+			requestUrl:  defaultSettings.url,
 			setupAuth:   defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -238,13 +511,13 @@ func TestCompareRequestedIdMiddleware(t *testing.T) {
 					Return(db.User{}, sql.ErrNoRows)
 			},
 			checkResponse: requierResponseCode(http.StatusForbidden),
-			setupContext:  defaultSettings.setupContext,
+			setupContext:  setupPayload(0),
 			getMiddleware: defaultSettings.getMiddleware,
 		},
 		{
-			name:        "InternalError",
+			name:        "LegacyTokenInternalError",
 			requestPath: defaultSettings.path,
-			requestUrl:  fmt.Sprintf("/%d", user.ID),
+			requestUrl:  defaultSettings.url,
 			setupAuth:   defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -253,22 +526,22 @@ func TestCompareRequestedIdMiddleware(t *testing.T) {
 					Return(db.User{}, sql.ErrConnDone)
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
-			setupContext:  defaultSettings.setupContext,
+			setupContext:  setupPayload(0),
 			getMiddleware: defaultSettings.getMiddleware,
 		},
 		{
-			name:        "AccessError",
+			name:        "LegacyTokenAccessError",
 			requestPath: defaultSettings.path,
-			requestUrl:  fmt.Sprintf("/%d", user.ID),
+			requestUrl:  defaultSettings.url,
 			setupAuth:   defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.User{ID: util.RandomID()}, nil)
+					Return(db.User{ID: user.ID + 1}, nil)
 			},
 			checkResponse: requierResponseCode(http.StatusUnauthorized),
-			setupContext:  defaultSettings.setupContext,
+			setupContext:  setupPayload(0),
 			getMiddleware: defaultSettings.getMiddleware,
 		},
 	}
@@ -291,7 +564,7 @@ func TestCheckListAuthorMiddleware(t *testing.T) {
 	}{
 		method:    http.MethodGet,
 		path:      fmt.Sprintf("/:%s/:%s", userIdKey, listIdKey),
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 		setupContext: func(ctx *gin.Context) {
 			ctx.Set(userIdKey, user.ID)
 			ctx.Set(listIdKey, listId)
@@ -393,7 +666,7 @@ func TestCheckTaskParentListMiddleware(t *testing.T) {
 	}{
 		method:    http.MethodGet,
 		path:      fmt.Sprintf("/:%s/:%s/:%s", userIdKey, listIdKey, taskIdKey),
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 		setupContext: func(ctx *gin.Context) {
 			ctx.Set(userIdKey, user.ID)
 			ctx.Set(listIdKey, listId)
@@ -480,3 +753,93 @@ func TestCheckTaskParentListMiddleware(t *testing.T) {
 		t.Run(tc.name, testingMiddlewareFunc(tc))
 	}
 }
+
+// setPayloadContext puts payload with access into context like authMiddleware does
+func setPayloadContext(access token.Access, listId int32) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, _ := token.NewPayload("user", 1, uuid.New(), access, time.Minute)
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(listIdKey, listId)
+
+		ctx.Next()
+	}
+}
+
+func TestScopeMiddleware(t *testing.T) {
+	getMiddleware := func(server *Server, store db.Store) gin.HandlerFunc {
+		return scopeMiddleware(token.ScopeTasksWrite)
+	}
+
+	testCases := []*middlewareTestCase{
+		{
+			name:          "OK",
+			requestPath:   "/scope",
+			requestUrl:    "/scope",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs:    func(store *mockdb.MockStore) {},
+			checkResponse: requierResponseCode(http.StatusOK),
+			setupContext:  setPayloadContext(token.FullAccess(), 0),
+			getMiddleware: getMiddleware,
+		},
+		{
+			name:          "MissingScope",
+			requestPath:   "/scope",
+			requestUrl:    "/scope",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs:    func(store *mockdb.MockStore) {},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+			setupContext:  setPayloadContext(token.Access{Scopes: []string{token.ScopeListsRead, token.ScopeTasksRead}}, 0),
+			getMiddleware: getMiddleware,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, testingMiddlewareFunc(tc))
+	}
+}
+
+func TestListAccessMiddleware(t *testing.T) {
+	listId := util.RandomID()
+
+	getMiddleware := func(server *Server, store db.Store) gin.HandlerFunc {
+		return listAccessMiddleware()
+	}
+
+	testCases := []*middlewareTestCase{
+		{
+			name:          "AllLists",
+			requestPath:   "/list",
+			requestUrl:    "/list",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs:    func(store *mockdb.MockStore) {},
+			checkResponse: requierResponseCode(http.StatusOK),
+			setupContext:  setPayloadContext(token.FullAccess(), listId),
+			getMiddleware: getMiddleware,
+		},
+		{
+			name:          "AllowedList",
+			requestPath:   "/list",
+			requestUrl:    "/list",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs:    func(store *mockdb.MockStore) {},
+			checkResponse: requierResponseCode(http.StatusOK),
+			setupContext:  setPayloadContext(token.Access{Scopes: token.Scopes, ListIDs: []int32{listId}}, listId),
+			getMiddleware: getMiddleware,
+		},
+		{
+			name:          "RestrictedList",
+			requestPath:   "/list",
+			requestUrl:    "/list",
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs:    func(store *mockdb.MockStore) {},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+			setupContext:  setPayloadContext(token.Access{Scopes: token.Scopes, ListIDs: []int32{listId + 1}}, listId),
+			getMiddleware: getMiddleware,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, testingMiddlewareFunc(tc))
+	}
+}
@@ -48,7 +48,7 @@ func testingMiddlewareFunc(tc *middlewareTestCase) func(*testing.T) {
 		request, err := http.NewRequest(http.MethodGet, tc.requestUrl, nil)
 		require.NoError(t, err)
 
-		tc.setupAuth(t, request, server.pasetoMaker)
+		tc.setupAuth(t, request, server.tokenMaker)
 
 		server.router.ServeHTTP(recorder, request)
 
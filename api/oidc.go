@@ -0,0 +1,220 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/oidc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/users/oidc/callback"
+)
+
+var (
+	errUnknownOidcState  = errors.New("unknown or already used authorization state")
+	errOidcStateExpired  = errors.New("authorization request has expired")
+	errOidcStateNotBound = errors.New("authorization request wasn't started by this browser")
+)
+
+// startOidcLogin redirects user to OpenID Connect provider to sign in
+func (s *Server) startOidcLogin(ctx *gin.Context) {
+	authURL, ok := s.createOidcAuthRequest(ctx, sql.NullInt32{})
+	if !ok {
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// startOidcLink returns URL of OpenID Connect provider where user has to sign in
+// to link provider identity to the account
+func (s *Server) startOidcLink(ctx *gin.Context) {
+	userId := ctx.MustGet(userIdKey).(int32)
+
+	authURL, ok := s.createOidcAuthRequest(ctx, sql.NullInt32{Int32: userId, Valid: true})
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// createOidcAuthRequest saves state, nonce and PKCE verifier of a new authorization request and returns its URL.
+// State is also set as cookie which the callback has to come with.
+// Request of user links identity to the user instead of login
+func (s *Server) createOidcAuthRequest(ctx *gin.Context, userID sql.NullInt32) (string, bool) {
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := oidc.RandomString()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+			return "", false
+		}
+
+		secrets[i] = secret
+	}
+
+	params := db.CreateOidcAuthRequestParams{
+		State:        secrets[0],
+		Nonce:        secrets[1],
+		CodeVerifier: secrets[2],
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(s.config.OIDCAuthRequestDuration),
+	}
+
+	if _, err := s.store.CreateOidcAuthRequest(ctx, params); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return "", false
+	}
+
+	// state is bound to the browser which starts the request, so callback of other user's request is rejected.
+	// Lax cookie is still sent by the top-level redirect back from provider
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, params.State, int(s.config.OIDCAuthRequestDuration.Seconds()), oidcStateCookiePath, "", true, true)
+
+	return s.oidcProvider.AuthCodeURL(params.State, params.Nonce, params.CodeVerifier), true
+}
+
+type oidcCallbackQuery struct {
+	State            string `form:"state" binding:"required"`
+	Code             string `form:"code"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// finishOidcLogin handles redirect back from OpenID Connect provider.
+// Linked user is logged in, unknown identity gets a new user without password
+func (s *Server) finishOidcLogin(ctx *gin.Context) {
+	var query oidcCallbackQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	stateCookie, err := ctx.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(stateCookie), []byte(query.State)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errOidcStateNotBound, ""))
+		return
+	}
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", true, true)
+
+	authRequest, err := s.store.TakeOidcAuthRequest(ctx, query.State)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errUnknownOidcState, ""))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if time.Now().After(authRequest.ExpiresAt) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errOidcStateExpired, ""))
+		return
+	}
+
+	if query.Error != "" {
+		err := fmt.Errorf("provider denied authorization: %s", query.Error)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, query.ErrorDescription))
+		return
+	}
+
+	if query.Code == "" {
+		err := errors.New("authorization code is missing")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	claims, err := s.oidcProvider.Exchange(ctx, query.Code, authRequest.CodeVerifier, authRequest.Nonce)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return
+	}
+
+	// unverified email can't be trusted to reset password
+	var email sql.NullString
+	if claims.Email != "" && claims.EmailVerified {
+		email = sql.NullString{String: claims.Email, Valid: true}
+	}
+
+	if authRequest.UserID.Valid {
+		s.linkOidcIdentity(ctx, authRequest.UserID.Int32, claims, email)
+		return
+	}
+
+	user, err := s.store.GetUserByIdentity(ctx, db.GetUserByIdentityParams{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err == sql.ErrNoRows {
+		// preferred username is chosen by user at the provider, subject is used if it isn't valid here
+		username := claims.PreferredUsername
+		if util.ValidateUsername(username) != nil {
+			username = claims.Subject
+		}
+
+		if err := util.ValidateUsername(username); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, "identity has no valid username, log in and link the identity to existing user"))
+			return
+		}
+
+		result, err := s.store.CreateIdentityUserTx(ctx, db.CreateIdentityUserTxParams{
+			Username: username,
+			Email:    email,
+			Issuer:   claims.Issuer,
+			Subject:  claims.Subject,
+		})
+		if err != nil {
+			if db.IsUniqueViolation(err) {
+				ctx.JSON(http.StatusConflict, errorResponse(err, "user with the same username or email exists, log in and link the identity to it"))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+			return
+		}
+
+		user = result.User
+	}
+
+	s.completeLogin(ctx, user, token.FullAccess())
+}
+
+func (s *Server) linkOidcIdentity(ctx *gin.Context, userID int32, claims *oidc.Claims, email sql.NullString) {
+	params := db.CreateUserIdentityParams{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		UserID:  userID,
+		Email:   email,
+	}
+
+	if _, err := s.store.CreateUserIdentity(ctx, params); err != nil {
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "identity is already linked"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
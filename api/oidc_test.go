@@ -0,0 +1,609 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/oidc/oidctest"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testOidcClientID    = "simpletodo"
+	testOidcRedirectURL = "http://localhost:8080/users/oidc/callback"
+)
+
+func newOidcTestServer(t *testing.T, store db.Store) (*Server, *oidctest.Issuer) {
+	issuer, err := oidctest.NewIssuer(testOidcClientID, "secret")
+	require.NoError(t, err)
+	t.Cleanup(issuer.Close)
+
+	config := newTestConfig()
+	config.OIDCIssuerURL = issuer.URL()
+	config.OIDCClientID = testOidcClientID
+	config.OIDCClientSecret = "secret"
+	config.OIDCRedirectURL = testOidcRedirectURL
+	config.OIDCAuthRequestDuration = time.Minute
+
+	return newTestServerWithConfig(t, store, config), issuer
+}
+
+// saveOidcAuthRequestCall keeps created authorization request in authRequest, so the next take call can return it
+func saveOidcAuthRequestCall(store *mockdb.MockStore, authRequest *db.OidcAuthRequest) *gomock.Call {
+	return store.EXPECT().
+		CreateOidcAuthRequest(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOidcAuthRequestParams) (db.OidcAuthRequest, error) {
+			*authRequest = db.OidcAuthRequest{
+				State:        arg.State,
+				Nonce:        arg.Nonce,
+				CodeVerifier: arg.CodeVerifier,
+				UserID:       arg.UserID,
+				ExpiresAt:    arg.ExpiresAt,
+				CreatedAt:    time.Now(),
+			}
+
+			return *authRequest, nil
+		})
+}
+
+func takeOidcAuthRequestCall(store *mockdb.MockStore, authRequest *db.OidcAuthRequest) *gomock.Call {
+	return store.EXPECT().
+		TakeOidcAuthRequest(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, state string) (db.OidcAuthRequest, error) {
+			if state != authRequest.State {
+				return db.OidcAuthRequest{}, sql.ErrNoRows
+			}
+
+			return *authRequest, nil
+		})
+}
+
+// followAuthorization signs in at the issuer like browser does and returns callback URL of the server
+func followAuthorization(t *testing.T, authURL string) string {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusFound, response.StatusCode)
+
+	location, err := response.Location()
+	require.NoError(t, err)
+
+	return location.Path + "?" + location.RawQuery
+}
+
+func serveTestRequest(t *testing.T, server *Server, method, url string, setupAuth setupAuthFunc) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+
+	if setupAuth != nil {
+		setupAuth(t, request, server.tokenMaker)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// serveOidcCallback sends callback like browser which has got cookies of the starting response
+func serveOidcCallback(t *testing.T, server *Server, callbackURL string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	request, err := http.NewRequest(http.MethodGet, callbackURL, nil)
+	require.NoError(t, err)
+
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+type oidcLoginTestCase struct {
+	name          string
+	identity      oidctest.Identity
+	buildStubs    func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest)
+	checkResponse checkResponseFunc
+}
+
+func TestOidcLoginAPI(t *testing.T) {
+	user := util.RandomUser()
+	email := util.RandomEmail()
+
+	identity := oidctest.Identity{
+		Subject:           uuid.NewString(),
+		Email:             email,
+		EmailVerified:     true,
+		PreferredUsername: user.Username,
+	}
+
+	session := db.Session{
+		ID: uuid.New(),
+	}
+
+	startSessionCalls := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(db.UserTotp{}, sql.ErrNoRows)
+
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(session, nil)
+	}
+
+	checkLoggedIn := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		gotResult := unmarshal[loginUserResponse](t, recorder.Body)
+		require.Equal(t, user.ID, gotResult.ID)
+		require.NotEmpty(t, gotResult.AccessToken)
+		require.NotEmpty(t, gotResult.RefreshToken)
+	}
+
+	identityParams := func(issuer string) db.GetUserByIdentityParams {
+		return db.GetUserByIdentityParams{Issuer: issuer, Subject: identity.Subject}
+	}
+
+	testCases := []*oidcLoginTestCase{
+		{
+			name:     "LinkedUser",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Eq(identityParams(issuer))).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateIdentityUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				startSessionCalls(store)
+			},
+			checkResponse: checkLoggedIn,
+		},
+		{
+			name:     "NewUser",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Eq(identityParams(issuer))).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				params := db.CreateIdentityUserTxParams{
+					Username: user.Username,
+					Email:    sql.NullString{String: email, Valid: true},
+					Issuer:   issuer,
+					Subject:  identity.Subject,
+				}
+
+				store.EXPECT().
+					CreateIdentityUserTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.CreateIdentityUserTxResult{User: db.User{ID: user.ID, Username: user.Username}}, nil)
+
+				startSessionCalls(store)
+			},
+			checkResponse: checkLoggedIn,
+		},
+		{
+			name: "NewUserWithoutVerifiedEmailAndUsername",
+			identity: oidctest.Identity{
+				Subject: identity.Subject,
+				Email:   email,
+			},
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Eq(identityParams(issuer))).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				params := db.CreateIdentityUserTxParams{
+					Username: identity.Subject,
+					Email:    sql.NullString{},
+					Issuer:   issuer,
+					Subject:  identity.Subject,
+				}
+
+				store.EXPECT().
+					CreateIdentityUserTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.CreateIdentityUserTxResult{User: db.User{ID: user.ID, Username: identity.Subject}}, nil)
+
+				startSessionCalls(store)
+			},
+			checkResponse: checkLoggedIn,
+		},
+		{
+			name: "NewUserWithInvalidUsername",
+			identity: oidctest.Identity{
+				Subject:           identity.Subject,
+				PreferredUsername: "John Doe",
+			},
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Eq(identityParams(issuer))).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				params := db.CreateIdentityUserTxParams{
+					Username: identity.Subject,
+					Issuer:   issuer,
+					Subject:  identity.Subject,
+				}
+
+				store.EXPECT().
+					CreateIdentityUserTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.CreateIdentityUserTxResult{User: db.User{ID: user.ID, Username: identity.Subject}}, nil)
+
+				startSessionCalls(store)
+			},
+			checkResponse: checkLoggedIn,
+		},
+		{
+			name:     "UsernameTaken",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					CreateIdentityUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateIdentityUserTxResult{}, &pq.Error{Code: "23505"})
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:     "TwoFactorEnabled",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username}, nil)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{UserID: user.ID, IsEnabled: true}, nil)
+
+				store.EXPECT().
+					CreateLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.NotEqual(t, uuid.Nil, arg.ID)
+
+						return db.LoginChallenge{ID: arg.ID, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+					})
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[twoFactorChallengeResponse](t, recorder.Body)
+				require.True(t, gotResult.TwoFactorRequired)
+				require.NotEmpty(t, gotResult.ChallengeToken)
+			},
+		},
+		{
+			name:     "UnknownState",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					TakeOidcAuthRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OidcAuthRequest{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:     "ExpiredState",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					TakeOidcAuthRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, state string) (db.OidcAuthRequest, error) {
+						expired := *authRequest
+						expired.ExpiresAt = time.Now().Add(-time.Second)
+
+						return expired, nil
+					})
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:     "WrongCodeVerifier",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					TakeOidcAuthRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, state string) (db.OidcAuthRequest, error) {
+						stolen := *authRequest
+						stolen.CodeVerifier = util.RandomString(43)
+
+						return stolen, nil
+					})
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:     "GetUserByIdentityInternalError",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string, authRequest *db.OidcAuthRequest) {
+				saveOidcAuthRequestCall(store, authRequest)
+				takeOidcAuthRequestCall(store, authRequest)
+
+				store.EXPECT().
+					GetUserByIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, issuer := newOidcTestServer(t, store)
+			issuer.SetIdentity(tc.identity)
+
+			var authRequest db.OidcAuthRequest
+			tc.buildStubs(store, issuer.URL(), &authRequest)
+
+			recorder := serveTestRequest(t, server, http.MethodGet, "/users/oidc/login", nil)
+			require.Equal(t, http.StatusFound, recorder.Code)
+
+			callbackURL := followAuthorization(t, recorder.Header().Get("Location"))
+
+			tc.checkResponse(t, serveOidcCallback(t, server, callbackURL, recorder.Result().Cookies()))
+		})
+	}
+}
+
+func TestOidcLinkAPI(t *testing.T) {
+	user := util.RandomUser()
+	identity := oidctest.Identity{Subject: uuid.NewString()}
+
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []struct {
+		name          string
+		linkErr       error
+		checkResponse checkResponseFunc
+	}{
+		{
+			name:          "OK",
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "AlreadyLinked",
+			linkErr:       &pq.Error{Code: "23505"},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, issuer := newOidcTestServer(t, store)
+			issuer.SetIdentity(identity)
+
+			var authRequest db.OidcAuthRequest
+
+			authorizedCalls(store, user)
+			saveOidcAuthRequestCall(store, &authRequest)
+			takeOidcAuthRequestCall(store, &authRequest)
+
+			params := db.CreateUserIdentityParams{
+				Issuer:  issuer.URL(),
+				Subject: identity.Subject,
+				UserID:  user.ID,
+			}
+
+			store.EXPECT().
+				CreateUserIdentity(gomock.Any(), gomock.Eq(params)).
+				Times(1).
+				Return(db.UserIdentity{}, tc.linkErr)
+
+			store.EXPECT().
+				GetUserByIdentity(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			recorder := serveTestRequest(t, server, http.MethodPost, fmt.Sprintf("/users/%d/oidc", user.ID), setupAuth)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, sql.NullInt32{Int32: user.ID, Valid: true}, authRequest.UserID)
+
+			gotResult := unmarshal[struct {
+				AuthorizationURL string `json:"authorization_url"`
+			}](t, recorder.Body)
+
+			callbackURL := followAuthorization(t, gotResult.AuthorizationURL)
+
+			tc.checkResponse(t, serveOidcCallback(t, server, callbackURL, recorder.Result().Cookies()))
+		})
+	}
+}
+
+func TestOidcCallbackProviderError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server, _ := newOidcTestServer(t, store)
+
+	authRequest := db.OidcAuthRequest{
+		State:     "state",
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	takeOidcAuthRequestCall(store, &authRequest)
+
+	store.EXPECT().
+		GetUserByIdentity(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	query := url.Values{"state": {"state"}, "error": {"access_denied"}}
+	cookies := []*http.Cookie{{Name: oidcStateCookie, Value: "state"}}
+	recorder := serveOidcCallback(t, server, "/users/oidc/callback?"+query.Encode(), cookies)
+
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestOidcCallbackStateCookie(t *testing.T) {
+	identity := oidctest.Identity{Subject: uuid.NewString()}
+
+	testCases := []struct {
+		name    string
+		cookies func(startCookies []*http.Cookie) []*http.Cookie
+	}{
+		{
+			name:    "NoCookie",
+			cookies: func(startCookies []*http.Cookie) []*http.Cookie { return nil },
+		},
+		{
+			name: "OtherBrowser",
+			cookies: func(startCookies []*http.Cookie) []*http.Cookie {
+				return []*http.Cookie{{Name: oidcStateCookie, Value: util.RandomString(43)}}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, issuer := newOidcTestServer(t, store)
+			issuer.SetIdentity(identity)
+
+			var authRequest db.OidcAuthRequest
+			saveOidcAuthRequestCall(store, &authRequest)
+
+			// state of other browser isn't consumed
+			store.EXPECT().
+				TakeOidcAuthRequest(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			recorder := serveTestRequest(t, server, http.MethodGet, "/users/oidc/login", nil)
+			require.Equal(t, http.StatusFound, recorder.Code)
+
+			startCookies := recorder.Result().Cookies()
+			require.Len(t, startCookies, 1)
+			require.Equal(t, oidcStateCookie, startCookies[0].Name)
+			require.Equal(t, authRequest.State, startCookies[0].Value)
+			require.True(t, startCookies[0].HttpOnly)
+			require.True(t, startCookies[0].Secure)
+			require.Equal(t, http.SameSiteLaxMode, startCookies[0].SameSite)
+
+			callbackURL := followAuthorization(t, recorder.Header().Get("Location"))
+
+			recorder = serveOidcCallback(t, server, callbackURL, tc.cookies(startCookies))
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestOidcDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	recorder := serveTestRequest(t, server, http.MethodGet, "/users/oidc/login", nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
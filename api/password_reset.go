@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/gin-gonic/gin"
+)
+
+const passwordResetSubject = "Password reset"
+
+type requestPasswordResetData struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset mails reset token to the user with requested verified email.
+// User is looked up and mailed in background, so neither response nor its time tells whether such user exists
+func (s *Server) requestPasswordReset(ctx *gin.Context) {
+	var data requestPasswordResetData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	s.runInBackground(func() {
+		if err := s.sendPasswordReset(context.Background(), data.Email); err != nil {
+			log.Printf("cannot send password reset: %v", err)
+		}
+	})
+
+	ctx.JSON(http.StatusAccepted, nil)
+}
+
+// sendPasswordReset creates reset token of the user with verified email and mails it, unknown email is ignored
+func (s *Server) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.store.GetUserByEmail(ctx, sql.NullString{String: email, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+
+		return err
+	}
+
+	// token is mailed only to the email user has proven to own
+	if !user.EmailVerified {
+		return nil
+	}
+
+	resetToken, err := util.GenerateMailToken()
+	if err != nil {
+		return fmt.Errorf("cannot create token: %w", err)
+	}
+
+	params := db.CreatePasswordResetTokenParams{
+		TokenHash: util.HashMailToken(resetToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.PasswordResetTokenDuration),
+	}
+
+	if _, err := s.store.CreatePasswordResetToken(ctx, params); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Hello, %s!\n\nUse this token to reset your password: %s\nIt expires in %s. If you didn't request password reset, ignore this email.",
+		user.Username, resetToken, s.config.PasswordResetTokenDuration,
+	)
+
+	if err := s.mailer.SendEmail(email, passwordResetSubject, body); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+
+	return nil
+}
+
+type confirmPasswordResetData struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// confirmPasswordReset sets new password and blocks all sessions of the user
+func (s *Server) confirmPasswordReset(ctx *gin.Context) {
+	var data confirmPasswordResetData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if err := s.passwordPolicy.Validate(data.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	newHash, err := util.HashPassword(data.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	params := db.ResetPasswordTxParams{
+		TokenHash: util.HashMailToken(data.Token),
+		NewHash:   newHash,
+	}
+
+	if _, err := s.store.ResetPasswordTx(ctx, params); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err, "invalid or expired reset token"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/mail"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+	user := util.RandomUser()
+	email := util.RandomEmail()
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		body       requestBody
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        "/users/password_reset",
+		body:       requestBody{"email": email},
+		setupAuth:  func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+	}
+
+	getUserByEmailCall := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Eq(sql.NullString{String: email, Valid: true})).
+			Times(1).
+			Return(db.User{ID: user.ID, Username: user.Username, Email: sql.NullString{String: email, Valid: true}, EmailVerified: true}, nil)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.NotEmpty(t, arg.TokenHash)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+
+						return db.PasswordResetToken{TokenHash: arg.TokenHash, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+					}).
+					After(getUserByEmailCall(store))
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "UnknownEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "UnverifiedEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username, Email: sql.NullString{String: email, Valid: true}}, nil)
+
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "InvalidEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body.replace("email", user.Username),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			// failures are logged, response is the same as for unknown email
+			name:          "GetUserInternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+		{
+			name:          "CreateTokenInternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordResetToken{}, sql.ErrConnDone).
+					After(getUserByEmailCall(store))
+			},
+			checkResponse: requierResponseCode(http.StatusAccepted),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestPasswordResetMail(t *testing.T) {
+	user := util.RandomUser()
+	email := util.RandomEmail()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	var tokenHash []byte
+
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{ID: user.ID, Username: user.Username, EmailVerified: true}, nil)
+
+	store.EXPECT().
+		CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+			tokenHash = arg.TokenHash
+			return db.PasswordResetToken{TokenHash: arg.TokenHash, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	server := newTestServer(t, store)
+
+	var mailbox bytes.Buffer
+	server.mailer = mail.NewFileMailer(&mailbox, "noreply@example.com")
+
+	data, err := json.Marshal(requestBody{"email": email})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/password_reset", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	server.background.Wait()
+
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	require.Contains(t, mailbox.String(), "To: "+email)
+
+	// mailed token is the one stored
+	resetToken := regexp.MustCompile(`[0-9a-f]{64}`).FindString(mailbox.String())
+	require.NotEmpty(t, resetToken)
+	require.Equal(t, util.HashMailToken(resetToken), tokenHash)
+}
+
+func TestConfirmPasswordResetAPI(t *testing.T) {
+	user := util.RandomUser()
+
+	resetToken, err := util.GenerateMailToken()
+	require.NoError(t, err)
+
+	newPassword := util.RandomPassword()
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		body       requestBody
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        "/users/password_reset/confirm",
+		body:       requestBody{"token": resetToken, "new_password": newPassword},
+		setupAuth:  func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, util.HashMailToken(resetToken), arg.TokenHash)
+						require.NoError(t, util.CheckPassword(newPassword, arg.NewHash))
+
+						return db.ResetPasswordTxResult{User: db.User{ID: user.ID, Username: user.Username, Hash: arg.NewHash}}, nil
+					})
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvalidToken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "ShortPassword",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body.replace("new_password", util.RandomString(7)),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "TooLongPassword",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body.replace("new_password", util.RandomString(util.DefaultPasswordMaxLength+1)),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type createPersonalAccessTokenData struct {
+	Name      string     `json:"name" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Scopes    []string   `json:"scopes"`
+	ListIDs   []int32    `json:"list_ids"`
+}
+
+type personalAccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIp *string    `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Scopes     []string   `json:"scopes"`
+	ListIDs    []int32    `json:"list_ids"`
+}
+
+type createPersonalAccessTokenResponse struct {
+	personalAccessTokenResponse
+	Token string `json:"token"`
+}
+
+type getPersonalAccessTokensResponse struct {
+	Tokens []personalAccessTokenResponse `json:"tokens"`
+}
+
+func newPersonalAccessTokenResponse(pat db.GetPersonalAccessTokensRow) personalAccessTokenResponse {
+	response := personalAccessTokenResponse{
+		ID:        pat.ID,
+		Name:      pat.Name,
+		CreatedAt: pat.CreatedAt,
+		Scopes:    pat.Scopes,
+		ListIDs:   pat.ListIds,
+	}
+
+	if pat.ExpiresAt.Valid {
+		response.ExpiresAt = &pat.ExpiresAt.Time
+	}
+
+	if pat.LastUsedAt.Valid {
+		response.LastUsedAt = &pat.LastUsedAt.Time
+	}
+
+	if pat.LastUsedIp.Valid {
+		response.LastUsedIp = &pat.LastUsedIp.String
+	}
+
+	return response
+}
+
+func (s *Server) createPersonalAccessToken(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var data createPersonalAccessTokenData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	access, err := token.NewAccess(data.Scopes, data.ListIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if !authPayload.Includes(access) {
+		err := errors.New("token can't grant more access than it has")
+		ctx.JSON(http.StatusForbidden, errorResponse(err, ""))
+		return
+	}
+
+	// personal access token isn't bound to session, token minted by it mustn't outlive it
+	callerExpiresAt := authPayload.ExpiredAt
+	if authPayload.SessionID == uuid.Nil && !callerExpiresAt.IsZero() {
+		if data.ExpiresAt == nil || data.ExpiresAt.After(callerExpiresAt) {
+			err := errors.New("token can't expire later than personal access token it's created with")
+			ctx.JSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return
+	}
+
+	patToken, err := token.GeneratePersonalAccessToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
+		return
+	}
+
+	params := db.CreatePersonalAccessTokenParams{
+		ID:        id,
+		UserID:    ctx.MustGet(userIdKey).(int32),
+		Name:      data.Name,
+		TokenHash: token.HashPersonalAccessToken(patToken),
+		Scopes:    access.Scopes,
+		ListIds:   access.ListIDs,
+	}
+
+	if params.ListIds == nil {
+		params.ListIds = []int32{}
+	}
+
+	if data.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *data.ExpiresAt, Valid: true}
+	}
+
+	pat, err := s.store.CreatePersonalAccessToken(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createPersonalAccessTokenResponse{
+		personalAccessTokenResponse: newPersonalAccessTokenResponse(db.GetPersonalAccessTokensRow{
+			ID:         pat.ID,
+			Name:       pat.Name,
+			ExpiresAt:  pat.ExpiresAt,
+			LastUsedAt: pat.LastUsedAt,
+			LastUsedIp: pat.LastUsedIp,
+			CreatedAt:  pat.CreatedAt,
+			Scopes:     pat.Scopes,
+			ListIds:    pat.ListIds,
+		}),
+		Token: patToken,
+	})
+}
+
+func (s *Server) getPersonalAccessTokens(ctx *gin.Context) {
+	pats, err := s.store.GetPersonalAccessTokens(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := getPersonalAccessTokensResponse{
+		Tokens: make([]personalAccessTokenResponse, len(pats)),
+	}
+
+	for i, pat := range pats {
+		response.Tokens[i] = newPersonalAccessTokenResponse(pat)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s *Server) deletePersonalAccessToken(ctx *gin.Context) {
+	params := db.DeletePersonalAccessTokenParams{
+		ID:     ctx.MustGet(personalAccessTokenIdKey).(uuid.UUID),
+		UserID: ctx.MustGet(userIdKey).(int32),
+	}
+
+	deleted, err := s.store.DeletePersonalAccessToken(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if deleted == 0 {
+		err := errors.New("user doesn't have this token")
+		ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreatePersonalAccessTokenAPI(t *testing.T) {
+	user := util.RandomUser()
+	name := util.RandomString(8)
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        fmt.Sprintf("/users/%d/tokens", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	// caller authorized by personal access token which expires in an hour
+	callerExpiresAt := time.Now().Add(time.Hour)
+	callerPATCalls := func(store *mockdb.MockStore) *gomock.Call {
+		pat := db.GetPersonalAccessTokenByHashRow{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			Username:  user.Username,
+			Scopes:    token.Scopes,
+			ExpiresAt: sql.NullTime{Time: callerExpiresAt, Valid: true},
+		}
+
+		return store.EXPECT().
+			UpdatePersonalAccessTokenUsage(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(nil).
+			After(store.EXPECT().
+				GetPersonalAccessTokenByHash(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(pat, nil))
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, name, arg.Name)
+						require.NotEmpty(t, arg.TokenHash)
+						require.False(t, arg.ExpiresAt.Valid)
+						require.Equal(t, token.Scopes, arg.Scopes)
+
+						return db.PersonalAccessToken{ID: arg.ID, UserID: arg.UserID, Name: arg.Name, TokenHash: arg.TokenHash}, nil
+					}).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				gotResult := unmarshal[createPersonalAccessTokenResponse](t, recorder.Body)
+				require.True(t, token.IsPersonalAccessToken(gotResult.Token))
+				require.Equal(t, name, gotResult.Name)
+				require.Nil(t, gotResult.ExpiresAt)
+			},
+		},
+		{
+			name:          "WithExpiry",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name, "expires_at": time.Now().Add(time.Hour)},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+						require.True(t, arg.ExpiresAt.Valid)
+
+						return db.PersonalAccessToken{ID: arg.ID, Name: arg.Name, ExpiresAt: arg.ExpiresAt}, nil
+					}).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				gotResult := unmarshal[createPersonalAccessTokenResponse](t, recorder.Body)
+				require.NotNil(t, gotResult.ExpiresAt)
+			},
+		},
+		{
+			name:          "ExpiresInPast",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name, "expires_at": time.Now().Add(-time.Hour)},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "ExceedsTokenAccess",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name, "scopes": []string{token.ScopeTasksWrite}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				access := token.Access{Scopes: []string{token.ScopeAccountAdmin, token.ScopeTasksRead}}
+				addScopedAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, access, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "NoName",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PersonalAccessToken{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "FromPersonalAccessToken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name, "expires_at": callerExpiresAt.Add(-time.Minute)},
+			setupAuth:     addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+						return db.PersonalAccessToken{ID: arg.ID, Name: arg.Name, ExpiresAt: arg.ExpiresAt}, nil
+					}).
+					After(callerPATCalls(store))
+			},
+			checkResponse: requierResponseCode(http.StatusCreated),
+		},
+		{
+			name:          "FromPersonalAccessTokenWithoutExpiry",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				callerPATCalls(store)
+
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "OutlivesPersonalAccessToken",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"name": name, "expires_at": callerExpiresAt.Add(time.Minute)},
+			setupAuth:     addPersonalAccessToken(testPersonalAccessToken),
+			buildStubs: func(store *mockdb.MockStore) {
+				callerPATCalls(store)
+
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetPersonalAccessTokensAPI(t *testing.T) {
+	user := util.RandomUser()
+
+	pats := []db.GetPersonalAccessTokensRow{
+		{
+			ID:   uuid.New(),
+			Name: util.RandomString(8),
+		},
+		{
+			ID:         uuid.New(),
+			Name:       util.RandomString(8),
+			LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			LastUsedIp: sql.NullString{String: "127.0.0.1", Valid: true},
+		},
+	}
+
+	defaultSettings := struct {
+		methodGet string
+		url       string
+		setupAuth setupAuthFunc
+	}{
+		methodGet: http.MethodGet,
+		url:       fmt.Sprintf("/users/%d/tokens", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokens(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(pats, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[getPersonalAccessTokensResponse](t, recorder.Body)
+				require.Len(t, gotResult.Tokens, len(pats))
+				require.Equal(t, pats[0].ID, gotResult.Tokens[0].ID)
+				require.Nil(t, gotResult.Tokens[0].LastUsedIp)
+				require.Equal(t, "127.0.0.1", *gotResult.Tokens[1].LastUsedIp)
+				require.NotContains(t, recorder.Body.String(), "token_hash")
+			},
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokens(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeletePersonalAccessTokenAPI(t *testing.T) {
+	user := util.RandomUser()
+	patId := uuid.New()
+
+	defaultSettings := struct {
+		methodDelete string
+		url          string
+		setupAuth    setupAuthFunc
+	}{
+		methodDelete: http.MethodDelete,
+		url:          fmt.Sprintf("/users/%d/tokens/%s", user.ID, patId),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	deleteParams := db.DeletePersonalAccessTokenParams{
+		ID:     patId,
+		UserID: user.ID,
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Eq(deleteParams)).
+					Times(1).
+					Return(int64(1), nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvalidTokenId",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    fmt.Sprintf("/users/%d/tokens/%s", user.ID, "invalid"),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "TokenDoesNotExist",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Eq(deleteParams)).
+					Times(1).
+					Return(int64(0), nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Eq(deleteParams)).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type userProfileResponse struct {
+	ID            int32  `json:"user_id"`
+	Username      string `json:"username"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	DisplayName   string `json:"display_name"`
+	TimeZone      string `json:"time_zone"`
+	Locale        string `json:"locale"`
+}
+
+func newUserProfileResponse(user db.User) userProfileResponse {
+	return userProfileResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email.String,
+		EmailVerified: user.EmailVerified,
+		DisplayName:   user.DisplayName,
+		TimeZone:      user.TimeZone,
+		Locale:        user.Locale,
+	}
+}
+
+func (s *Server) getUserProfile(ctx *gin.Context) {
+	userId := ctx.MustGet(userIdKey).(int32)
+
+	user, err := s.store.GetUserByID(ctx, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserProfileResponse(user))
+}
+
+type updateUserProfileData struct {
+	Username    *string `json:"username"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=64"`
+	TimeZone    *string `json:"time_zone" binding:"omitempty,timezone"`
+	Locale      *string `json:"locale" binding:"omitempty,bcp47_language_tag"`
+}
+
+type updateUserProfileResponse struct {
+	userProfileResponse
+	// new session replaces the blocked ones after username change
+	Session *loginUserResponse `json:"session,omitempty"`
+}
+
+// updateUserProfile changes the given profile fields of user.
+// Username change blocks all sessions of user since their tokens carry the old username,
+// so the current session is replaced by a new one
+func (s *Server) updateUserProfile(ctx *gin.Context) {
+	userId := ctx.MustGet(userIdKey).(int32)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var data updateUserProfileData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.Username != nil {
+		if err := util.ValidateUsername(*data.Username); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+			return
+		}
+	}
+
+	params := db.UpdateUserProfileParams{
+		ID:          userId,
+		Username:    toNullString(data.Username),
+		DisplayName: toNullString(data.DisplayName),
+		TimeZone:    toNullString(data.TimeZone),
+		Locale:      toNullString(data.Locale),
+	}
+
+	if params.Username.String == authPayload.Username {
+		params.Username = sql.NullString{}
+	}
+
+	result, err := s.store.UpdateProfileTx(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "username is used by other user"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := updateUserProfileResponse{
+		userProfileResponse: newUserProfileResponse(result.User),
+	}
+
+	// personal access tokens aren't bound to session and keep working
+	if params.Username.Valid && authPayload.SessionID != uuid.Nil {
+		session, ok := s.createSession(ctx, result.User, authPayload.Access)
+		if !ok {
+			return
+		}
+
+		response.Session = &session
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func toNullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: *value, Valid: true}
+}
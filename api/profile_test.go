@@ -0,0 +1,375 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetUserProfileAPI(t *testing.T) {
+	user := util.RandomUser()
+
+	profile := db.User{
+		ID:            user.ID,
+		Username:      user.Username,
+		Hash:          user.Hash,
+		Email:         sql.NullString{String: util.RandomEmail(), Valid: true},
+		EmailVerified: true,
+		DisplayName:   util.RandomString(8),
+		TimeZone:      "Europe/Berlin",
+		Locale:        "de-DE",
+	}
+
+	defaultSettings := struct {
+		methodGet string
+		url       string
+		setupAuth setupAuthFunc
+	}{
+		methodGet: http.MethodGet,
+		url:       fmt.Sprintf("/users/%d", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[userProfileResponse](t, recorder.Body)
+				require.Equal(t, newUserProfileResponse(profile), *response)
+				require.NotContains(t, recorder.Body.String(), "hash")
+			},
+		},
+		{
+			name:          "NotFound",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "OtherUser",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    fmt.Sprintf("/users/%d", user.ID+1),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:          "Unauthorized",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestUpdateUserProfileAPI(t *testing.T) {
+	user := util.RandomUser()
+	newUsername := util.RandomUsername()
+
+	defaultSettings := struct {
+		methodPatch string
+		url         string
+		setupAuth   setupAuthFunc
+	}{
+		methodPatch: http.MethodPatch,
+		url:         fmt.Sprintf("/users/%d", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	updateProfileResult := func(_ context.Context, arg db.UpdateUserProfileParams) (db.UpdateProfileTxResult, error) {
+		updatedUser := db.User{ID: arg.ID, Username: user.Username, TimeZone: "UTC", Locale: "en"}
+
+		if arg.Username.Valid {
+			updatedUser.Username = arg.Username.String
+		}
+		if arg.DisplayName.Valid {
+			updatedUser.DisplayName = arg.DisplayName.String
+		}
+		if arg.TimeZone.Valid {
+			updatedUser.TimeZone = arg.TimeZone.String
+		}
+		if arg.Locale.Valid {
+			updatedUser.Locale = arg.Locale.String
+		}
+
+		return db.UpdateProfileTxResult{User: updatedUser}, nil
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"display_name": "Jane", "time_zone": "Asia/Tokyo", "locale": "ja-JP"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				expectedParams := db.UpdateUserProfileParams{
+					ID:          user.ID,
+					DisplayName: sql.NullString{String: "Jane", Valid: true},
+					TimeZone:    sql.NullString{String: "Asia/Tokyo", Valid: true},
+					Locale:      sql.NullString{String: "ja-JP", Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Eq(expectedParams)).
+					Times(1).
+					DoAndReturn(updateProfileResult)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[updateUserProfileResponse](t, recorder.Body)
+				require.Equal(t, "Jane", response.DisplayName)
+				require.Equal(t, "Asia/Tokyo", response.TimeZone)
+				require.Equal(t, "ja-JP", response.Locale)
+				require.Nil(t, response.Session)
+			},
+		},
+		{
+			name:          "UsernameChanged",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": newUsername},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				expectedParams := db.UpdateUserProfileParams{
+					ID:       user.ID,
+					Username: sql.NullString{String: newUsername, Valid: true},
+				}
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Eq(expectedParams)).
+					Times(1).
+					DoAndReturn(updateProfileResult)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, newUsername, arg.Username)
+
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[updateUserProfileResponse](t, recorder.Body)
+				require.Equal(t, newUsername, response.Username)
+				require.NotNil(t, response.Session)
+				require.NotEmpty(t, response.Session.AccessToken)
+				require.NotEmpty(t, response.Session.RefreshToken)
+			},
+		},
+		{
+			name:          "SameUsername",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": user.Username},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Eq(db.UpdateUserProfileParams{ID: user.ID})).
+					Times(1).
+					DoAndReturn(updateProfileResult)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "UsernameTaken",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": newUsername},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateProfileTxResult{}, &pq.Error{Code: "23505"})
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InvalidTimeZone",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"time_zone": "Mars/Olympus_Mons"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InvalidLocale",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"locale": "not a locale"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "EmptyUsername",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": ""},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "UsernameWithSpaces",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": " admin"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"display_name": "Jane"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizedCalls(store, user)
+
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateProfileTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "Unauthorized",
+			requestMethod: defaultSettings.methodPatch,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"display_name": "Jane"},
+			setupAuth:     func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateProfileTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -1,82 +1,339 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/mail"
+	"github.com/PYTNAG/simpletodo/oidc"
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	userIdKey = "user_id"
-	listIdKey = "list_id"
-	taskIdKey = "task_key"
+	userIdKey    = "user_id"
+	listIdKey    = "list_id"
+	taskIdKey    = "task_key"
+	sessionIdKey = "session_id"
+
+	personalAccessTokenIdKey = "token_id"
+	listShareIdKey           = "share_id"
+
+	workspaceIdKey           = "workspace_id"
+	workspaceMemberKey       = "workspace_member"
+	workspaceInvitationIdKey = "invitation_id"
+)
+
+const (
+	tokenModeLocal  = "local"
+	tokenModePublic = "public"
+)
+
+const (
+	loginAttemptStorePostgres = "postgres"
+	loginAttemptStoreMemory   = "memory"
 )
 
 // Server servers HTTP req-s for todo app
 type Server struct {
-	config      util.Config
-	store       db.Store
-	pasetoMaker *token.PasetoMaker
-	router      *gin.Engine
+	config         util.Config
+	store          db.Store
+	tokenMaker     token.Maker
+	mailer         mail.Mailer
+	loginLimiter   *lockout.Limiter
+	passwordPolicy *util.PasswordPolicy
+	oidcProvider   *oidc.Provider // nil if OpenID Connect login is disabled
+	router         *gin.Engine
+	background     sync.WaitGroup // work started off the request path by runInBackground
 }
 
 // NewServer creates a new HTTP server and setup routing
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	pasetoMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+func NewServer(config util.Config, store db.Store, mailer mail.Mailer) (*Server, error) {
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	loginLimiter, err := newLoginLimiter(config, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create login limiter: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	oidcProvider, err := newOidcProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create OpenID Connect provider: %w", err)
+	}
+
 	server := &Server{
-		config:      config,
-		store:       store,
-		pasetoMaker: pasetoMaker,
+		config:         config,
+		store:          store,
+		tokenMaker:     tokenMaker,
+		mailer:         mailer,
+		loginLimiter:   loginLimiter,
+		passwordPolicy: passwordPolicy,
+		oidcProvider:   oidcProvider,
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, fmt.Errorf("cannot setup router: %w", err)
+	}
 
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+// newTokenMaker creates token maker of configured mode, v4.local is used by default
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	switch config.TokenMode {
+	case "", tokenModeLocal:
+		verificationKeys, err := token.ParseKeys(config.TokenVerificationKeys)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse verification keys: %w", err)
+		}
+
+		primaryKey := token.Key{
+			ID:  config.TokenKeyID,
+			Key: config.TokenSymmetricKey,
+		}
+
+		return token.NewPasetoMaker(primaryKey, verificationKeys...)
+	case tokenModePublic:
+		publicKeys, err := token.ParseKeys(config.TokenPublicKeys)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse public keys: %w", err)
+		}
+
+		secretKey := token.Key{
+			ID:  config.TokenKeyID,
+			Key: config.TokenSecretKey,
+		}
+
+		return token.NewPublicPasetoMaker(secretKey, publicKeys...)
+	default:
+		return nil, fmt.Errorf("unsupported token mode %s", config.TokenMode)
+	}
+}
+
+// newLoginLimiter creates limiter with attempt store of configured kind, postgres is used by default
+func newLoginLimiter(config util.Config, store db.Store) (*lockout.Limiter, error) {
+	policy := lockout.Policy{
+		FreeAttempts:    config.LoginFreeAttempts,
+		BackoffBase:     config.LoginBackoffBase,
+		MaxAttempts:     config.LoginMaxAttempts,
+		LockoutDuration: config.LoginLockoutDuration,
+	}
+
+	switch config.LoginAttemptStore {
+	case "", loginAttemptStorePostgres:
+		return lockout.NewLimiter(lockout.NewPostgresStore(store), policy), nil
+	case loginAttemptStoreMemory:
+		return lockout.NewLimiter(lockout.NewMemoryStore(), policy), nil
+	default:
+		return nil, fmt.Errorf("unsupported login attempt store %s", config.LoginAttemptStore)
+	}
+}
+
+// newOidcProvider discovers configured OpenID Connect provider, returns nil if issuer isn't configured
+func newOidcProvider(config util.Config) (*oidc.Provider, error) {
+	if config.OIDCIssuerURL == "" {
+		return nil, nil
+	}
+
+	oidcConfig := oidc.Config{
+		IssuerURL:    config.OIDCIssuerURL,
+		ClientID:     config.OIDCClientID,
+		ClientSecret: config.OIDCClientSecret,
+		RedirectURL:  config.OIDCRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+
+	return oidc.NewProvider(context.Background(), oidcConfig, nil)
+}
+
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// client IP keys login attempts, so forwarding headers are trusted only from configured proxies
+	if err := router.SetTrustedProxies(parseTrustedProxies(server.config.TrustedProxies)); err != nil {
+		return fmt.Errorf("cannot set trusted proxies: %w", err)
+	}
+
 	authRoutes := router.Group("/")
-	authRoutes.Use(authMiddleware(*server.pasetoMaker))
+	authRoutes.Use(authMiddleware(server.tokenMaker, server.store))
 
 	userRequestRoutes := server.getNewIdRequestGroup(authRoutes, "/users/:%s", userIdKey)
 	userRequestRoutes.Use(compareRequestedIdMiddleware(server.store))
 
 	listRequestRoutes := server.getNewIdRequestGroup(userRequestRoutes, "/lists/:%s", listIdKey)
-	listRequestRoutes.Use(checkListAuthorMiddleware(server.store))
+	listRequestRoutes.Use(listAccessMiddleware(), checkListAuthorMiddleware(server.store))
 
 	taskRequestRoutes := server.getNewIdRequestGroup(listRequestRoutes, "/tasks/:%s", taskIdKey)
 	taskRequestRoutes.Use(checkTaskParentListMiddleware(server.store))
 
+	listShareRequestRoutes := listRequestRoutes.Group(fmt.Sprintf("/shares/:%s", listShareIdKey))
+	listShareRequestRoutes.Use(uuidRequestMiddleware(listShareIdKey))
+
+	sessionRequestRoutes := userRequestRoutes.Group(fmt.Sprintf("/sessions/:%s", sessionIdKey))
+	sessionRequestRoutes.Use(uuidRequestMiddleware(sessionIdKey))
+
+	personalAccessTokenRequestRoutes := userRequestRoutes.Group(fmt.Sprintf("/tokens/:%s", personalAccessTokenIdKey))
+	personalAccessTokenRequestRoutes.Use(uuidRequestMiddleware(personalAccessTokenIdKey))
+
+	userInvitationRequestRoutes := userRequestRoutes.Group(fmt.Sprintf("/invitations/:%s", workspaceInvitationIdKey))
+	userInvitationRequestRoutes.Use(uuidRequestMiddleware(workspaceInvitationIdKey))
+
+	workspaceRequestRoutes := server.getNewIdRequestGroup(authRoutes, "/workspaces/:%s", workspaceIdKey)
+	workspaceRequestRoutes.Use(workspaceMemberMiddleware(server.store))
+
+	workspaceMemberRequestRoutes := server.getNewIdRequestGroup(workspaceRequestRoutes, "/members/:%s", userIdKey)
+
+	workspaceInvitationRequestRoutes := workspaceRequestRoutes.Group(fmt.Sprintf("/invitations/:%s", workspaceInvitationIdKey))
+	workspaceInvitationRequestRoutes.Use(uuidRequestMiddleware(workspaceInvitationIdKey))
+
+	workspaceListRequestRoutes := server.getNewIdRequestGroup(workspaceRequestRoutes, "/lists/:%s", listIdKey)
+	workspaceListRequestRoutes.Use(listAccessMiddleware(), checkWorkspaceListMiddleware(server.store))
+
+	workspaceTaskRequestRoutes := server.getNewIdRequestGroup(workspaceListRequestRoutes, "/tasks/:%s", taskIdKey)
+	workspaceTaskRequestRoutes.Use(checkTaskParentListMiddleware(server.store))
+
+	adminRoutes := authRoutes.Group("/admin")
+	adminRoutes.Use(scopeMiddleware(token.ScopeAccountAdmin), adminMiddleware(server.store))
+
+	adminUserRequestRoutes := server.getNewIdRequestGroup(adminRoutes, "/users/:%s", userIdKey)
+
 	// user
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
-	userRequestRoutes.PUT("", server.rehashUser)
-	userRequestRoutes.DELETE("", server.deleteUser)
+	router.POST("/users/login/2fa", server.loginTwoFactor)
+	router.POST("/users/password_reset", server.requestPasswordReset)
+	router.POST("/users/password_reset/confirm", server.confirmPasswordReset)
+	router.POST("/users/email/verify", server.verifyEmail)
+	if server.oidcProvider != nil {
+		router.GET("/users/oidc/login", server.startOidcLogin)
+		router.GET("/users/oidc/callback", server.finishOidcLogin)
+		userRequestRoutes.POST("/oidc", scopeMiddleware(token.ScopeAccountAdmin), server.startOidcLink)
+	}
+	userRequestRoutes.GET("", scopeMiddleware(token.ScopeAccountAdmin), server.getUserProfile)
+	userRequestRoutes.PATCH("", scopeMiddleware(token.ScopeAccountAdmin), server.updateUserProfile)
+	userRequestRoutes.PUT("", scopeMiddleware(token.ScopeAccountAdmin), server.rehashUser)
+	userRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), server.deleteUser)
+	userRequestRoutes.PUT("/email", scopeMiddleware(token.ScopeAccountAdmin), server.changeEmail)
+	userRequestRoutes.POST("/email/verification", scopeMiddleware(token.ScopeAccountAdmin), server.resendEmailVerification)
+
+	// sessions
+	userRequestRoutes.GET("/sessions", scopeMiddleware(token.ScopeAccountAdmin), server.getUserSessions)
+	sessionRequestRoutes.GET("", scopeMiddleware(token.ScopeAccountAdmin), server.getUserSession)
+	userRequestRoutes.DELETE("/sessions", scopeMiddleware(token.ScopeAccountAdmin), server.deleteUserSessions)
+	sessionRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), server.deleteUserSession)
+
+	// personal access tokens
+	userRequestRoutes.GET("/tokens", scopeMiddleware(token.ScopeAccountAdmin), server.getPersonalAccessTokens)
+	userRequestRoutes.POST("/tokens", scopeMiddleware(token.ScopeAccountAdmin), server.createPersonalAccessToken)
+	personalAccessTokenRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), server.deletePersonalAccessToken)
+
+	// two factor authentication
+	userRequestRoutes.POST("/2fa", scopeMiddleware(token.ScopeAccountAdmin), server.enrollTOTP)
+	userRequestRoutes.POST("/2fa/confirm", scopeMiddleware(token.ScopeAccountAdmin), server.confirmTOTP)
+	userRequestRoutes.DELETE("/2fa", scopeMiddleware(token.ScopeAccountAdmin), server.disableTOTP)
 
 	// lists
-	userRequestRoutes.GET("/lists", server.getUserLists)
-	userRequestRoutes.POST("/lists", server.addListToUser)
-	listRequestRoutes.DELETE("", server.deleteUserList)
+	userRequestRoutes.GET("/lists", scopeMiddleware(token.ScopeListsRead), server.getUserLists)
+	userRequestRoutes.POST("/lists", scopeMiddleware(token.ScopeListsWrite), server.addListToUser)
+	listRequestRoutes.PATCH("", scopeMiddleware(token.ScopeListsWrite), server.updateUserList)
+	listRequestRoutes.DELETE("", scopeMiddleware(token.ScopeListsWrite), server.deleteUserList)
+	listRequestRoutes.PUT("/position", scopeMiddleware(token.ScopeListsWrite), server.moveUserList)
+
+	// list share links
+	router.GET("/shared/:token", server.getSharedList)
+	listRequestRoutes.GET("/shares", scopeMiddleware(token.ScopeListsRead), server.getListShares)
+	listRequestRoutes.POST("/shares", scopeMiddleware(token.ScopeListsWrite), server.createListShare)
+	listShareRequestRoutes.DELETE("", scopeMiddleware(token.ScopeListsWrite), server.deleteListShare)
+	listRequestRoutes.POST("/transfer", scopeMiddleware(token.ScopeListsWrite), server.transferListToWorkspace)
 
 	// tasks
-	listRequestRoutes.GET("/tasks", server.getTasks)
-	listRequestRoutes.POST("/tasks", server.addTask)
-	taskRequestRoutes.PUT("", server.updateTask)
-	taskRequestRoutes.DELETE("", server.deleteTask)
+	listRequestRoutes.GET("/tasks", scopeMiddleware(token.ScopeTasksRead), server.getTasks)
+	listRequestRoutes.POST("/tasks", scopeMiddleware(token.ScopeTasksWrite), server.addTask)
+	taskRequestRoutes.PUT("", scopeMiddleware(token.ScopeTasksWrite), server.updateTask)
+	taskRequestRoutes.PUT("/parent", scopeMiddleware(token.ScopeTasksWrite), server.setTaskParent)
+	taskRequestRoutes.PUT("/position", scopeMiddleware(token.ScopeTasksWrite), server.moveTask)
+	taskRequestRoutes.DELETE("", scopeMiddleware(token.ScopeTasksWrite), server.deleteTask)
+
+	// workspaces
+	userRequestRoutes.GET("/workspaces", scopeMiddleware(token.ScopeAccountAdmin), server.getUserWorkspaces)
+	userRequestRoutes.POST("/workspaces", scopeMiddleware(token.ScopeAccountAdmin), server.createWorkspace)
+	workspaceRequestRoutes.GET("", scopeMiddleware(token.ScopeAccountAdmin), server.getWorkspace)
+	workspaceRequestRoutes.PATCH("", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.updateWorkspace)
+	workspaceRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleOwner), server.deleteWorkspace)
+
+	// workspace members
+	workspaceRequestRoutes.GET("/members", scopeMiddleware(token.ScopeAccountAdmin), server.getWorkspaceMembers)
+	workspaceMemberRequestRoutes.PATCH("", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.updateWorkspaceMember)
+	workspaceMemberRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), server.removeWorkspaceMember)
+
+	// workspace invitations
+	workspaceRequestRoutes.GET("/invitations", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.getWorkspaceInvitations)
+	workspaceRequestRoutes.POST("/invitations", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.inviteToWorkspace)
+	workspaceInvitationRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.deleteWorkspaceInvitation)
+	userRequestRoutes.GET("/invitations", scopeMiddleware(token.ScopeAccountAdmin), server.getUserInvitations)
+	userInvitationRequestRoutes.POST("/accept", scopeMiddleware(token.ScopeAccountAdmin), server.acceptWorkspaceInvitation)
+	userInvitationRequestRoutes.DELETE("", scopeMiddleware(token.ScopeAccountAdmin), server.declineWorkspaceInvitation)
+
+	// workspace lists and tasks
+	workspaceRequestRoutes.GET("/lists", scopeMiddleware(token.ScopeListsRead), server.getWorkspaceLists)
+	workspaceRequestRoutes.POST("/lists", scopeMiddleware(token.ScopeListsWrite), server.addListToWorkspace)
+	workspaceListRequestRoutes.PATCH("", scopeMiddleware(token.ScopeListsWrite), server.updateUserList)
+	workspaceListRequestRoutes.DELETE("", scopeMiddleware(token.ScopeListsWrite), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.deleteUserList)
+	workspaceListRequestRoutes.PUT("/position", scopeMiddleware(token.ScopeListsWrite), server.moveUserList)
+	workspaceListRequestRoutes.POST("/transfer", scopeMiddleware(token.ScopeListsWrite), workspaceRoleMiddleware(db.WorkspaceRoleAdmin), server.transferListFromWorkspace)
+	workspaceListRequestRoutes.GET("/tasks", scopeMiddleware(token.ScopeTasksRead), server.getTasks)
+	workspaceListRequestRoutes.POST("/tasks", scopeMiddleware(token.ScopeTasksWrite), server.addTask)
+	workspaceTaskRequestRoutes.PUT("", scopeMiddleware(token.ScopeTasksWrite), server.updateTask)
+	workspaceTaskRequestRoutes.PUT("/parent", scopeMiddleware(token.ScopeTasksWrite), server.setTaskParent)
+	workspaceTaskRequestRoutes.PUT("/position", scopeMiddleware(token.ScopeTasksWrite), server.moveTask)
+	workspaceTaskRequestRoutes.DELETE("", scopeMiddleware(token.ScopeTasksWrite), server.deleteTask)
+
+	// admin
+	adminRoutes.GET("/users", server.listUsers)
+	adminUserRequestRoutes.GET("", server.getUser)
+	adminUserRequestRoutes.GET("/usage", server.getUserUsage)
+	adminUserRequestRoutes.POST("/disable", server.disableUser)
+	adminUserRequestRoutes.POST("/enable", server.enableUser)
+	adminUserRequestRoutes.DELETE("/sessions", server.logoutUser)
+	adminRoutes.DELETE("/login-locks/:kind/*key", server.unlockLogin)
 
 	// tokens
 	router.POST("/tokens/refresh_access", server.refreshAccessToken)
+	router.POST("/tokens/revoke", server.revokeRefreshToken)
+
+	if _, ok := server.tokenMaker.(*token.PublicPasetoMaker); ok {
+		router.GET("/.well-known/paseto-keys", server.getPublicKeys)
+	}
 
 	server.router = router
+
+	return nil
+}
+
+// parseTrustedProxies splits comma separated proxies, nil means forwarding headers are never trusted
+func parseTrustedProxies(proxies string) []string {
+	var trusted []string
+
+	for _, proxy := range strings.Split(proxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+
+	return trusted
 }
 
 func (s *Server) getNewIdRequestGroup(prev *gin.RouterGroup, pathTemplate, idKey string) *gin.RouterGroup {
@@ -90,6 +347,16 @@ func (s *Server) Start(address string) error {
 	return s.router.Run(address)
 }
 
+// runInBackground runs fn after the response without holding the request, its errors have to be logged by fn
+func (s *Server) runInBackground(fn func()) {
+	s.background.Add(1)
+
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
 func errorResponse(err error, additionalMessage string) gin.H {
 	response := gin.H{}
 
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type getUserSessionsData struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+type userSessionResponse struct {
+	db.GetUserSessionsRow
+	Current bool `json:"current"`
+}
+
+type getUserSessionsResponse struct {
+	Sessions []userSessionResponse `json:"sessions"`
+}
+
+func (s *Server) getUserSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var data getUserSessionsData
+	if err := ctx.ShouldBindQuery(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.GetUserSessionsParams{
+		Username: authPayload.Username,
+		Limit:    data.PageSize,
+		Offset:   (data.PageID - 1) * data.PageSize,
+	}
+
+	sessions, err := s.store.GetUserSessions(ctx, params)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := getUserSessionsResponse{
+		Sessions: make([]userSessionResponse, len(sessions)),
+	}
+
+	for i, session := range sessions {
+		response.Sessions[i] = userSessionResponse{
+			GetUserSessionsRow: session,
+			Current:            session.ID == authPayload.SessionID,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getUserSession(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	params := db.GetUserSessionParams{
+		ID:       ctx.MustGet(sessionIdKey).(uuid.UUID),
+		Username: authPayload.Username,
+	}
+
+	session, err := s.store.GetUserSession(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't have this session"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userSessionResponse{
+		GetUserSessionsRow: db.GetUserSessionsRow(session),
+		Current:            session.ID == authPayload.SessionID,
+	})
+}
+
+func (s *Server) deleteUserSession(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	params := db.BlockUserSessionParams{
+		ID:       ctx.MustGet(sessionIdKey).(uuid.UUID),
+		Username: authPayload.Username,
+	}
+
+	session, err := s.store.BlockUserSession(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't have this session"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	// rotated ancestors of session may still have valid access tokens
+	if err := s.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+func (s *Server) deleteUserSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := s.store.BlockUserSessions(ctx, authPayload.Username); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,361 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetUserSessionsAPI(t *testing.T) {
+	user := util.RandomUser()
+	currentSessionId := uuid.New()
+
+	userSessions := []db.GetUserSessionsRow{
+		{
+			ID:        currentSessionId,
+			UserAgent: util.RandomString(16),
+			ClientIp:  "127.0.0.1",
+		},
+		{
+			ID:        uuid.New(),
+			UserAgent: util.RandomString(16),
+			ClientIp:  "127.0.0.1",
+			IsBlocked: true,
+		},
+	}
+
+	defaultSettings := struct {
+		methodGet string
+		url       string
+		body      requestBody
+		setupAuth setupAuthFunc
+	}{
+		methodGet: http.MethodGet,
+		url:       fmt.Sprintf("/users/%d/sessions?page_id=%d&page_size=%d", user.ID, 1, 5),
+		body:      requestBody{},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			accessToken, _, err := tokenMaker.CreateToken(user.Username, user.ID, currentSessionId, token.FullAccess(), time.Minute)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+		},
+	}
+
+	getUserSessionsParams := db.GetUserSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSessions(gomock.Any(), gomock.Eq(getUserSessionsParams)).
+					Times(1).
+					Return(userSessions, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "refresh_token")
+
+				sessions := unmarshal[getUserSessionsResponse](t, recorder.Body)
+
+				require.Len(t, sessions.Sessions, len(userSessions))
+
+				for i, session := range sessions.Sessions {
+					require.Equal(t, userSessions[i].ID, session.ID)
+					require.Equal(t, userSessions[i].IsBlocked, session.IsBlocked)
+					require.Equal(t, session.ID == currentSessionId, session.Current)
+				}
+			},
+		},
+		{
+			name:          "InvalidPageSize",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    fmt.Sprintf("/users/%d/sessions?page_id=%d&page_size=%d", user.ID, 1, 100),
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSessions(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSessions(gomock.Any(), gomock.Eq(getUserSessionsParams)).
+					Times(1).
+					Return([]db.GetUserSessionsRow{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetUserSessionAPI(t *testing.T) {
+	user := util.RandomUser()
+	sessionId := uuid.New()
+
+	defaultSettings := struct {
+		methodGet string
+		url       string
+		body      requestBody
+		setupAuth setupAuthFunc
+	}{
+		methodGet: http.MethodGet,
+		url:       fmt.Sprintf("/users/%d/sessions/%s", user.ID, sessionId),
+		body:      requestBody{},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	getUserSessionParams := db.GetUserSessionParams{
+		ID:       sessionId,
+		Username: user.Username,
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(getUserSessionParams)).
+					Times(1).
+					Return(db.GetUserSessionRow{ID: sessionId}, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				session := unmarshal[userSessionResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, sessionId, session.ID)
+				require.False(t, session.Current)
+			},
+		},
+		{
+			name:          "SessionDoesNotExist",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(getUserSessionParams)).
+					Times(1).
+					Return(db.GetUserSessionRow{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserSession(gomock.Any(), gomock.Eq(getUserSessionParams)).
+					Times(1).
+					Return(db.GetUserSessionRow{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeleteUserSessionAPI(t *testing.T) {
+	user := util.RandomUser()
+	sessionId := uuid.New()
+
+	defaultSettings := struct {
+		methodDelete string
+		url          string
+		body         requestBody
+		setupAuth    setupAuthFunc
+	}{
+		methodDelete: http.MethodDelete,
+		url:          fmt.Sprintf("/users/%d/sessions/%s", user.ID, sessionId),
+		body:         requestBody{},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	blockUserSessionParams := db.BlockUserSessionParams{
+		ID:       sessionId,
+		Username: user.Username,
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				familyId := uuid.New()
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+
+					store.EXPECT().
+						BlockUserSession(gomock.Any(), gomock.Eq(blockUserSessionParams)).
+						Times(1).
+						Return(db.Session{ID: sessionId, Username: user.Username, IsBlocked: true, FamilyID: familyId}, nil),
+
+					store.EXPECT().
+						BlockSessionFamily(gomock.Any(), gomock.Eq(familyId)).
+						Times(1).
+						Return(nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvalidSessionId",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    fmt.Sprintf("/users/%d/sessions/%s", user.ID, "invalid"),
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSession(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "SessionDoesNotExist",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSession(gomock.Any(), gomock.Eq(blockUserSessionParams)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSession(gomock.Any(), gomock.Eq(blockUserSessionParams)).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeleteUserSessionsAPI(t *testing.T) {
+	user := util.RandomUser()
+
+	defaultSettings := struct {
+		methodDelete string
+		url          string
+		body         requestBody
+		setupAuth    setupAuthFunc
+	}{
+		methodDelete: http.MethodDelete,
+		url:          fmt.Sprintf("/users/%d/sessions", user.ID),
+		body:         requestBody{},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,13 +12,41 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+type getTasksData struct {
+	View string `form:"view" binding:"omitempty,oneof=flat tree"`
+	Root int32  `form:"root" binding:"omitempty,min=1"`
+}
+
 type getTasksResponse struct {
 	Tasks []db.Task `json:"tasks"`
 }
 
+type getTaskTreeResponse struct {
+	Tasks []*taskNode `json:"tasks"`
+}
+
+// taskNode is a task with its subtasks, Done and Total count all descendants of the task
+type taskNode struct {
+	db.Task
+	Done     int         `json:"done"`
+	Total    int         `json:"total"`
+	Children []*taskNode `json:"children"`
+}
+
 func (s *Server) getTasks(ctx *gin.Context) {
 	listId := ctx.MustGet(listIdKey).(int32)
 
+	var data getTasksData
+	if err := ctx.ShouldBindQuery(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.View == "tree" {
+		s.getTaskTree(ctx, listId, data.Root)
+		return
+	}
+
 	tasks, err := s.store.GetTasks(ctx, listId)
 	if err != nil && err != sql.ErrNoRows {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
@@ -27,6 +56,76 @@ func (s *Server) getTasks(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, getTasksResponse{Tasks: tasks})
 }
 
+// getTaskTree responds with nested tasks of the list, or only with subtree of the root task if it's set
+func (s *Server) getTaskTree(ctx *gin.Context, listId int32, root int32) {
+	params := db.GetTaskTreeParams{
+		ListID:   listId,
+		RootTask: dbtypes.NewNullInt32(root, root > 0),
+	}
+
+	rows, err := s.store.GetTaskTree(ctx, params)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if root > 0 && len(rows) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("list doesn't have task %d", root), ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getTaskTreeResponse{Tasks: buildTaskTree(rows)})
+}
+
+// buildTaskTree nests rows ordered by depth and counts completion of descendants
+func buildTaskTree(rows []db.GetTaskTreeRow) []*taskNode {
+	nodes := make(map[int32]*taskNode, len(rows))
+	roots := []*taskNode{}
+
+	for _, row := range rows {
+		node := &taskNode{
+			Task: db.Task{
+				ID:         row.ID,
+				ListID:     row.ListID,
+				ParentTask: row.ParentTask,
+				Task:       row.Task,
+				Complete:   row.Complete,
+				Position:   row.Position,
+			},
+			Children: []*taskNode{},
+		}
+		nodes[row.ID] = node
+
+		// parent is always before its children, so missing parent means root of the tree
+		parent, ok := nodes[row.ParentTask.Int32]
+		if row.ParentTask.Valid && ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	for _, root := range roots {
+		root.countDescendants()
+	}
+
+	return roots
+}
+
+func (n *taskNode) countDescendants() {
+	n.Done, n.Total = 0, 0
+
+	for _, child := range n.Children {
+		child.countDescendants()
+
+		n.Total += child.Total + 1
+		n.Done += child.Done
+		if child.Complete {
+			n.Done++
+		}
+	}
+}
+
 type updateTaskData struct {
 	Type string `json:"type" binding:"required,oneof=CHECK TEXT"`
 	Text string `json:"text" binding:"required_if=Type TEXT"`
@@ -88,12 +187,93 @@ func (s *Server) addTask(ctx *gin.Context) {
 
 	task, err := s.store.AddTask(ctx, params)
 	if err != nil {
+		if db.IsTaskParentNotInList(err) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, "parent task must be in the same list"))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
 		return
 	}
 	ctx.JSON(http.StatusCreated, taskResponse{ID: task.ID})
 }
 
+type setTaskParentData struct {
+	ParentTask int32 `json:"parent_task" binding:"omitempty,min=1"`
+}
+
+// setTaskParent moves task with its subtasks under another task of the same list,
+// task without parent_task becomes top level one
+func (s *Server) setTaskParent(ctx *gin.Context) {
+	var data setTaskParentData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.SetTaskParentParams{
+		ID:         ctx.MustGet(taskIdKey).(int32),
+		ParentTask: dbtypes.NewNullInt32(data.ParentTask, data.ParentTask > 0),
+	}
+
+	task, err := s.store.SetTaskParent(ctx, params)
+	if err != nil {
+		if db.IsTaskParentNotInList(err) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, "parent task must be in the same list"))
+			return
+		}
+
+		if db.IsTaskParentCycle(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "task cannot be moved under its own subtask"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+func (s *Server) moveTask(ctx *gin.Context) {
+	taskId := ctx.MustGet(taskIdKey).(int32)
+
+	var data moveData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if data.After == taskId {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("task cannot be placed after itself"), ""))
+		return
+	}
+
+	params := db.MoveTaskTxParams{
+		ID:     taskId,
+		ListID: ctx.MustGet(listIdKey).(int32),
+		After:  data.After,
+	}
+
+	result, err := s.store.MoveTaskTx(ctx, params)
+	if err != nil {
+		if err == db.ErrNotSibling {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+			return
+		}
+
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, fmt.Sprintf("There is no task %d", taskId)))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Task)
+}
+
 func (s *Server) deleteTask(ctx *gin.Context) {
 	taskId := ctx.MustGet(taskIdKey).(int32)
 
@@ -13,6 +13,7 @@ import (
 	dbtypes "github.com/PYTNAG/simpletodo/db/types"
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -34,6 +35,12 @@ func TestGetTasksAPI(t *testing.T) {
 		},
 	}
 
+	treeRows := []db.GetTaskTreeRow{
+		{ID: 1, ListID: listId, Task: util.RandomString(8)},
+		{ID: 2, ListID: listId, ParentTask: dbtypes.NewNullInt32(1, true), Task: util.RandomString(8), Depth: 1},
+		{ID: 3, ListID: listId, ParentTask: dbtypes.NewNullInt32(2, true), Task: util.RandomString(8), Complete: true, Depth: 2},
+	}
+
 	defaultSettings := struct {
 		methodGet string
 		url       string
@@ -43,8 +50,8 @@ func TestGetTasksAPI(t *testing.T) {
 		methodGet: http.MethodGet,
 		url:       fmt.Sprintf("/users/%d/lists/%d/tasks", user.ID, listId),
 		body:      requestBody{},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -57,7 +64,7 @@ func TestGetTasksAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -87,7 +94,7 @@ func TestGetTasksAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -111,7 +118,7 @@ func TestGetTasksAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -122,6 +129,116 @@ func TestGetTasksAPI(t *testing.T) {
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
 		},
+		{
+			name:          "Tree",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url + "?view=tree",
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.GetTaskTreeParams{
+					ListID:   listId,
+					RootTask: dbtypes.NewNullInt32(0, false),
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetTaskTree(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(treeRows, nil),
+				)
+
+				store.EXPECT().
+					GetTasks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				tree := unmarshal[getTaskTreeResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Len(t, tree.Tasks, 1)
+				require.Equal(t, treeRows[0].ID, tree.Tasks[0].ID)
+				require.Len(t, tree.Tasks[0].Children, 1)
+				require.Equal(t, 2, tree.Tasks[0].Total)
+				require.Equal(t, 1, tree.Tasks[0].Done)
+			},
+		},
+		{
+			name:          "Subtree",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    fmt.Sprintf("%s?view=tree&root=%d", defaultSettings.url, treeRows[1].ID),
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.GetTaskTreeParams{
+					ListID:   listId,
+					RootTask: dbtypes.NewNullInt32(treeRows[1].ID, true),
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetTaskTree(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(treeRows[1:], nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				tree := unmarshal[getTaskTreeResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Len(t, tree.Tasks, 1)
+				require.Equal(t, treeRows[1].ID, tree.Tasks[0].ID)
+				require.Equal(t, 1, tree.Tasks[0].Total)
+				require.Equal(t, 1, tree.Tasks[0].Done)
+			},
+		},
+		{
+			name:          "SubtreeNotFound",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url + "?view=tree&root=1",
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetTaskTree(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return([]db.GetTaskTreeRow{}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "InvalidView",
+			requestMethod: defaultSettings.methodGet,
+			requestUrl:    defaultSettings.url + "?view=graph",
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+				)
+
+				store.EXPECT().
+					GetTaskTree(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetTasks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -129,6 +246,35 @@ func TestGetTasksAPI(t *testing.T) {
 	}
 }
 
+func TestBuildTaskTree(t *testing.T) {
+	rows := []db.GetTaskTreeRow{
+		{ID: 1, Depth: 0},
+		{ID: 2, Depth: 0, Complete: true},
+		{ID: 3, ParentTask: dbtypes.NewNullInt32(1, true), Depth: 1, Complete: true},
+		{ID: 4, ParentTask: dbtypes.NewNullInt32(1, true), Depth: 1},
+		{ID: 5, ParentTask: dbtypes.NewNullInt32(4, true), Depth: 2, Complete: true},
+	}
+
+	roots := buildTaskTree(rows)
+	require.Len(t, roots, 2)
+
+	require.Equal(t, int32(1), roots[0].ID)
+	require.Equal(t, 3, roots[0].Total)
+	require.Equal(t, 2, roots[0].Done)
+	require.Len(t, roots[0].Children, 2)
+
+	child := roots[0].Children[1]
+	require.Equal(t, int32(4), child.ID)
+	require.Equal(t, 1, child.Total)
+	require.Equal(t, 1, child.Done)
+
+	require.Equal(t, int32(2), roots[1].ID)
+	require.Zero(t, roots[1].Total)
+	require.Empty(t, roots[1].Children)
+
+	require.Empty(t, buildTaskTree([]db.GetTaskTreeRow{}))
+}
+
 func TestUpdateTaskAPI(t *testing.T) {
 	user := util.RandomUser()
 	listId := util.RandomID()
@@ -143,8 +289,8 @@ func TestUpdateTaskAPI(t *testing.T) {
 	}{
 		methodPut: http.MethodPut,
 		url:       fmt.Sprintf("/users/%d/lists/%d/tasks/%d", user.ID, listId, taskId),
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -165,7 +311,7 @@ func TestUpdateTaskAPI(t *testing.T) {
 				}
 
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -193,7 +339,7 @@ func TestUpdateTaskAPI(t *testing.T) {
 				}
 
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -215,7 +361,7 @@ func TestUpdateTaskAPI(t *testing.T) {
 			setupAuth: defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -237,7 +383,7 @@ func TestUpdateTaskAPI(t *testing.T) {
 			setupAuth: defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -257,7 +403,7 @@ func TestUpdateTaskAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -293,8 +439,8 @@ func TestAddTaskAPI(t *testing.T) {
 		body: requestBody{
 			"task": newTaskText,
 		},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -313,7 +459,7 @@ func TestAddTaskAPI(t *testing.T) {
 				}
 
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -347,7 +493,7 @@ func TestAddTaskAPI(t *testing.T) {
 				}
 
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -364,6 +510,28 @@ func TestAddTaskAPI(t *testing.T) {
 				require.Equal(t, taskId, task.ID)
 			},
 		},
+		{
+			name:          "ParentOfOtherList",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody: requestBody{
+				"task":        newTaskText,
+				"parent_task": taskId,
+			},
+			setupAuth: defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						AddTask(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Task{}, &pq.Error{Code: "23503", Constraint: "tasks_parent_task_list"}),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
 		{
 			name:          "WrongBody",
 			requestMethod: defaultSettings.methodPost,
@@ -372,7 +540,7 @@ func TestAddTaskAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -396,7 +564,7 @@ func TestAddTaskAPI(t *testing.T) {
 				}
 
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 
 					store.EXPECT().
@@ -414,6 +582,288 @@ func TestAddTaskAPI(t *testing.T) {
 	}
 }
 
+func TestSetTaskParentAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	taskId := util.RandomID()
+	parentId := util.RandomID()
+
+	url := fmt.Sprintf("/users/%d/lists/%d/tasks/%d/parent", user.ID, listId, taskId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"parent_task": parentId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.SetTaskParentParams{
+					ID:         taskId,
+					ParentTask: dbtypes.NewNullInt32(parentId, true),
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						SetTaskParent(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.Task{ID: taskId, ListID: listId, ParentTask: params.ParentTask}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				task := unmarshal[db.Task](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, taskId, task.ID)
+				require.Equal(t, dbtypes.NewNullInt32(parentId, true), task.ParentTask)
+			},
+		},
+		{
+			name:          "OK(TopLevel)",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.SetTaskParentParams{
+					ID:         taskId,
+					ParentTask: dbtypes.NewNullInt32(0, false),
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						SetTaskParent(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.Task{ID: taskId, ListID: listId}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "ParentOfOtherList",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"parent_task": parentId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						SetTaskParent(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Task{}, &pq.Error{Code: "23503", Constraint: "tasks_parent_task_list"}),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "Cycle",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"parent_task": parentId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						SetTaskParent(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Task{}, &pq.Error{Code: "23514", Constraint: "tasks_parent_task_cycle"}),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InvalidParent",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"parent_task": -1},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+				)
+
+				store.EXPECT().
+					SetTaskParent(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"parent_task": parentId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						SetTaskParent(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Task{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestMoveTaskAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	taskId := util.RandomID()
+	afterId := taskId + 1
+
+	url := fmt.Sprintf("/users/%d/lists/%d/tasks/%d/position", user.ID, listId, taskId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.MoveTaskTxParams{ID: taskId, ListID: listId, After: afterId}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						MoveTaskTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.MoveTaskTxResult{Task: db.Task{ID: taskId, ListID: listId, Position: 1536}}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				task := unmarshal[db.Task](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, taskId, task.ID)
+				require.Equal(t, float64(1536), task.Position)
+			},
+		},
+		{
+			name:          "OK(First)",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.MoveTaskTxParams{ID: taskId, ListID: listId}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						MoveTaskTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.MoveTaskTxResult{Task: db.Task{ID: taskId, ListID: listId}}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "AfterItself",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": taskId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+				)
+
+				store.EXPECT().
+					MoveTaskTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "NotSibling",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						MoveTaskTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.MoveTaskTxResult{}, db.ErrNotSibling),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPut,
+			requestUrl:    url,
+			requestBody:   requestBody{"after": afterId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getTasksCall(store, listId, taskId),
+
+					store.EXPECT().
+						MoveTaskTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.MoveTaskTxResult{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
 func TestDeleteTaskAPI(t *testing.T) {
 	user := util.RandomUser()
 	listId := util.RandomID()
@@ -428,8 +878,8 @@ func TestDeleteTaskAPI(t *testing.T) {
 		methodDelete: http.MethodDelete,
 		url:          fmt.Sprintf("/users/%d/lists/%d/tasks/%d", user.ID, listId, taskId),
 		body:         requestBody{},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -442,7 +892,7 @@ func TestDeleteTaskAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -462,7 +912,7 @@ func TestDeleteTaskAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -482,7 +932,7 @@ func TestDeleteTaskAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				gomock.InOrder(
-					getUserCall(store, user),
+					authorizedCalls(store, user),
 					getListsCall(store, user.ID, listId),
 					getTasksCall(store, listId, taskId),
 
@@ -6,35 +6,40 @@ import (
 	"net/http"
 	"time"
 
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-type refreshAccessTokenData struct {
+type refreshTokenData struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 type refreshAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (s *Server) refreshAccessToken(ctx *gin.Context) {
-	var data refreshAccessTokenData
+	var data refreshTokenData
 	if err := ctx.ShouldBindJSON(&data); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
 		return
 	}
 
-	refreshPayload, err := s.pasetoMaker.VerifyToken(data.RefreshToken)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+	refreshPayload, session, ok := s.verifyRefreshToken(ctx, data.RefreshToken)
+	if !ok {
 		return
 	}
 
-	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	user, err := s.store.GetUser(ctx, refreshPayload.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err, "user doesn't exist"))
 			return
 		}
 
@@ -42,39 +47,175 @@ func (s *Server) refreshAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if !checkUserEnabled(ctx, user) {
+		return
+	}
+
+	newSessionID, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return
+	}
+
+	accesToken, accessPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.UserID, newSessionID, refreshPayload.Access, s.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "Cannot create UUID"))
+		return
+	}
+
+	refreshToken, newRefreshPayload, err := s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.UserID, newSessionID, refreshPayload.Access, s.config.RefreshTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return
+	}
+
+	params := db.RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newSessionID,
+			Username:     refreshPayload.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	}
+
+	result, err := s.store.RotateSessionTx(ctx, params)
+	if err != nil {
+		if err == db.ErrSessionReused {
+			s.blockReusedSession(ctx, session)
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	response := refreshAccessTokenResponse{
+		SessionID:             result.Session.ID,
+		AccessToken:           accesToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiredAt,
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s *Server) revokeRefreshToken(ctx *gin.Context) {
+	var data refreshTokenData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	_, session, ok := s.verifyRefreshToken(ctx, data.RefreshToken)
+	if !ok {
+		return
+	}
+
+	if err := s.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// verifyRefreshToken checks refresh token against its session and writes error response if it's not valid
+func (s *Server) verifyRefreshToken(ctx *gin.Context, refreshToken string) (*token.Payload, *db.Session, bool) {
+	refreshPayload, err := s.tokenMaker.VerifyToken(refreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, nil, false
+	}
+
+	session, err := s.store.GetSession(ctx, refreshPayload.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+			return nil, nil, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return nil, nil, false
+	}
+
 	if session.IsBlocked {
 		err := fmt.Errorf("blocked session")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
-		return
+		return nil, nil, false
 	}
 
 	if session.Username != refreshPayload.Username {
 		err := fmt.Errorf("incorrect session user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
-		return
+		return nil, nil, false
 	}
 
-	if session.RefreshToken != data.RefreshToken {
+	if session.RefreshToken != refreshToken {
 		err := fmt.Errorf("mismatched session token")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
-		return
+		return nil, nil, false
+	}
+
+	if session.IsRotated {
+		s.blockReusedSession(ctx, &session)
+		return nil, nil, false
 	}
 
 	if time.Now().After(session.ExpiresAt) {
 		err := fmt.Errorf("expired session")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return nil, nil, false
+	}
+
+	return refreshPayload, &session, true
+}
+
+// blockReusedSession blocks whole family of session which refresh token was presented after rotation
+func (s *Server) blockReusedSession(ctx *gin.Context, session *db.Session) {
+	if err := s.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
 		return
 	}
 
-	accesToken, accessPayload, err := s.pasetoMaker.CreateToken(refreshPayload.Username, s.config.AccessTokenDuration)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "Cannot create UUID"))
+	err := fmt.Errorf("refresh token reuse detected")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err, "all sessions of this login are blocked"))
+}
+
+type publicKeyResponse struct {
+	KeyID   string `json:"kid"`
+	Version string `json:"version"`
+	Purpose string `json:"purpose"`
+	Key     string `json:"key"`
+}
+
+type publicKeysResponse struct {
+	Keys []publicKeyResponse `json:"keys"`
+}
+
+func (s *Server) getPublicKeys(ctx *gin.Context) {
+	publicMaker, ok := s.tokenMaker.(*token.PublicPasetoMaker)
+	if !ok {
+		err := fmt.Errorf("tokens aren't signed with public keys")
+		ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
 		return
 	}
 
-	response := refreshAccessTokenResponse{
-		AccessToken:          accesToken,
-		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	response := publicKeysResponse{
+		Keys: []publicKeyResponse{},
+	}
+
+	for _, key := range publicMaker.PublicKeys() {
+		response.Keys = append(response.Keys, publicKeyResponse{
+			KeyID:   key.ID,
+			Version: "v4",
+			Purpose: "public",
+			Key:     key.Key,
+		})
 	}
 
 	ctx.JSON(http.StatusOK, response)
@@ -0,0 +1,461 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/mail"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type eqRotateSessionTxParamsMatcher struct {
+	session db.Session
+}
+
+func (e eqRotateSessionTxParamsMatcher) Matches(x any) bool {
+	params, ok := x.(db.RotateSessionTxParams)
+	if !ok {
+		return false
+	}
+
+	return params.SessionID == e.session.ID &&
+		params.NewSession.ID != e.session.ID &&
+		params.NewSession.Username == e.session.Username &&
+		params.NewSession.RefreshToken != e.session.RefreshToken
+}
+
+func (e eqRotateSessionTxParamsMatcher) String() string {
+	return fmt.Sprintf("rotates session %v", e.session.ID)
+}
+
+func EqRotateSessionTxParams(session db.Session) gomock.Matcher {
+	return eqRotateSessionTxParamsMatcher{session: session}
+}
+
+type buildSessionStubsFunc func(store *mockdb.MockStore, session db.Session)
+
+type refreshTokenTestCase struct {
+	name          string
+	requestUrl    string
+	buildStubs    buildSessionStubsFunc
+	checkResponse checkResponseFunc
+}
+
+// refreshTokenTestingFunc creates refresh token by test server's maker and sends it within request body
+func refreshTokenTestingFunc(tc *refreshTokenTestCase) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		server := newTestServer(t, store)
+
+		username := util.RandomUsername()
+		refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(username, util.RandomID(), uuid.New(), token.FullAccess(), time.Minute)
+		require.NoError(t, err)
+
+		session := db.Session{
+			ID:           refreshPayload.SessionID,
+			Username:     username,
+			RefreshToken: refreshToken,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+			FamilyID:     uuid.New(),
+		}
+
+		tc.buildStubs(store, session)
+
+		data, err := json.Marshal(requestBody{"refresh_token": refreshToken})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, tc.requestUrl, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		tc.checkResponse(t, recorder)
+	}
+}
+
+func TestRevokeRefreshTokenAPI(t *testing.T) {
+	url := "/tokens/revoke"
+
+	testCases := []*refreshTokenTestCase{
+		{
+			name:       "OK",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+						Times(1).
+						Return(nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:       "SessionDoesNotExist",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:       "AlreadyBlocked",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "MismatchedToken",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = util.RandomString(32)
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "InternalError",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+						Times(1).
+						Return(sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, refreshTokenTestingFunc(tc))
+	}
+}
+
+func TestRefreshAccessTokenAPI(t *testing.T) {
+	url := "/tokens/refresh_access"
+
+	testCases := []*refreshTokenTestCase{
+		{
+			name:       "OK",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(session.Username)).
+						Times(1).
+						Return(db.User{Username: session.Username}, nil),
+
+					store.EXPECT().
+						RotateSessionTx(gomock.Any(), EqRotateSessionTxParams(session)).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+							return db.RotateSessionTxResult{
+								Session: db.Session{
+									ID:           arg.NewSession.ID,
+									Username:     arg.NewSession.Username,
+									RefreshToken: arg.NewSession.RefreshToken,
+									FamilyID:     session.FamilyID,
+									ParentID:     uuid.NullUUID{UUID: session.ID, Valid: true},
+								},
+							}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				gotResult := unmarshal[refreshAccessTokenResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotEmpty(t, gotResult.AccessToken)
+				require.NotEmpty(t, gotResult.RefreshToken)
+				require.NotZero(t, gotResult.SessionID)
+			},
+		},
+		{
+			name:       "ReusedRefreshToken",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsRotated = true
+
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+						Times(1).
+						Return(nil),
+				)
+
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "ConcurrentlyRotated",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(session.Username)).
+						Times(1).
+						Return(db.User{Username: session.Username}, nil),
+
+					store.EXPECT().
+						RotateSessionTx(gomock.Any(), EqRotateSessionTxParams(session)).
+						Times(1).
+						Return(db.RotateSessionTxResult{}, db.ErrSessionReused),
+
+					store.EXPECT().
+						BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+						Times(1).
+						Return(nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "RotateInternalError",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(session.Username)).
+						Times(1).
+						Return(db.User{Username: session.Username}, nil),
+
+					store.EXPECT().
+						RotateSessionTx(gomock.Any(), EqRotateSessionTxParams(session)).
+						Times(1).
+						Return(db.RotateSessionTxResult{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:       "DisabledUser",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				disabledUser := db.User{
+					Username:   session.Username,
+					DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}
+
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(session.Username)).
+						Times(1).
+						Return(disabledUser, nil),
+				)
+
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:       "GetUserInternalError",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				gomock.InOrder(
+					store.EXPECT().
+						GetSession(gomock.Any(), gomock.Eq(session.ID)).
+						Times(1).
+						Return(session, nil),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(session.Username)).
+						Times(1).
+						Return(db.User{}, sql.ErrConnDone),
+				)
+
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:       "BlockedSession",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "IncorrectSessionUser",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.Username = util.RandomUsername()
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:       "InternalError",
+			requestUrl: url,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, refreshTokenTestingFunc(tc))
+	}
+}
+
+func TestGetPublicKeysAPI(t *testing.T) {
+	url := "/.well-known/paseto-keys"
+
+	testCases := []struct {
+		name          string
+		config        util.Config
+		checkResponse checkResponseFunc
+	}{
+		{
+			name: "OK",
+			config: util.Config{
+				TokenMode:      tokenModePublic,
+				TokenKeyID:     "primary",
+				TokenSecretKey: token.RandomKey,
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[publicKeysResponse](t, recorder.Body)
+				require.Len(t, gotResult.Keys, 1)
+				require.Equal(t, "primary", gotResult.Keys[0].KeyID)
+				require.Equal(t, "v4", gotResult.Keys[0].Version)
+				require.Equal(t, "public", gotResult.Keys[0].Purpose)
+				require.NotEmpty(t, gotResult.Keys[0].Key)
+			},
+		},
+		{
+			name: "LocalMode",
+			config: util.Config{
+				TokenMode:         tokenModeLocal,
+				TokenSymmetricKey: token.RandomKey,
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server, err := NewServer(tc.config, mockdb.NewMockStore(ctrl), mail.NewFileMailer(io.Discard, "noreply@example.com"))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer                 = "simpletodo"
+	twoFactorChallengeDuration = 5 * time.Minute
+	// codes which can be tried for one challenge, login has to be started again after that
+	twoFactorChallengeMaxAttempts = 5
+)
+
+var (
+	errTwoFactorNotEnabled      = errors.New("two factor authentication isn't enabled")
+	errCodeAlreadyUsed          = errors.New("code has already been used")
+	errInvalidCode              = errors.New("invalid code")
+	errInvalidLoginChallenge    = errors.New("challenge is used, expired or out of attempts")
+	errTooManyTwoFactorAttempts = errors.New("too many failed two factor attempts, try again later")
+)
+
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired       bool      `json:"two_factor_required"`
+	ChallengeToken          string    `json:"challenge_token"`
+	ChallengeTokenExpiresAt time.Time `json:"challenge_token_expires_at"`
+}
+
+// startTwoFactorChallenge writes short-lived token which login is completed with by POST /users/login/2fa.
+// Challenge is stored by token ID, so the token is accepted only once and for limited number of codes
+func (s *Server) startTwoFactorChallenge(ctx *gin.Context, user db.User, access token.Access) {
+	challengeAccess := token.Access{
+		Scopes:  append(slices.Clone(access.Scopes), token.ScopeLoginChallenge),
+		ListIDs: access.ListIDs,
+	}
+
+	challengeToken, payload, err := s.tokenMaker.CreateToken(user.Username, user.ID, uuid.Nil, challengeAccess, twoFactorChallengeDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
+		return
+	}
+
+	params := db.CreateLoginChallengeParams{
+		ID:        payload.ID,
+		UserID:    user.ID,
+		ExpiresAt: payload.ExpiredAt,
+	}
+
+	if _, err := s.store.CreateLoginChallenge(ctx, params); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, twoFactorChallengeResponse{
+		TwoFactorRequired:       true,
+		ChallengeToken:          challengeToken,
+		ChallengeTokenExpiresAt: payload.ExpiredAt,
+	})
+}
+
+type loginTwoFactorData struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+func (s *Server) loginTwoFactor(ctx *gin.Context) {
+	var data loginTwoFactorData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(data.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return
+	}
+
+	if !payload.HasScope(token.ScopeLoginChallenge) {
+		err := errors.New("token is not login challenge token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return
+	}
+
+	// failed codes are counted with failed passwords of the user, so new challenges don't give more tries
+	attemptKey := lockout.UsernameKey(payload.Username)
+
+	retryAfter, err := s.loginLimiter.Check(ctx, attemptKey)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyTwoFactorAttempts, ""))
+		return
+	}
+
+	// attempt is taken before the code is checked, so concurrent requests can't exceed the limit
+	_, err = s.store.TakeLoginChallengeAttempt(ctx, db.TakeLoginChallengeAttemptParams{
+		ID:          payload.ID,
+		UserID:      payload.UserID,
+		MaxAttempts: twoFactorChallengeMaxAttempts,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge, ""))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	// account could be disabled after the challenge has started
+	if !checkUserEnabled(ctx, user) {
+		return
+	}
+
+	if err := s.checkSecondFactor(ctx, user.ID, data.Code); err != nil {
+		if isSecondFactorError(err) {
+			if err := s.loginLimiter.Fail(ctx, attemptKey); err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+				return
+			}
+
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	// challenge is consumed, concurrent request with valid code loses
+	deleted, err := s.store.DeleteLoginChallenge(ctx, payload.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge, ""))
+		return
+	}
+
+	if err := s.loginLimiter.Reset(ctx, attemptKey); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	access := token.Access{
+		Scopes:  slices.DeleteFunc(payload.Scopes, func(scope string) bool { return scope == token.ScopeLoginChallenge }),
+		ListIDs: payload.ListIDs,
+	}
+
+	s.startSession(ctx, user, access)
+}
+
+// checkSecondFactor accepts TOTP code or unused recovery code, rejected code is reported by one of second factor errors
+func (s *Server) checkSecondFactor(ctx *gin.Context, userID int32, code string) error {
+	totp, err := s.store.GetUserTOTP(ctx, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows || !totp.IsEnabled {
+		return errTwoFactorNotEnabled
+	}
+
+	if step, ok := util.ValidateTOTP(code, totp.Secret, time.Now()); ok {
+		// every code is accepted only once
+		used, err := s.store.UseTOTPStep(ctx, db.UseTOTPStepParams{UserID: userID, LastUsedStep: step})
+		if err != nil {
+			return err
+		}
+
+		if used == 0 {
+			return errCodeAlreadyUsed
+		}
+
+		return nil
+	}
+
+	used, err := s.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: userID, CodeHash: util.HashRecoveryCode(code)})
+	if err != nil {
+		return err
+	}
+
+	if used == 0 {
+		return errInvalidCode
+	}
+
+	return nil
+}
+
+func isSecondFactorError(err error) bool {
+	return err == errTwoFactorNotEnabled || err == errCodeAlreadyUsed || err == errInvalidCode
+}
+
+// verifySecondFactor checks code by checkSecondFactor and writes error response if it's rejected
+func (s *Server) verifySecondFactor(ctx *gin.Context, userID int32, code string) bool {
+	err := s.checkSecondFactor(ctx, userID, code)
+	if err == nil {
+		return true
+	}
+
+	if isSecondFactorError(err) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return false
+	}
+
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+	return false
+}
+
+type enrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+func (s *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create secret"))
+		return
+	}
+
+	params := db.CreateUserTOTPParams{
+		UserID: ctx.MustGet(userIdKey).(int32),
+		Secret: secret,
+	}
+
+	if _, err := s.store.CreateUserTOTP(ctx, params); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "two factor authentication is already enabled"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(totpIssuer, authPayload.Username, secret),
+	})
+}
+
+type totpCodeData struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type confirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (s *Server) confirmTOTP(ctx *gin.Context) {
+	userID := ctx.MustGet(userIdKey).(int32)
+
+	var data totpCodeData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	totp, err := s.store.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "two factor authentication isn't enrolled"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if totp.IsEnabled {
+		err := errors.New("two factor authentication is already enabled")
+		ctx.JSON(http.StatusConflict, errorResponse(err, ""))
+		return
+	}
+
+	step, ok := util.ValidateTOTP(data.Code, totp.Secret, time.Now())
+	if !ok {
+		err := errors.New("invalid code")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err, ""))
+		return
+	}
+
+	recoveryCodes, err := util.GenerateRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create recovery codes"))
+		return
+	}
+
+	params := db.EnableTOTPTxParams{
+		UserID:             userID,
+		LastUsedStep:       step,
+		RecoveryCodeHashes: make([][]byte, len(recoveryCodes)),
+	}
+
+	for i, code := range recoveryCodes {
+		params.RecoveryCodeHashes[i] = util.HashRecoveryCode(code)
+	}
+
+	if _, err := s.store.EnableTOTPTx(ctx, params); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "two factor authentication is already enabled"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{RecoveryCodes: recoveryCodes})
+}
+
+func (s *Server) disableTOTP(ctx *gin.Context) {
+	userID := ctx.MustGet(userIdKey).(int32)
+
+	var data totpCodeData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if !s.verifySecondFactor(ctx, userID, data.Code) {
+		return
+	}
+
+	if err := s.store.DisableTOTPTx(ctx, userID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,729 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	return code
+}
+
+type loginTwoFactorTestCase struct {
+	name          string
+	access        token.Access
+	code          func(t *testing.T) string
+	buildStubs    buildStubsFunc
+	checkResponse checkResponseFunc
+}
+
+// loginTwoFactorTestingFunc creates challenge token by test server's maker and sends it with code
+func loginTwoFactorTestingFunc(tc *loginTwoFactorTestCase, username string, userID int32) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		tc.buildStubs(store)
+
+		server := newTestServer(t, store)
+
+		challengeToken, _, err := server.tokenMaker.CreateToken(username, userID, uuid.Nil, tc.access, time.Minute)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(requestBody{"challenge_token": challengeToken, "code": tc.code(t)})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login/2fa", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		tc.checkResponse(t, recorder)
+	}
+}
+
+func TestLoginTwoFactorAPI(t *testing.T) {
+	user := util.RandomUser()
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	recoveryCode := "0123abcd-4567ef89"
+
+	challengeAccess := token.Access{
+		Scopes: []string{token.ScopeListsRead, token.ScopeLoginChallenge},
+	}
+
+	getUser := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(db.User{ID: user.ID, Username: user.Username}, nil)
+	}
+
+	getTOTP := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(db.UserTotp{UserID: user.ID, Secret: secret, IsEnabled: true}, nil)
+	}
+
+	takeAttempt := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			TakeLoginChallengeAttempt(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.TakeLoginChallengeAttemptParams) (db.LoginChallenge, error) {
+				require.Equal(t, user.ID, arg.UserID)
+				require.Equal(t, int32(twoFactorChallengeMaxAttempts), arg.MaxAttempts)
+
+				return db.LoginChallenge{ID: arg.ID, UserID: arg.UserID, Attempts: 1}, nil
+			})
+	}
+
+	deleteChallenge := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			DeleteLoginChallenge(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(int64(1), nil)
+	}
+
+	testCases := []*loginTwoFactorTestCase{
+		{
+			name:   "OK",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseTOTPStep(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(1), nil),
+
+					deleteChallenge(store),
+
+					store.EXPECT().
+						CreateSession(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+							return db.Session{ID: arg.ID, Username: arg.Username}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[loginUserResponse](t, recorder.Body)
+				require.Equal(t, user.ID, gotResult.ID)
+				require.NotEmpty(t, gotResult.AccessToken)
+				require.NotEmpty(t, gotResult.RefreshToken)
+			},
+		},
+		{
+			name:   "DisabledUser",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				disabledUser := db.User{
+					ID:         user.ID,
+					Username:   user.Username,
+					DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}
+
+				gomock.InOrder(
+					takeAttempt(store),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(user.Username)).
+						Times(1).
+						Return(disabledUser, nil),
+				)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:   "RecoveryCode",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return recoveryCode },
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.UseRecoveryCodeParams{
+					UserID:   user.ID,
+					CodeHash: util.HashRecoveryCode(recoveryCode),
+				}
+
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseRecoveryCode(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(int64(1), nil),
+
+					deleteChallenge(store),
+
+					store.EXPECT().
+						CreateSession(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.Session{}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:   "ReusedCode",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseTOTPStep(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(0), nil),
+				)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:   "InvalidCode",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return "invalid" },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseRecoveryCode(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(0), nil),
+				)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:   "ChallengeUsed",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TakeLoginChallengeAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginChallenge{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:   "ChallengeConsumedConcurrently",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseTOTPStep(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(1), nil),
+
+					store.EXPECT().
+						DeleteLoginChallenge(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(0), nil),
+				)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:   "NotChallengeToken",
+			access: token.FullAccess(),
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TakeLoginChallengeAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:   "TwoFactorNotEnabled",
+			access: challengeAccess,
+			code:   func(t *testing.T) string { return currentTOTPCode(t, secret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					takeAttempt(store),
+					getUser(store),
+
+					store.EXPECT().
+						GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+						Times(1).
+						Return(db.UserTotp{}, sql.ErrNoRows),
+				)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, loginTwoFactorTestingFunc(tc, user.Username, user.ID))
+	}
+}
+
+func TestLoginTwoFactorLockout(t *testing.T) {
+	user := util.RandomUser()
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	challengeAccess := token.Access{
+		Scopes: []string{token.ScopeLoginChallenge},
+	}
+
+	// buildStore keeps attempts of challenges like TakeLoginChallengeAttempt does, valid code is never checked
+	buildStore := func(t *testing.T) *mockdb.MockStore {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		attempts := map[uuid.UUID]int32{}
+
+		store.EXPECT().
+			TakeLoginChallengeAttempt(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			DoAndReturn(func(_ context.Context, arg db.TakeLoginChallengeAttemptParams) (db.LoginChallenge, error) {
+				if attempts[arg.ID] >= arg.MaxAttempts {
+					return db.LoginChallenge{}, sql.ErrNoRows
+				}
+
+				attempts[arg.ID]++
+				return db.LoginChallenge{ID: arg.ID, UserID: arg.UserID, Attempts: attempts[arg.ID]}, nil
+			})
+
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			AnyTimes().
+			Return(db.User{ID: user.ID, Username: user.Username}, nil)
+
+		store.EXPECT().
+			GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+			AnyTimes().
+			Return(db.UserTotp{UserID: user.ID, Secret: secret, IsEnabled: true}, nil)
+
+		store.EXPECT().
+			UseRecoveryCode(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(int64(0), nil)
+
+		store.EXPECT().
+			UseTOTPStep(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		return store
+	}
+
+	sendCode := func(t *testing.T, server *Server, challengeToken string, code string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(requestBody{"challenge_token": challengeToken, "code": code})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login/2fa", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	newChallenge := func(t *testing.T, server *Server) string {
+		challengeToken, _, err := server.tokenMaker.CreateToken(user.Username, user.ID, uuid.Nil, challengeAccess, time.Minute)
+		require.NoError(t, err)
+
+		return challengeToken
+	}
+
+	t.Run("UserLockedOut", func(t *testing.T) {
+		server := newTestServer(t, buildStore(t))
+
+		// failures are counted for the user, so new challenges don't give more tries
+		for i := int32(0); i < lockout.DefaultPolicy.FreeAttempts; i++ {
+			require.Equal(t, http.StatusUnauthorized, sendCode(t, server, newChallenge(t, server), "invalid").Code)
+		}
+
+		recorder := sendCode(t, server, newChallenge(t, server), currentTOTPCode(t, secret))
+		require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("ChallengeOutOfAttempts", func(t *testing.T) {
+		config := newTestConfig()
+		config.LoginFreeAttempts = 2 * twoFactorChallengeMaxAttempts
+		config.LoginMaxAttempts = 4 * twoFactorChallengeMaxAttempts
+
+		server := newTestServerWithConfig(t, buildStore(t), config)
+		challengeToken := newChallenge(t, server)
+
+		for i := 0; i < twoFactorChallengeMaxAttempts; i++ {
+			require.Equal(t, http.StatusUnauthorized, sendCode(t, server, challengeToken, "invalid").Code)
+		}
+
+		// even valid code isn't checked after the last attempt
+		recorder := sendCode(t, server, challengeToken, currentTOTPCode(t, secret))
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Contains(t, recorder.Body.String(), errInvalidLoginChallenge.Error())
+	})
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user := util.RandomUser()
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        fmt.Sprintf("/users/%d/2fa", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserTOTPParams) (db.UserTotp, error) {
+						return db.UserTotp{UserID: arg.UserID, Secret: arg.Secret}, nil
+					}).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[enrollTOTPResponse](t, recorder.Body)
+				require.NotEmpty(t, gotResult.Secret)
+				require.Contains(t, gotResult.ProvisioningURI, gotResult.Secret)
+			},
+		},
+		{
+			name:          "AlreadyEnabled",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTOTP(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user := util.RandomUser()
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	code := currentTOTPCode(t, secret)
+
+	defaultSettings := struct {
+		methodPost string
+		url        string
+		body       requestBody
+		setupAuth  setupAuthFunc
+	}{
+		methodPost: http.MethodPost,
+		url:        fmt.Sprintf("/users/%d/2fa/confirm", user.ID),
+		body:       requestBody{"code": code},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+
+					store.EXPECT().
+						GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+						Times(1).
+						Return(db.UserTotp{UserID: user.ID, Secret: secret}, nil),
+
+					store.EXPECT().
+						EnableTOTPTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.EnableTOTPTxParams) (db.EnableTOTPTxResult, error) {
+							require.Equal(t, user.ID, arg.UserID)
+							require.Len(t, arg.RecoveryCodeHashes, util.RecoveryCodesCount)
+
+							return db.EnableTOTPTxResult{}, nil
+						}),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				gotResult := unmarshal[confirmTOTPResponse](t, recorder.Body)
+				require.Len(t, gotResult.RecoveryCodes, util.RecoveryCodesCount)
+			},
+		},
+		{
+			name:          "InvalidCode",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"code": "invalid"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+
+					store.EXPECT().
+						GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+						Times(1).
+						Return(db.UserTotp{UserID: user.ID, Secret: secret}, nil),
+				)
+
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:          "NotEnrolled",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "AlreadyEnabled",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{UserID: user.ID, Secret: secret, IsEnabled: true}, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDisableTOTPAPI(t *testing.T) {
+	user := util.RandomUser()
+	secret, err := util.GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	defaultSettings := struct {
+		methodDelete string
+		url          string
+		setupAuth    setupAuthFunc
+	}{
+		methodDelete: http.MethodDelete,
+		url:          fmt.Sprintf("/users/%d/2fa", user.ID),
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+		},
+	}
+
+	getTOTP := func(store *mockdb.MockStore) *gomock.Call {
+		return store.EXPECT().
+			GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(db.UserTotp{UserID: user.ID, Secret: secret, IsEnabled: true}, nil)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"code": currentTOTPCode(t, secret)},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseTOTPStep(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(1), nil),
+
+					store.EXPECT().
+						DisableTOTPTx(gomock.Any(), gomock.Eq(user.ID)).
+						Times(1).
+						Return(nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvalidCode",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"code": "invalid"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getTOTP(store),
+
+					store.EXPECT().
+						UseRecoveryCode(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(0), nil),
+				)
+
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
+		},
+		{
+			name:          "NoCode",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
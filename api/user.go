@@ -2,11 +2,16 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,7 +19,8 @@ import (
 
 type createUserData struct {
 	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,printascii,min=8"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email"`
 }
 
 func (s *Server) createUser(ctx *gin.Context) {
@@ -24,22 +30,44 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	if err := util.ValidateUsername(data.Username); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	if err := s.passwordPolicy.Validate(data.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
 	hash, err := util.HashPassword(data.Password)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden, errorResponse(err, "maximum length of password is 72 bytes"))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	verificationToken, err := util.GenerateMailToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create token"))
 		return
 	}
 
 	params := db.CreateUserTxParams{
 		Username: data.Username,
 		Hash:     hash,
+		Email: sql.NullString{
+			String: data.Email,
+			Valid:  data.Email != "",
+		},
+		VerificationTokenHash: util.HashMailToken(verificationToken),
+		VerificationExpiresAt: time.Now().Add(s.config.EmailVerificationTokenDuration),
 	}
 
 	createUserResult, err := s.store.CreateUserTx(ctx, params)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusForbidden, errorResponse(err, "user "+data.Username+" already exist"))
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "username or email is used by other user"))
 			return
 		}
 
@@ -47,13 +75,42 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	if params.Email.Valid {
+		// user is created anyway, verification can be resent
+		if err := s.sendEmailVerification(data.Email, data.Username, verificationToken); err != nil {
+			ctx.Error(fmt.Errorf("cannot send email verification: %w", err))
+		}
+	}
+
 	ctx.JSON(http.StatusCreated, gin.H{"user_id": createUserResult.User.ID})
 }
 
+type deleteUserQuery struct {
+	Export bool `form:"export"`
+}
+
+type userExportResponse struct {
+	User  userProfileResponse `json:"user"`
+	Lists []db.ExportedList   `json:"lists"`
+}
+
+// deleteUser deletes user with all its data and optionally returns export of the data
 func (s *Server) deleteUser(ctx *gin.Context) {
 	userId := ctx.MustGet(userIdKey).(int32)
 
-	if _, err := s.store.DeleteUser(ctx, userId); err != nil {
+	var query deleteUserQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.DeleteUserTxParams{
+		UserID: userId,
+		Export: query.Export,
+	}
+
+	result, err := s.store.DeleteUserTx(ctx, params)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusForbidden, errorResponse(err, "user doesn't exist"))
 			return
@@ -63,7 +120,15 @@ func (s *Server) deleteUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusNoContent, nil)
+	if !query.Export {
+		ctx.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userExportResponse{
+		User:  newUserProfileResponse(result.User),
+		Lists: result.Lists,
+	})
 }
 
 type rehashUserData struct {
@@ -71,8 +136,10 @@ type rehashUserData struct {
 	NewPassword string `json:"new_password" binding:"required"`
 }
 
+// rehashUser changes password of user and blocks all its sessions except the current one
 func (s *Server) rehashUser(ctx *gin.Context) {
 	userId := ctx.MustGet(userIdKey).(int32)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
 	var data rehashUserData
 
@@ -81,27 +148,43 @@ func (s *Server) rehashUser(ctx *gin.Context) {
 		return
 	}
 
-	oldHash, err := util.HashPassword(data.OldPassword)
+	if err := s.passwordPolicy.Validate(data.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden, errorResponse(err, "maximum length of password is 72 bytes"))
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err := util.CheckPassword(data.OldPassword, user.Hash); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err, "wrong actual password"))
 		return
 	}
 
 	newHash, err := util.HashPassword(data.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden, errorResponse(err, "maximum length of password is 72 bytes"))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
 		return
 	}
 
-	params := db.RehashUserParams{
-		ID:      userId,
-		OldHash: oldHash,
-		NewHash: newHash,
+	params := db.ChangePasswordTxParams{
+		ID:               userId,
+		OldHash:          user.Hash,
+		NewHash:          newHash,
+		CurrentSessionID: authPayload.SessionID,
 	}
 
-	if _, err := s.store.RehashUser(ctx, params); err != nil {
+	if _, err := s.store.ChangePasswordTx(ctx, params); err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusForbidden, errorResponse(err, "wrong user id or actual password"))
+			ctx.JSON(http.StatusConflict, errorResponse(err, "password has been changed concurrently"))
 			return
 		}
 
@@ -112,6 +195,15 @@ func (s *Server) rehashUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
+var (
+	errInvalidCredentials   = errors.New("invalid username or password")
+	errTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	errAccountDisabled      = errors.New("account is disabled")
+)
+
+// dummyPasswordHash is checked against when user doesn't exist so response time doesn't reveal it
+var dummyPasswordHash, _ = util.HashPassword("dummy password")
+
 type loginUserResponse struct {
 	SessionsID            uuid.UUID `json:"session_id"`
 	AccessToken           string    `json:"access_token"`
@@ -122,8 +214,10 @@ type loginUserResponse struct {
 }
 
 type loginUserData struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string   `json:"username" binding:"required"`
+	Password string   `json:"password" binding:"required"`
+	Scopes   []string `json:"scopes"`
+	ListIDs  []int32  `json:"list_ids"`
 }
 
 func (s *Server) loginUser(ctx *gin.Context) {
@@ -133,57 +227,172 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	attemptKeys := []string{lockout.UsernameKey(data.Username), lockout.IPKey(ctx.ClientIP())}
+
+	// attempt is counted as failed until password is checked, so parallel guesses can't skip the delay
+	attempt, retryAfter, err := s.loginLimiter.Take(ctx, attemptKeys...)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts, ""))
+		return
+	}
+
 	user, err := s.store.GetUser(ctx, data.Username)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err == sql.ErrNoRows {
+		// spend the same time as for existing user
+		util.CheckPassword(data.Password, dummyPasswordHash)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials, ""))
+		return
+	}
+
+	if err := util.CheckPassword(data.Password, user.Hash); err != nil {
+		// the same response as for unknown user
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials, ""))
+		return
+	}
+
+	if err := s.loginLimiter.Release(ctx, attempt); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if util.NeedsRehash(user.Hash) {
+		s.upgradePasswordHash(ctx, &user, data.Password)
+	}
+
+	access, err := token.NewAccess(data.Scopes, data.ListIDs)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err, fmt.Sprintf("there is no user with username %s", data.Username)))
-			return
-		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	s.completeLogin(ctx, user, access)
+}
+
+// completeLogin starts session of authenticated user or two factor challenge if user has it enabled
+func (s *Server) completeLogin(ctx *gin.Context, user db.User, access token.Access) {
+	if !checkUserEnabled(ctx, user) {
+		return
+	}
+
+	totp, err := s.store.GetUserTOTP(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if err == nil && totp.IsEnabled {
+		s.startTwoFactorChallenge(ctx, user, access)
+		return
+	}
 
+	// with two factor authentication failures are reset only after the code, so password can't reset its attempts.
+	// Failures of client IP are kept, otherwise one known account would let to guess passwords of others from the same IP
+	if err := s.loginLimiter.Reset(ctx, lockout.UsernameKey(user.Username)); err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
 		return
 	}
 
-	err = util.CheckPassword(data.Password, user.Hash)
+	s.startSession(ctx, user, access)
+}
+
+// checkUserEnabled writes error response if account of user is disabled by admin
+func checkUserEnabled(ctx *gin.Context, user db.User) bool {
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountDisabled, ""))
+		return false
+	}
+
+	return true
+}
+
+// upgradePasswordHash replaces legacy hash of user after its password has been checked.
+// Failure doesn't break login, upgrade is retried on the next one
+func (s *Server) upgradePasswordHash(ctx *gin.Context, user *db.User, password string) {
+	newHash, err := util.HashPassword(password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err, "wrong password"))
+		ctx.Error(fmt.Errorf("cannot upgrade password hash: %w", err))
 		return
 	}
 
-	accesToken, accessPayload, err := s.pasetoMaker.CreateToken(user.Username, s.config.AccessTokenDuration)
+	params := db.RehashUserParams{
+		ID:      user.ID,
+		NewHash: newHash,
+		OldHash: user.Hash,
+	}
+
+	upgradedUser, err := s.store.RehashUser(ctx, params)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		ctx.Error(fmt.Errorf("cannot upgrade password hash: %w", err))
+		return
+	}
+
+	*user = upgradedUser
+}
+
+// startSession creates a new session of user and writes its tokens as login response
+func (s *Server) startSession(ctx *gin.Context, user db.User, access token.Access) {
+	response, ok := s.createSession(ctx, user, access)
+	if !ok {
 		return
 	}
 
-	refreshToken, refreshPayload, err := s.pasetoMaker.CreateToken(user.Username, s.config.RefreshTokenDuration)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// createSession creates a new session of user and returns its tokens
+func (s *Server) createSession(ctx *gin.Context, user db.User, access token.Access) (loginUserResponse, bool) {
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return loginUserResponse{}, false
+	}
+
+	accesToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.ID, sessionID, access, s.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return loginUserResponse{}, false
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.ID, sessionID, access, s.config.RefreshTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return loginUserResponse{}, false
 	}
 
 	params := db.CreateSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		Username:     user.Username,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		FamilyID:     sessionID,
 	}
 
 	session, err := s.store.CreateSession(ctx, params)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create session"))
+		return loginUserResponse{}, false
 	}
 
-	response := loginUserResponse{
+	return loginUserResponse{
 		SessionsID:            session.ID,
 		AccessToken:           accesToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
 		ID:                    user.ID,
-	}
-
-	ctx.JSON(http.StatusOK, response)
+	}, true
 }
@@ -1,7 +1,11 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,11 +15,14 @@ import (
 
 	mockdb "github.com/PYTNAG/simpletodo/db/mock"
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
 	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type eqCreateUserTxParamsMatcher struct {
@@ -34,7 +41,14 @@ func (e eqCreateUserTxParamsMatcher) Matches(x any) bool {
 		return false
 	}
 
+	// verification token is random
+	if len(params.VerificationTokenHash) != sha256.Size || time.Until(params.VerificationExpiresAt) <= 0 {
+		return false
+	}
+
 	e.params.Hash = params.Hash
+	e.params.VerificationTokenHash = params.VerificationTokenHash
+	e.params.VerificationExpiresAt = params.VerificationExpiresAt
 	return reflect.DeepEqual(e.params, params)
 }
 
@@ -49,44 +63,11 @@ func EqCreateUserTxParams(params db.CreateUserTxParams, password string) gomock.
 	}
 }
 
-type eqRehashUserParamsMatcher struct {
-	params      db.RehashUserParams
-	oldPassword string
-	newPassword string
-}
-
-func (e eqRehashUserParamsMatcher) Matches(x any) bool {
-	params, ok := x.(db.RehashUserParams)
-	if !ok {
-		return false
-	}
-
-	errOldHash := util.CheckPassword(e.oldPassword, params.OldHash)
-	errNewHash := util.CheckPassword(e.newPassword, params.NewHash)
-	if errOldHash != nil || errNewHash != nil {
-		return false
-	}
-
-	e.params.OldHash = params.OldHash
-	e.params.NewHash = params.NewHash
-	return reflect.DeepEqual(e.params, params)
-}
-
-func (e eqRehashUserParamsMatcher) String() string {
-	return fmt.Sprintf("matches params %v and passwords {old: %v ; new: %v}", e.params, e.oldPassword, e.newPassword)
-}
-
-func EqRehashUserParams(params db.RehashUserParams, oldPassword, newPassword string) gomock.Matcher {
-	return eqRehashUserParamsMatcher{
-		params:      params,
-		oldPassword: oldPassword,
-		newPassword: newPassword,
-	}
-}
-
 func TestCreateUserAPI(t *testing.T) {
 	user := util.RandomUser()
 
+	email := util.RandomEmail()
+
 	defaultSettings := struct {
 		methodPost         string
 		url                string
@@ -100,7 +81,7 @@ func TestCreateUserAPI(t *testing.T) {
 			"username": user.Username,
 			"password": user.Password,
 		},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 		createUserTxParams: db.CreateUserTxParams{
 			Username: user.Username,
 			Hash:     user.Hash,
@@ -140,7 +121,50 @@ func TestCreateUserAPI(t *testing.T) {
 			},
 		},
 		{
-			name:          "UserAlreadyExist",
+			name:          "WithEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": user.Username, "password": user.Password, "email": email},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := defaultSettings.createUserTxParams
+				params.Email = sql.NullString{String: email, Valid: true}
+
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), EqCreateUserTxParams(params, user.Password)).
+					Times(1).
+					Return(db.CreateUserTxResult{User: db.User{ID: user.ID}}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusCreated),
+		},
+		{
+			name:          "InvalidEmail",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": user.Username, "password": user.Password, "email": "not an email"},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InvalidUsername",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   requestBody{"username": "john doe", "password": user.Password},
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "UserAlreadyExists",
 			requestMethod: defaultSettings.methodPost,
 			requestUrl:    defaultSettings.url,
 			requestBody:   defaultSettings.body,
@@ -149,9 +173,9 @@ func TestCreateUserAPI(t *testing.T) {
 				store.EXPECT().
 					CreateUserTx(gomock.Any(), EqCreateUserTxParams(defaultSettings.createUserTxParams, user.Password)).
 					Times(1).
-					Return(db.CreateUserTxResult{}, sql.ErrNoRows)
+					Return(db.CreateUserTxResult{}, &pq.Error{Code: "23505"})
 			},
-			checkResponse: requierResponseCode(http.StatusForbidden),
+			checkResponse: requierResponseCode(http.StatusConflict),
 		},
 		{
 			name:          "InternalError",
@@ -171,14 +195,27 @@ func TestCreateUserAPI(t *testing.T) {
 			name:          "Too long password",
 			requestMethod: defaultSettings.methodPost,
 			requestUrl:    defaultSettings.url,
-			requestBody:   defaultSettings.body.replace("password", util.RandomString(73)),
+			requestBody:   defaultSettings.body.replace("password", util.RandomString(util.DefaultPasswordMaxLength+1)),
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
-			checkResponse: requierResponseCode(http.StatusForbidden),
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "ShortPassword",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body.replace("password", util.RandomString(util.DefaultPasswordMinLength-1)),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
 		},
 		{
 			name:          "Invalid Request Data",
@@ -212,8 +249,8 @@ func TestDeleteUserAPI(t *testing.T) {
 		methodDelete: http.MethodDelete,
 		url:          fmt.Sprintf("/users/%d", user.ID),
 		body:         requestBody{},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
@@ -225,18 +262,85 @@ func TestDeleteUserAPI(t *testing.T) {
 			requestBody:   defaultSettings.body,
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
-				deleteResult := db.DeleteUserRow{
-					ID:       user.ID,
-					Username: user.Username,
+				deleteResult := db.DeleteUserTxResult{
+					User: db.User{ID: user.ID, Username: user.Username},
 				}
 
 				store.EXPECT().
-					DeleteUser(gomock.Any(), gomock.Eq(user.ID)).
+					DeleteUserTx(gomock.Any(), gomock.Eq(db.DeleteUserTxParams{UserID: user.ID})).
 					Times(1).
 					Return(deleteResult, nil).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
-			checkResponse: requierResponseCode(http.StatusNoContent),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+				require.Empty(t, recorder.Body.String())
+			},
+		},
+		{
+			name:          "Export",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url + "?export=true",
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				deleteResult := db.DeleteUserTxResult{
+					User: db.User{ID: user.ID, Username: user.Username, Hash: user.Hash},
+					Lists: []db.ExportedList{
+						{
+							GetAuthoredListsRow: db.GetAuthoredListsRow{ID: util.RandomID(), Header: db.DefaultLIstHeader},
+							Tasks:               []db.Task{{ID: util.RandomID(), Task: util.RandomString(10)}},
+						},
+					},
+				}
+
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(db.DeleteUserTxParams{UserID: user.ID, Export: true})).
+					Times(1).
+					Return(deleteResult, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hash")
+
+				response := unmarshal[userExportResponse](t, recorder.Body)
+				require.Equal(t, user.ID, response.User.ID)
+				require.Equal(t, user.Username, response.User.Username)
+				require.Len(t, response.Lists, 1)
+				require.Len(t, response.Lists[0].Tasks, 1)
+			},
+		},
+		{
+			name:          "InvalidExport",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url + "?export=maybe",
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "MissingScope",
+			requestMethod: defaultSettings.methodDelete,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				access := token.Access{Scopes: []string{token.ScopeListsRead, token.ScopeTasksRead}}
+				addScopedAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, access, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
 		},
 		{
 			name:          "WrongUser",
@@ -246,10 +350,10 @@ func TestDeleteUserAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteUser(gomock.Any(), gomock.Any()).
+					DeleteUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.DeleteUserRow{}, sql.ErrNoRows).
-					After(getUserCall(store, user))
+					Return(db.DeleteUserTxResult{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusForbidden),
 		},
@@ -261,10 +365,10 @@ func TestDeleteUserAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteUser(gomock.Any(), gomock.Any()).
+					DeleteUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.DeleteUserRow{}, sql.ErrConnDone).
-					After(getUserCall(store, user))
+					Return(db.DeleteUserTxResult{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
 		},
@@ -278,7 +382,6 @@ func TestDeleteUserAPI(t *testing.T) {
 func TestRehashUserAPI(t *testing.T) {
 	user := util.RandomUser()
 	newPass := util.RandomPassword()
-	newHash, _ := util.HashPassword(newPass)
 
 	defaultSettings := struct {
 		methodPut string
@@ -292,11 +395,16 @@ func TestRehashUserAPI(t *testing.T) {
 			"old_password": user.Password,
 			"new_password": newPass,
 		},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {
-			addAuthorization(t, request, pasetoMaker, authorizationTypeBearer, user.Username, time.Minute)
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
 		},
 	}
 
+	// handler loads user after the auth middlewares
+	authorizedUserCalls := func(store *mockdb.MockStore) *gomock.Call {
+		return getUserCall(store, user).After(authorizedCalls(store, user))
+	}
+
 	testCases := []*apiTestCase{
 		{
 			name:          "OK",
@@ -305,23 +413,18 @@ func TestRehashUserAPI(t *testing.T) {
 			requestBody:   defaultSettings.body,
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
-				rehashUserParams := db.RehashUserParams{
-					ID:      user.ID,
-					OldHash: user.Hash,
-					NewHash: newHash,
-				}
-
-				rehashUserResult := db.User{
-					ID:       user.ID,
-					Username: user.Username,
-					Hash:     newHash,
-				}
-
 				store.EXPECT().
-					RehashUser(gomock.Any(), EqRehashUserParams(rehashUserParams, user.Password, newPass)).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(rehashUserResult, nil).
-					After(getUserCall(store, user))
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.ChangePasswordTxResult, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.Equal(t, user.Hash, arg.OldHash)
+						require.NoError(t, util.CheckPassword(newPass, arg.NewHash))
+						require.NotEqual(t, uuid.Nil, arg.CurrentSessionID)
+
+						return db.ChangePasswordTxResult{User: db.User{ID: user.ID, Username: user.Username, Hash: arg.NewHash}}, nil
+					}).
+					After(authorizedUserCalls(store))
 			},
 			checkResponse: requierResponseCode(http.StatusNoContent),
 		},
@@ -333,60 +436,87 @@ func TestRehashUserAPI(t *testing.T) {
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					RehashUser(gomock.Any(), gomock.Any()).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(0).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
 			checkResponse: requierResponseCode(http.StatusBadRequest),
 		},
 		{
-			name:          "TooLongOldPassword",
+			name:          "ShortNewPassword",
 			requestMethod: defaultSettings.methodPut,
 			requestUrl:    defaultSettings.url,
-			requestBody:   defaultSettings.body.replace("old_password", util.RandomString(73)),
+			requestBody:   defaultSettings.body.replace("new_password", util.RandomString(7)),
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					RehashUser(gomock.Any(), gomock.Any()).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(0).
-					After(getUserCall(store, user))
+					After(authorizedCalls(store, user))
 			},
-			checkResponse: requierResponseCode(http.StatusForbidden),
+			checkResponse: requierResponseCode(http.StatusBadRequest),
 		},
 		{
 			name:          "TooLongNewPassword",
 			requestMethod: defaultSettings.methodPut,
 			requestUrl:    defaultSettings.url,
-			requestBody:   defaultSettings.body.replace("new_password", util.RandomString(73)),
+			requestBody:   defaultSettings.body.replace("new_password", util.RandomString(util.DefaultPasswordMaxLength+1)),
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					RehashUser(gomock.Any(), gomock.Any()).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "WrongOldPassword",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body.replace("old_password", util.RandomPassword()),
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(0).
-					After(getUserCall(store, user))
+					After(authorizedUserCalls(store))
 			},
 			checkResponse: requierResponseCode(http.StatusForbidden),
 		},
 		{
-			name:          "WrongUserIdOrPassword",
+			name:          "GetUserInternalError",
 			requestMethod: defaultSettings.methodPut,
 			requestUrl:    defaultSettings.url,
 			requestBody:   defaultSettings.body,
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
-				rehashUserParams := db.RehashUserParams{
-					ID:      user.ID,
-					OldHash: user.Hash,
-					NewHash: newHash,
-				}
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
 
 				store.EXPECT().
-					RehashUser(gomock.Any(), EqRehashUserParams(rehashUserParams, user.Password, newPass)).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "ConcurrentChange",
+			requestMethod: defaultSettings.methodPut,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, sql.ErrNoRows).
-					After(getUserCall(store, user))
+					Return(db.ChangePasswordTxResult{}, sql.ErrNoRows).
+					After(authorizedUserCalls(store))
 			},
-			checkResponse: requierResponseCode(http.StatusForbidden),
+			checkResponse: requierResponseCode(http.StatusConflict),
 		},
 		{
 			name:          "InternalError",
@@ -395,17 +525,11 @@ func TestRehashUserAPI(t *testing.T) {
 			requestBody:   defaultSettings.body,
 			setupAuth:     defaultSettings.setupAuth,
 			buildStubs: func(store *mockdb.MockStore) {
-				rehashUserParams := db.RehashUserParams{
-					ID:      user.ID,
-					OldHash: user.Hash,
-					NewHash: newHash,
-				}
-
 				store.EXPECT().
-					RehashUser(gomock.Any(), EqRehashUserParams(rehashUserParams, user.Password, newPass)).
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, sql.ErrConnDone).
-					After(getUserCall(store, user))
+					Return(db.ChangePasswordTxResult{}, sql.ErrConnDone).
+					After(authorizedUserCalls(store))
 			},
 			checkResponse: requierResponseCode(http.StatusInternalServerError),
 		},
@@ -434,7 +558,7 @@ func TestLoginUserAPI(t *testing.T) {
 			"username": user.Username,
 			"password": user.Password,
 		},
-		setupAuth: func(t *testing.T, request *http.Request, pasetoMaker *token.PasetoMaker) {},
+		setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 	}
 
 	testCases := []*apiTestCase{
@@ -450,6 +574,11 @@ func TestLoginUserAPI(t *testing.T) {
 					Times(1).
 					Return(session, nil)
 
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrNoRows)
+
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
@@ -467,6 +596,200 @@ func TestLoginUserAPI(t *testing.T) {
 				require.NotEmpty(t, gotResult.AccessToken)
 			},
 		},
+		{
+			name:          "DisabledUser",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{
+						ID:         user.ID,
+						Username:   user.Username,
+						Hash:       user.Hash,
+						DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+					}, nil)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "LegacyHashUpgraded",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				legacyHash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+				require.NoError(t, err)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username, Hash: legacyHash}, nil)
+
+				store.EXPECT().
+					RehashUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RehashUserParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.Equal(t, legacyHash, arg.OldHash)
+						require.NoError(t, util.CheckPassword(user.Password, arg.NewHash))
+						require.False(t, util.NeedsRehash(arg.NewHash))
+
+						return db.User{ID: user.ID, Username: user.Username, Hash: arg.NewHash}, nil
+					})
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "LegacyHashUpgradeFailure",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				legacyHash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+				require.NoError(t, err)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{ID: user.ID, Username: user.Username, Hash: legacyHash}, nil)
+
+				store.EXPECT().
+					RehashUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "TwoFactorRequired",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{UserID: user.ID, IsEnabled: true}, nil)
+
+				store.EXPECT().
+					CreateLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.NotEqual(t, uuid.Nil, arg.ID)
+
+						return db.LoginChallenge{ID: arg.ID, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+					})
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{
+						ID:       user.ID,
+						Username: user.Username,
+						Hash:     user.Hash,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				gotResult := unmarshal[twoFactorChallengeResponse](t, recorder.Body)
+
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, gotResult.TwoFactorRequired)
+				require.NotEmpty(t, gotResult.ChallengeToken)
+			},
+		},
+		{
+			name:          "TwoFactorInternalError",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody:   defaultSettings.body,
+			setupAuth:     defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetUserTOTP(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTotp{}, sql.ErrConnDone)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{
+						ID:       user.ID,
+						Username: user.Username,
+						Hash:     user.Hash,
+					}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+		{
+			name:          "UnknownScope",
+			requestMethod: defaultSettings.methodPost,
+			requestUrl:    defaultSettings.url,
+			requestBody: requestBody{
+				"username": user.Username,
+				"password": user.Password,
+				"scopes":   []string{"unknown"},
+			},
+			setupAuth: defaultSettings.setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{
+						ID:       user.ID,
+						Username: user.Username,
+						Hash:     user.Hash,
+					}, nil)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
 		{
 			name:          "WrongBody",
 			requestMethod: defaultSettings.methodPost,
@@ -492,7 +815,7 @@ func TestLoginUserAPI(t *testing.T) {
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
 			},
-			checkResponse: requierResponseCode(http.StatusNotFound),
+			checkResponse: requierResponseCode(http.StatusUnauthorized),
 		},
 		{
 			name:          "InternalError",
@@ -532,3 +855,155 @@ func TestLoginUserAPI(t *testing.T) {
 		t.Run(tc.name, apiTestingFunc(tc))
 	}
 }
+
+func TestLoginLockout(t *testing.T) {
+	user := util.RandomUser()
+	unknownUser := util.RandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		AnyTimes().
+		Return(db.User{ID: user.ID, Username: user.Username, Hash: user.Hash}, nil)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(unknownUser.Username)).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+
+	server := newTestServer(t, store)
+
+	login := func(username, password string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(requestBody{"username": username, "password": password})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	// response doesn't reveal whether user exists
+	unknownUserResponse := login(unknownUser.Username, unknownUser.Password)
+	wrongPasswordResponse := login(user.Username, util.RandomPassword())
+
+	require.Equal(t, http.StatusUnauthorized, unknownUserResponse.Code)
+	require.Equal(t, http.StatusUnauthorized, wrongPasswordResponse.Code)
+	require.Equal(t, unknownUserResponse.Body.String(), wrongPasswordResponse.Body.String())
+
+	// failures from client IP are counted across usernames
+	for i := int32(2); i < lockout.DefaultPolicy.FreeAttempts; i++ {
+		require.Equal(t, http.StatusUnauthorized, login(user.Username, util.RandomPassword()).Code)
+	}
+
+	// even correct password is rejected until backoff is over
+	recorder := login(user.Username, user.Password)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+}
+
+func TestLoginParallelGuesses(t *testing.T) {
+	user := util.RandomUser()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		AnyTimes().
+		Return(db.User{ID: user.ID, Username: user.Username, Hash: user.Hash}, nil)
+
+	server := newTestServer(t, store)
+
+	data, err := json.Marshal(requestBody{"username": user.Username, "password": util.RandomPassword()})
+	require.NoError(t, err)
+
+	// guesses sent at once are counted before their passwords are checked, so only free ones get through
+	n := 2 * int(lockout.DefaultPolicy.FreeAttempts)
+	codes := make(chan int)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			request := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			codes <- recorder.Code
+		}()
+	}
+
+	counts := make(map[int]int)
+	for i := 0; i < n; i++ {
+		counts[<-codes]++
+	}
+
+	require.Equal(t, int(lockout.DefaultPolicy.FreeAttempts), counts[http.StatusUnauthorized])
+	require.Equal(t, n-int(lockout.DefaultPolicy.FreeAttempts), counts[http.StatusTooManyRequests])
+}
+
+func TestLoginClientIP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies string
+		lockedOut      bool
+	}{
+		{
+			name:      "ForwardedForNotTrusted",
+			lockedOut: true,
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: "10.0.0.1, 192.0.2.0/24",
+			lockedOut:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(db.User{}, sql.ErrNoRows)
+
+			config := newTestConfig()
+			config.TrustedProxies = tc.trustedProxies
+
+			server := newTestServerWithConfig(t, store, config)
+
+			// every guess is for another user and claims another client IP, only the IP key can lock them out
+			var recorder *httptest.ResponseRecorder
+			for i := int32(0); i <= lockout.DefaultPolicy.FreeAttempts; i++ {
+				data, err := json.Marshal(requestBody{"username": util.RandomUsername(), "password": util.RandomPassword()})
+				require.NoError(t, err)
+
+				// httptest request comes from 192.0.2.1
+				request := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+				request.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+
+				recorder = httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+			}
+
+			if tc.lockedOut {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			} else {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			}
+		})
+	}
+}
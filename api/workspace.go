@@ -0,0 +1,202 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var errOwnerLeavesWorkspace = errors.New("owner can't leave workspace, delete it instead")
+
+type createWorkspaceData struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// createWorkspace creates workspace owned by user
+func (s *Server) createWorkspace(ctx *gin.Context) {
+	var data createWorkspaceData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.CreateWorkspaceTxParams{
+		Name:    data.Name,
+		OwnerID: ctx.MustGet(userIdKey).(int32),
+	}
+
+	result, err := s.store.CreateWorkspaceTx(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, db.GetUserWorkspacesRow{
+		ID:        result.Workspace.ID,
+		Name:      result.Workspace.Name,
+		CreatedAt: result.Workspace.CreatedAt,
+		Role:      result.Owner.Role,
+	})
+}
+
+type getUserWorkspacesResponse struct {
+	Workspaces []db.GetUserWorkspacesRow `json:"workspaces"`
+}
+
+// getUserWorkspaces returns workspaces user is member of with its role in each one
+func (s *Server) getUserWorkspaces(ctx *gin.Context) {
+	workspaces, err := s.store.GetUserWorkspaces(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getUserWorkspacesResponse{Workspaces: workspaces})
+}
+
+func (s *Server) getWorkspace(ctx *gin.Context) {
+	member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+
+	workspace, err := s.store.GetWorkspace(ctx, member.WorkspaceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "workspace doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.GetUserWorkspacesRow{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		CreatedAt: workspace.CreatedAt,
+		Role:      member.Role,
+	})
+}
+
+type updateWorkspaceData struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+func (s *Server) updateWorkspace(ctx *gin.Context) {
+	var data updateWorkspaceData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.UpdateWorkspaceParams{
+		ID:   ctx.MustGet(workspaceIdKey).(int32),
+		Name: data.Name,
+	}
+
+	workspace, err := s.store.UpdateWorkspace(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "workspace doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, workspace)
+}
+
+// deleteWorkspace deletes workspace with its lists, members and invitations
+func (s *Server) deleteWorkspace(ctx *gin.Context) {
+	if err := s.store.DeleteWorkspace(ctx, ctx.MustGet(workspaceIdKey).(int32)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type getWorkspaceMembersResponse struct {
+	Members []db.GetWorkspaceMembersRow `json:"members"`
+}
+
+func (s *Server) getWorkspaceMembers(ctx *gin.Context) {
+	members, err := s.store.GetWorkspaceMembers(ctx, ctx.MustGet(workspaceIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getWorkspaceMembersResponse{Members: members})
+}
+
+type updateWorkspaceMemberData struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// updateWorkspaceMember changes role of member, role of owner can't be changed
+func (s *Server) updateWorkspaceMember(ctx *gin.Context) {
+	var data updateWorkspaceMemberData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.UpdateWorkspaceMemberRoleParams{
+		WorkspaceID: ctx.MustGet(workspaceIdKey).(int32),
+		UserID:      ctx.MustGet(userIdKey).(int32),
+		Role:        data.Role,
+	}
+
+	member, err := s.store.UpdateWorkspaceMemberRole(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "workspace doesn't have this member or member is owner"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// removeWorkspaceMember removes member from workspace, any member can leave workspace by removing itself
+func (s *Server) removeWorkspaceMember(ctx *gin.Context) {
+	member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+	requestedUserId := ctx.MustGet(userIdKey).(int32)
+
+	if requestedUserId == member.UserID {
+		if member.Role == db.WorkspaceRoleOwner {
+			ctx.JSON(http.StatusConflict, errorResponse(errOwnerLeavesWorkspace, ""))
+			return
+		}
+	} else if workspaceRoleRanks[member.Role] < workspaceRoleRanks[db.WorkspaceRoleAdmin] {
+		err := errors.New("only admin can remove other members")
+		ctx.JSON(http.StatusForbidden, errorResponse(err, ""))
+		return
+	}
+
+	params := db.DeleteWorkspaceMemberParams{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      requestedUserId,
+	}
+
+	deleted, err := s.store.DeleteWorkspaceMember(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if deleted == 0 {
+		err := errors.New("workspace doesn't have this member or member is owner")
+		ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const workspaceInvitationDuration = 7 * 24 * time.Hour
+
+type inviteToWorkspaceData struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// inviteToWorkspace invites user to workspace, invitation of the same user is replaced
+func (s *Server) inviteToWorkspace(ctx *gin.Context) {
+	member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+
+	var data inviteToWorkspaceData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	invitee, err := s.store.GetUser(ctx, data.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "user doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	memberParams := db.GetWorkspaceMemberParams{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      invitee.ID,
+	}
+
+	_, err = s.store.GetWorkspaceMember(ctx, memberParams)
+	if err == nil {
+		err := fmt.Errorf("user %s is member of workspace already", invitee.Username)
+		ctx.JSON(http.StatusConflict, errorResponse(err, ""))
+		return
+	}
+
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, "cannot create UUID"))
+		return
+	}
+
+	params := db.CreateWorkspaceInvitationParams{
+		ID:          id,
+		WorkspaceID: member.WorkspaceID,
+		UserID:      invitee.ID,
+		Role:        data.Role,
+		InvitedBy:   member.UserID,
+		ExpiresAt:   time.Now().Add(workspaceInvitationDuration),
+	}
+
+	invitation, err := s.store.CreateWorkspaceInvitation(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, invitation)
+}
+
+type getWorkspaceInvitationsResponse struct {
+	Invitations []db.WorkspaceInvitation `json:"invitations"`
+}
+
+// getWorkspaceInvitations returns pending invitations of workspace
+func (s *Server) getWorkspaceInvitations(ctx *gin.Context) {
+	invitations, err := s.store.GetWorkspaceInvitations(ctx, ctx.MustGet(workspaceIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getWorkspaceInvitationsResponse{Invitations: invitations})
+}
+
+func (s *Server) deleteWorkspaceInvitation(ctx *gin.Context) {
+	params := db.DeleteWorkspaceInvitationParams{
+		ID:          ctx.MustGet(workspaceInvitationIdKey).(uuid.UUID),
+		WorkspaceID: ctx.MustGet(workspaceIdKey).(int32),
+	}
+
+	deleted, err := s.store.DeleteWorkspaceInvitation(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	if deleted == 0 {
+		err := errors.New("workspace doesn't have this invitation")
+		ctx.JSON(http.StatusNotFound, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+type getUserInvitationsResponse struct {
+	Invitations []db.GetUserWorkspaceInvitationsRow `json:"invitations"`
+}
+
+// getUserInvitations returns pending invitations of user to workspaces
+func (s *Server) getUserInvitations(ctx *gin.Context) {
+	invitations, err := s.store.GetUserWorkspaceInvitations(ctx, ctx.MustGet(userIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getUserInvitationsResponse{Invitations: invitations})
+}
+
+func (s *Server) acceptWorkspaceInvitation(ctx *gin.Context) {
+	params := db.TakeWorkspaceInvitationParams{
+		ID:     ctx.MustGet(workspaceInvitationIdKey).(uuid.UUID),
+		UserID: ctx.MustGet(userIdKey).(int32),
+	}
+
+	result, err := s.store.AcceptWorkspaceInvitationTx(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "invitation doesn't exist or expired"))
+			return
+		}
+
+		if db.IsUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err, "user is member of workspace already"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Member)
+}
+
+func (s *Server) declineWorkspaceInvitation(ctx *gin.Context) {
+	params := db.TakeWorkspaceInvitationParams{
+		ID:     ctx.MustGet(workspaceInvitationIdKey).(uuid.UUID),
+		UserID: ctx.MustGet(userIdKey).(int32),
+	}
+
+	if _, err := s.store.TakeWorkspaceInvitation(ctx, params); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "invitation doesn't exist or expired"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInviteToWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	invitee := util.RandomUser()
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d/invitations", workspaceId)
+	body := requestBody{"username": invitee.Username, "role": db.WorkspaceRoleMember}
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   body,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+					getUserCall(store, invitee),
+
+					store.EXPECT().
+						GetWorkspaceMember(gomock.Any(), gomock.Eq(db.GetWorkspaceMemberParams{WorkspaceID: workspaceId, UserID: invitee.ID})).
+						Times(1).
+						Return(db.WorkspaceMember{}, sql.ErrNoRows),
+
+					store.EXPECT().
+						CreateWorkspaceInvitation(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, arg db.CreateWorkspaceInvitationParams) (db.WorkspaceInvitation, error) {
+							require.Equal(t, workspaceId, arg.WorkspaceID)
+							require.Equal(t, invitee.ID, arg.UserID)
+							require.Equal(t, user.ID, arg.InvitedBy)
+							require.Equal(t, db.WorkspaceRoleMember, arg.Role)
+							require.WithinDuration(t, time.Now().Add(workspaceInvitationDuration), arg.ExpiresAt, time.Second)
+
+							return db.WorkspaceInvitation{ID: arg.ID, WorkspaceID: arg.WorkspaceID, UserID: arg.UserID, Role: arg.Role}, nil
+						}),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusCreated),
+		},
+		{
+			name:          "MemberRole",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   body,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+				)
+
+				store.EXPECT().
+					CreateWorkspaceInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "InvalidRole",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"username": invitee.Username, "role": db.WorkspaceRoleOwner},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleOwner),
+				)
+
+				store.EXPECT().
+					CreateWorkspaceInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "UserDoesNotExist",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   body,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+
+					store.EXPECT().
+						GetUser(gomock.Any(), gomock.Eq(invitee.Username)).
+						Times(1).
+						Return(db.User{}, sql.ErrNoRows),
+				)
+
+				store.EXPECT().
+					CreateWorkspaceInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "AlreadyMember",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   body,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+					getUserCall(store, invitee),
+					getWorkspaceMemberCall(store, workspaceId, invitee.ID, db.WorkspaceRoleMember),
+				)
+
+				store.EXPECT().
+					CreateWorkspaceInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestAcceptWorkspaceInvitationAPI(t *testing.T) {
+	user := util.RandomUser()
+	invitationId := uuid.New()
+
+	url := fmt.Sprintf("/users/%d/invitations/%s/accept", user.ID, invitationId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	params := db.TakeWorkspaceInvitationParams{ID: invitationId, UserID: user.ID}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				result := db.AcceptWorkspaceInvitationTxResult{
+					Member: db.WorkspaceMember{WorkspaceID: util.RandomID(), UserID: user.ID, Role: db.WorkspaceRoleMember},
+				}
+
+				store.EXPECT().
+					AcceptWorkspaceInvitationTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(result, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "InvitationDoesNotExist",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AcceptWorkspaceInvitationTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.AcceptWorkspaceInvitationTxResult{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+		{
+			name:          "AlreadyMember",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AcceptWorkspaceInvitationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AcceptWorkspaceInvitationTxResult{}, &pq.Error{Code: "23505"}).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "InvalidInvitationId",
+			requestMethod: http.MethodPost,
+			requestUrl:    fmt.Sprintf("/users/%d/invitations/abc/accept", user.ID),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AcceptWorkspaceInvitationTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeclineWorkspaceInvitationAPI(t *testing.T) {
+	user := util.RandomUser()
+	invitationId := uuid.New()
+
+	url := fmt.Sprintf("/users/%d/invitations/%s", user.ID, invitationId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	params := db.TakeWorkspaceInvitationParams{ID: invitationId, UserID: user.ID}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TakeWorkspaceInvitation(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.WorkspaceInvitation{ID: invitationId, UserID: user.ID}, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "InvitationDoesNotExist",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TakeWorkspaceInvitation(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(db.WorkspaceInvitation{}, sql.ErrNoRows).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
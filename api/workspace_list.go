@@ -0,0 +1,127 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	dbtypes "github.com/PYTNAG/simpletodo/db/types"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/gin-gonic/gin"
+)
+
+type getWorkspaceListsResponse struct {
+	Lists []db.GetWorkspaceListsRow `json:"lists"`
+}
+
+func (s *Server) getWorkspaceLists(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	lists, err := s.store.GetWorkspaceLists(ctx, ctx.MustGet(workspaceIdKey).(int32))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	// token restricted to some lists doesn't see the others
+	allowedLists := make([]db.GetWorkspaceListsRow, 0, len(lists))
+	for _, list := range lists {
+		if authPayload.AllowsList(list.ID) {
+			allowedLists = append(allowedLists, list)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getWorkspaceListsResponse{Lists: allowedLists})
+}
+
+func (s *Server) addListToWorkspace(ctx *gin.Context) {
+	member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+
+	var data addListToUserData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	params := db.AddWorkspaceListParams{
+		Author:      member.UserID,
+		WorkspaceID: member.WorkspaceID,
+		Header:      data.Header,
+	}
+
+	list, err := s.store.AddWorkspaceList(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, list)
+}
+
+type transferListToWorkspaceData struct {
+	WorkspaceID int32 `json:"workspace_id" binding:"required,min=1"`
+}
+
+// transferListToWorkspace moves personal list of user to workspace user is member of
+func (s *Server) transferListToWorkspace(ctx *gin.Context) {
+	userId := ctx.MustGet(userIdKey).(int32)
+
+	var data transferListToWorkspaceData
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err, ""))
+		return
+	}
+
+	memberParams := db.GetWorkspaceMemberParams{
+		WorkspaceID: data.WorkspaceID,
+		UserID:      userId,
+	}
+
+	if _, err := s.store.GetWorkspaceMember(ctx, memberParams); err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("user %d isn't member of workspace %d", userId, data.WorkspaceID)
+			ctx.JSON(http.StatusForbidden, errorResponse(err, ""))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	params := db.SetListWorkspaceParams{
+		ID:          ctx.MustGet(listIdKey).(int32),
+		WorkspaceID: dbtypes.NewNullInt32(data.WorkspaceID, true),
+		Author:      userId,
+	}
+
+	s.setListWorkspace(ctx, params)
+}
+
+// transferListFromWorkspace moves list of workspace to personal space of the member requested it
+func (s *Server) transferListFromWorkspace(ctx *gin.Context) {
+	member := ctx.MustGet(workspaceMemberKey).(db.WorkspaceMember)
+
+	params := db.SetListWorkspaceParams{
+		ID:          ctx.MustGet(listIdKey).(int32),
+		WorkspaceID: dbtypes.NewNullInt32(0, false),
+		Author:      member.UserID,
+	}
+
+	s.setListWorkspace(ctx, params)
+}
+
+func (s *Server) setListWorkspace(ctx *gin.Context, params db.SetListWorkspaceParams) {
+	result, err := s.store.SetListWorkspaceTx(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err, "list doesn't exist"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err, ""))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.List)
+}
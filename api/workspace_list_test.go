@@ -0,0 +1,294 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	dbtypes "github.com/PYTNAG/simpletodo/db/types"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func getWorkspaceListsCall(store *mockdb.MockStore, workspaceId int32, returnedListId int32) *gomock.Call {
+	return store.EXPECT().
+		GetWorkspaceLists(gomock.Any(), gomock.Eq(workspaceId)).
+		Times(1).
+		Return(
+			[]db.GetWorkspaceListsRow{
+				{ID: returnedListId},
+			}, nil)
+}
+
+func TestAddListToWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	workspaceId := util.RandomID()
+	header := util.RandomString(8)
+
+	url := fmt.Sprintf("/workspaces/%d/lists", workspaceId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"header": header},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.AddWorkspaceListParams{
+					Author:      user.ID,
+					WorkspaceID: workspaceId,
+					Header:      header,
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+
+					store.EXPECT().
+						AddWorkspaceList(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.List{ID: util.RandomID(), Author: user.ID, Header: header}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusCreated),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"header": header},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+
+					store.EXPECT().
+						AddWorkspaceList(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.List{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetWorkspaceTasksAPI(t *testing.T) {
+	user := util.RandomUser()
+	workspaceId := util.RandomID()
+	listId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d/lists/%d/tasks", workspaceId, listId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+					getWorkspaceListsCall(store, workspaceId, listId),
+					getTasksCall(store, listId, util.RandomID()),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "ListOfOtherWorkspace",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+					getWorkspaceListsCall(store, workspaceId, listId+1),
+				)
+
+				store.EXPECT().
+					GetTasks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestTransferListToWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/users/%d/lists/%d/transfer", user.ID, listId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"workspace_id": workspaceId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.SetListWorkspaceParams{
+					ID:          listId,
+					WorkspaceID: dbtypes.NewNullInt32(workspaceId, true),
+					Author:      user.ID,
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+
+					store.EXPECT().
+						SetListWorkspaceTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.SetListWorkspaceTxResult{List: db.List{ID: listId, Author: user.ID, WorkspaceID: params.WorkspaceID}}, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"workspace_id":%d`, workspaceId))
+			},
+		},
+		{
+			name:          "NotMember",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"workspace_id": workspaceId},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+
+					store.EXPECT().
+						GetWorkspaceMember(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.WorkspaceMember{}, sql.ErrNoRows),
+				)
+
+				store.EXPECT().
+					SetListWorkspaceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "NoWorkspace",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getListsCall(store, user.ID, listId),
+				)
+
+				store.EXPECT().
+					SetListWorkspaceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestTransferListFromWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	listId := util.RandomID()
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d/lists/%d/transfer", workspaceId, listId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.SetListWorkspaceParams{
+					ID:          listId,
+					WorkspaceID: dbtypes.NewNullInt32(0, false),
+					Author:      user.ID,
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+					getWorkspaceListsCall(store, workspaceId, listId),
+
+					store.EXPECT().
+						SetListWorkspaceTx(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.SetListWorkspaceTxResult{List: db.List{ID: listId, Author: user.ID}}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "MemberRole",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+					getWorkspaceListsCall(store, workspaceId, listId),
+				)
+
+				store.EXPECT().
+					SetListWorkspaceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -0,0 +1,487 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/PYTNAG/simpletodo/db/mock"
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/token"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	name := util.RandomString(8)
+
+	url := fmt.Sprintf("/users/%d/workspaces", user.ID)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	workspace := db.Workspace{ID: util.RandomID(), Name: name, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.CreateWorkspaceTxParams{Name: name, OwnerID: user.ID}
+
+				result := db.CreateWorkspaceTxResult{
+					Workspace: workspace,
+					Owner:     db.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: db.WorkspaceRoleOwner},
+				}
+
+				store.EXPECT().
+					CreateWorkspaceTx(gomock.Any(), gomock.Eq(params)).
+					Times(1).
+					Return(result, nil).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				response := unmarshal[db.GetUserWorkspacesRow](t, recorder.Body)
+				require.Equal(t, workspace.ID, response.ID)
+				require.Equal(t, name, response.Name)
+				require.Equal(t, db.WorkspaceRoleOwner, response.Role)
+			},
+		},
+		{
+			name:          "NoName",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWorkspaceTx(gomock.Any(), gomock.Any()).
+					Times(0).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "InternalError",
+			requestMethod: http.MethodPost,
+			requestUrl:    url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWorkspaceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateWorkspaceTxResult{}, sql.ErrConnDone).
+					After(authorizedCalls(store, user))
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestGetWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d", workspaceId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	workspace := db.Workspace{ID: workspaceId, Name: util.RandomString(8)}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+
+					store.EXPECT().
+						GetWorkspace(gomock.Any(), gomock.Eq(workspaceId)).
+						Times(1).
+						Return(workspace, nil),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				response := unmarshal[db.GetUserWorkspacesRow](t, recorder.Body)
+				require.Equal(t, workspace.Name, response.Name)
+				require.Equal(t, db.WorkspaceRoleMember, response.Role)
+			},
+		},
+		{
+			name:          "NotMember",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+
+					store.EXPECT().
+						GetWorkspaceMember(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.WorkspaceMember{}, sql.ErrNoRows),
+				)
+
+				store.EXPECT().
+					GetWorkspace(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "MemberInternalError",
+			requestMethod: http.MethodGet,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+
+					store.EXPECT().
+						GetWorkspaceMember(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.WorkspaceMember{}, sql.ErrConnDone),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestUpdateWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	workspaceId := util.RandomID()
+	name := util.RandomString(8)
+
+	url := fmt.Sprintf("/workspaces/%d", workspaceId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPatch,
+			requestUrl:    url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.UpdateWorkspaceParams{ID: workspaceId, Name: name}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+
+					store.EXPECT().
+						UpdateWorkspace(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.Workspace{ID: workspaceId, Name: name}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "MemberRole",
+			requestMethod: http.MethodPatch,
+			requestUrl:    url,
+			requestBody:   requestBody{"name": name},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+				)
+
+				store.EXPECT().
+					UpdateWorkspace(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestDeleteWorkspaceAPI(t *testing.T) {
+	user := util.RandomUser()
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d", workspaceId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleOwner),
+
+					store.EXPECT().
+						DeleteWorkspace(gomock.Any(), gomock.Eq(workspaceId)).
+						Times(1).
+						Return(nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "AdminRole",
+			requestMethod: http.MethodDelete,
+			requestUrl:    url,
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+				)
+
+				store.EXPECT().
+					DeleteWorkspace(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestRemoveWorkspaceMemberAPI(t *testing.T) {
+	user := util.RandomUser()
+	otherUserId := user.ID + 1
+	workspaceId := util.RandomID()
+
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	memberUrl := func(userId int32) string {
+		return fmt.Sprintf("/workspaces/%d/members/%d", workspaceId, userId)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "AdminRemovesMember",
+			requestMethod: http.MethodDelete,
+			requestUrl:    memberUrl(otherUserId),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.DeleteWorkspaceMemberParams{WorkspaceID: workspaceId, UserID: otherUserId}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+
+					store.EXPECT().
+						DeleteWorkspaceMember(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(int64(1), nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "MemberLeaves",
+			requestMethod: http.MethodDelete,
+			requestUrl:    memberUrl(user.ID),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.DeleteWorkspaceMemberParams{WorkspaceID: workspaceId, UserID: user.ID}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+
+					store.EXPECT().
+						DeleteWorkspaceMember(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(int64(1), nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNoContent),
+		},
+		{
+			name:          "OwnerLeaves",
+			requestMethod: http.MethodDelete,
+			requestUrl:    memberUrl(user.ID),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleOwner),
+				)
+
+				store.EXPECT().
+					DeleteWorkspaceMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusConflict),
+		},
+		{
+			name:          "MemberRemovesOther",
+			requestMethod: http.MethodDelete,
+			requestUrl:    memberUrl(otherUserId),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleMember),
+				)
+
+				store.EXPECT().
+					DeleteWorkspaceMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusForbidden),
+		},
+		{
+			name:          "RemovesOwner",
+			requestMethod: http.MethodDelete,
+			requestUrl:    memberUrl(otherUserId),
+			requestBody:   nil,
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+
+					store.EXPECT().
+						DeleteWorkspaceMember(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(int64(0), nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
+
+func TestUpdateWorkspaceMemberAPI(t *testing.T) {
+	user := util.RandomUser()
+	otherUserId := user.ID + 1
+	workspaceId := util.RandomID()
+
+	url := fmt.Sprintf("/workspaces/%d/members/%d", workspaceId, otherUserId)
+	setupAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, user.ID, time.Minute)
+	}
+
+	testCases := []*apiTestCase{
+		{
+			name:          "OK",
+			requestMethod: http.MethodPatch,
+			requestUrl:    url,
+			requestBody:   requestBody{"role": db.WorkspaceRoleAdmin},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				params := db.UpdateWorkspaceMemberRoleParams{
+					WorkspaceID: workspaceId,
+					UserID:      otherUserId,
+					Role:        db.WorkspaceRoleAdmin,
+				}
+
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleOwner),
+
+					store.EXPECT().
+						UpdateWorkspaceMemberRole(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(db.WorkspaceMember{WorkspaceID: workspaceId, UserID: otherUserId, Role: params.Role}, nil),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusOK),
+		},
+		{
+			name:          "OwnerRoleCantBeGiven",
+			requestMethod: http.MethodPatch,
+			requestUrl:    url,
+			requestBody:   requestBody{"role": db.WorkspaceRoleOwner},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleOwner),
+				)
+
+				store.EXPECT().
+					UpdateWorkspaceMemberRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: requierResponseCode(http.StatusBadRequest),
+		},
+		{
+			name:          "MemberIsOwner",
+			requestMethod: http.MethodPatch,
+			requestUrl:    url,
+			requestBody:   requestBody{"role": db.WorkspaceRoleMember},
+			setupAuth:     setupAuth,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					authorizedCalls(store, user),
+					getWorkspaceMemberCall(store, workspaceId, user.ID, db.WorkspaceRoleAdmin),
+
+					store.EXPECT().
+						UpdateWorkspaceMemberRole(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.WorkspaceMember{}, sql.ErrNoRows),
+				)
+			},
+			checkResponse: requierResponseCode(http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, apiTestingFunc(tc))
+	}
+}
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "is_rotated";
+
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "parent_id";
+
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "family_id";
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE "personal_access_tokens" (
+    "id" uuid PRIMARY KEY,
+    "user_id" int NOT NULL,
+    "name" text NOT NULL,
+    "token_hash" bytea UNIQUE NOT NULL,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "last_used_ip" text,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "personal_access_tokens" ("user_id");
+
+ALTER TABLE "personal_access_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "personal_access_tokens" DROP COLUMN IF EXISTS "list_ids";
+
+ALTER TABLE IF EXISTS "personal_access_tokens" DROP COLUMN IF EXISTS "scopes";
@@ -0,0 +1,8 @@
+ALTER TABLE "personal_access_tokens" ADD COLUMN "scopes" text[];
+
+-- tokens created before scopes could do everything
+UPDATE "personal_access_tokens" SET "scopes" = ARRAY['lists:read', 'lists:write', 'tasks:read', 'tasks:write', 'account:admin'];
+
+ALTER TABLE "personal_access_tokens" ALTER COLUMN "scopes" SET NOT NULL;
+
+ALTER TABLE "personal_access_tokens" ADD COLUMN "list_ids" int[] NOT NULL DEFAULT '{}';
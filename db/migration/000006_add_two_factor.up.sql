@@ -0,0 +1,20 @@
+CREATE TABLE "user_totp" (
+    "user_id" int PRIMARY KEY,
+    "secret" text NOT NULL,
+    "is_enabled" boolean NOT NULL DEFAULT FALSE,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" int NOT NULL,
+    "code_hash" bytea NOT NULL,
+    "used_at" timestamptz
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
+
+ALTER TABLE "user_totp" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
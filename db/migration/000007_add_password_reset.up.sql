@@ -0,0 +1,13 @@
+ALTER TABLE "users" ADD COLUMN "email" text UNIQUE;
+
+CREATE TABLE "password_reset_tokens" (
+    "token_hash" bytea PRIMARY KEY,
+    "user_id" int NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "password_reset_tokens" ("user_id");
+
+ALTER TABLE "password_reset_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
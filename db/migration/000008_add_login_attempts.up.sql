@@ -0,0 +1,5 @@
+CREATE TABLE "login_attempts" (
+    "key" text PRIMARY KEY,
+    "failures" int NOT NULL,
+    "last_failed_at" timestamptz NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "oidc_auth_requests";
+
+DROP TABLE IF EXISTS "user_identities";
@@ -0,0 +1,23 @@
+CREATE TABLE "user_identities" (
+    "issuer" text NOT NULL,
+    "subject" text NOT NULL,
+    "user_id" int NOT NULL,
+    "email" text,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("issuer", "subject")
+);
+
+CREATE INDEX ON "user_identities" ("user_id");
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "oidc_auth_requests" (
+    "state" text PRIMARY KEY,
+    "nonce" text NOT NULL,
+    "code_verifier" text NOT NULL,
+    "user_id" int,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "oidc_auth_requests" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
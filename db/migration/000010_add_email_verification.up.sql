@@ -0,0 +1,13 @@
+ALTER TABLE "users" ADD COLUMN "email_verified" bool NOT NULL DEFAULT false;
+
+CREATE TABLE "email_verification_tokens" (
+    "token_hash" bytea PRIMARY KEY,
+    "user_id" int NOT NULL,
+    "email" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "email_verification_tokens" ("user_id");
+
+ALTER TABLE "email_verification_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
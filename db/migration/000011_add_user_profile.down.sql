@@ -0,0 +1,6 @@
+ALTER TABLE "sessions" DROP CONSTRAINT IF EXISTS "sessions_username_fkey";
+ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "locale";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "time_zone";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "display_name";
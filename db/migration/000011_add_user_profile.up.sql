@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "display_name" text NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "time_zone" text NOT NULL DEFAULT 'UTC';
+ALTER TABLE "users" ADD COLUMN "locale" text NOT NULL DEFAULT 'en';
+
+-- sessions follow username change of their user
+ALTER TABLE "sessions" DROP CONSTRAINT "sessions_username_fkey";
+ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "role" text NOT NULL DEFAULT 'user';
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin'));
+ALTER TABLE "users" ADD COLUMN "disabled_at" timestamptz;
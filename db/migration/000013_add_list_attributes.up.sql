@@ -0,0 +1,4 @@
+ALTER TABLE "lists" ADD COLUMN "description" text NOT NULL DEFAULT '';
+ALTER TABLE "lists" ADD COLUMN "color" text NOT NULL DEFAULT '';
+ALTER TABLE "lists" ADD COLUMN "icon" text NOT NULL DEFAULT '';
+ALTER TABLE "lists" ADD COLUMN "archived" bool NOT NULL DEFAULT false;
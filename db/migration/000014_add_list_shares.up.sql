@@ -0,0 +1,11 @@
+CREATE TABLE "list_shares" (
+    "id" uuid PRIMARY KEY,
+    "list_id" int NOT NULL,
+    "token_hash" bytea UNIQUE NOT NULL,
+    "expires_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "list_shares" ("list_id");
+
+ALTER TABLE "list_shares" ADD FOREIGN KEY ("list_id") REFERENCES "lists" ("id") ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+DELETE FROM "lists" WHERE "workspace_id" IS NOT NULL;
+ALTER TABLE "lists" DROP COLUMN IF EXISTS "workspace_id";
+
+DROP TABLE IF EXISTS "workspace_invitations";
+DROP TABLE IF EXISTS "workspace_members";
+DROP TABLE IF EXISTS "workspaces";
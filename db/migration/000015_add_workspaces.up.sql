@@ -0,0 +1,45 @@
+CREATE TABLE "workspaces" (
+    "id" serial PRIMARY KEY,
+    "name" text NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "workspace_members" (
+    "workspace_id" int NOT NULL,
+    "user_id" int NOT NULL,
+    "role" text NOT NULL CHECK ("role" IN ('owner', 'admin', 'member')),
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("workspace_id", "user_id")
+);
+
+CREATE INDEX ON "workspace_members" ("user_id");
+
+ALTER TABLE "workspace_members" ADD FOREIGN KEY ("workspace_id") REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "workspace_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "workspace_invitations" (
+    "id" uuid PRIMARY KEY,
+    "workspace_id" int NOT NULL,
+    "user_id" int NOT NULL,
+    "role" text NOT NULL CHECK ("role" IN ('admin', 'member')),
+    "invited_by" int NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("workspace_id", "user_id")
+);
+
+CREATE INDEX ON "workspace_invitations" ("user_id");
+
+ALTER TABLE "workspace_invitations" ADD FOREIGN KEY ("workspace_id") REFERENCES "workspaces" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "workspace_invitations" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "workspace_invitations" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- lists without workspace are personal lists of author
+ALTER TABLE "lists" ADD COLUMN "workspace_id" int;
+
+CREATE INDEX ON "lists" ("workspace_id");
+
+ALTER TABLE "lists" ADD FOREIGN KEY ("workspace_id") REFERENCES "workspaces" ("id") ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS "tasks_check_parent" ON "tasks";
+DROP FUNCTION IF EXISTS "check_task_parent"();
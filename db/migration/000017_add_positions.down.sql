@@ -0,0 +1,2 @@
+ALTER TABLE "tasks" DROP COLUMN IF EXISTS "position";
+ALTER TABLE "lists" DROP COLUMN IF EXISTS "position";
@@ -0,0 +1,23 @@
+ALTER TABLE "tasks" ADD COLUMN "position" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "lists" ADD COLUMN "position" double precision NOT NULL DEFAULT 0;
+
+-- existing items keep their creation order, positions have gaps to put moved items in between
+UPDATE "tasks" SET "position" = "ranked"."rank" * 1024
+FROM (
+  SELECT "id", row_number() OVER (PARTITION BY "list_id", "parent_task" ORDER BY "id") AS "rank" FROM "tasks"
+) AS "ranked"
+WHERE "tasks"."id" = "ranked"."id";
+
+UPDATE "lists" SET "position" = "ranked"."rank" * 1024
+FROM (
+  SELECT "id", row_number() OVER (
+    PARTITION BY "workspace_id", CASE WHEN "workspace_id" IS NULL THEN "author" END ORDER BY "id"
+  ) AS "rank" FROM "lists"
+) AS "ranked"
+WHERE "lists"."id" = "ranked"."id";
+
+CREATE INDEX ON "tasks" ("list_id", "parent_task", "position");
+
+CREATE INDEX ON "lists" ("author", "workspace_id", "position");
+
+CREATE INDEX ON "lists" ("workspace_id", "position");
@@ -0,0 +1,11 @@
+CREATE TABLE "login_challenges" (
+    "id" uuid PRIMARY KEY,
+    "user_id" int NOT NULL,
+    "attempts" int NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_challenges" ("user_id");
+
+ALTER TABLE "login_challenges" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,27 @@
+CREATE OR REPLACE FUNCTION "check_task_parent"() RETURNS trigger AS $$
+BEGIN
+  IF NEW."parent_task" IS NULL THEN
+    RETURN NEW;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM "tasks" WHERE "id" = NEW."parent_task" and "list_id" = NEW."list_id") THEN
+    RAISE EXCEPTION 'parent task % is not in list %', NEW."parent_task", NEW."list_id"
+      USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'tasks_parent_task_list';
+  END IF;
+
+  IF EXISTS (
+    WITH RECURSIVE "ancestors" AS (
+      SELECT "id", "parent_task" FROM "tasks" WHERE "id" = NEW."parent_task"
+      UNION
+      SELECT "tasks"."id", "tasks"."parent_task" FROM "tasks"
+      JOIN "ancestors" ON "tasks"."id" = "ancestors"."parent_task"
+    )
+    SELECT 1 FROM "ancestors" WHERE "id" = NEW."id"
+  ) THEN
+    RAISE EXCEPTION 'task % cannot be descendant of itself', NEW."id"
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'tasks_parent_task_cycle';
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,32 @@
+-- concurrent re-parents in the same list are serialized by lock of the list row,
+-- otherwise each of them could pass the cycle check before the other is committed
+CREATE OR REPLACE FUNCTION "check_task_parent"() RETURNS trigger AS $$
+BEGIN
+  IF NEW."parent_task" IS NULL THEN
+    RETURN NEW;
+  END IF;
+
+  -- doesn't conflict with key share lock of foreign keys, so tasks can still be added to the list
+  PERFORM 1 FROM "lists" WHERE "id" = NEW."list_id" FOR NO KEY UPDATE;
+
+  IF NOT EXISTS (SELECT 1 FROM "tasks" WHERE "id" = NEW."parent_task" and "list_id" = NEW."list_id") THEN
+    RAISE EXCEPTION 'parent task % is not in list %', NEW."parent_task", NEW."list_id"
+      USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'tasks_parent_task_list';
+  END IF;
+
+  IF EXISTS (
+    WITH RECURSIVE "ancestors" AS (
+      SELECT "id", "parent_task" FROM "tasks" WHERE "id" = NEW."parent_task"
+      UNION
+      SELECT "tasks"."id", "tasks"."parent_task" FROM "tasks"
+      JOIN "ancestors" ON "tasks"."id" = "ancestors"."parent_task"
+    )
+    SELECT 1 FROM "ancestors" WHERE "id" = NEW."id"
+  ) THEN
+    RAISE EXCEPTION 'task % cannot be descendant of itself', NEW."id"
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'tasks_parent_task_cycle';
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -10,6 +10,7 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
 
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
@@ -40,6 +41,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptWorkspaceInvitationTx mocks base method.
+func (m *MockStore) AcceptWorkspaceInvitationTx(arg0 context.Context, arg1 db.TakeWorkspaceInvitationParams) (db.AcceptWorkspaceInvitationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptWorkspaceInvitationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AcceptWorkspaceInvitationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptWorkspaceInvitationTx indicates an expected call of AcceptWorkspaceInvitationTx.
+func (mr *MockStoreMockRecorder) AcceptWorkspaceInvitationTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptWorkspaceInvitationTx", reflect.TypeOf((*MockStore)(nil).AcceptWorkspaceInvitationTx), arg0, arg1)
+}
+
 // AddList mocks base method.
 func (m *MockStore) AddList(arg0 context.Context, arg1 db.AddListParams) (db.List, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +71,21 @@ func (mr *MockStoreMockRecorder) AddList(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddList", reflect.TypeOf((*MockStore)(nil).AddList), arg0, arg1)
 }
 
+// AddLoginFailure mocks base method.
+func (m *MockStore) AddLoginFailure(arg0 context.Context, arg1 db.AddLoginFailureParams) (db.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLoginFailure indicates an expected call of AddLoginFailure.
+func (mr *MockStoreMockRecorder) AddLoginFailure(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoginFailure", reflect.TypeOf((*MockStore)(nil).AddLoginFailure), arg0, arg1)
+}
+
 // AddTask mocks base method.
 func (m *MockStore) AddTask(arg0 context.Context, arg1 db.AddTaskParams) (db.Task, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +101,256 @@ func (mr *MockStoreMockRecorder) AddTask(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTask", reflect.TypeOf((*MockStore)(nil).AddTask), arg0, arg1)
 }
 
+// AddWorkspaceList mocks base method.
+func (m *MockStore) AddWorkspaceList(arg0 context.Context, arg1 db.AddWorkspaceListParams) (db.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWorkspaceList", arg0, arg1)
+	ret0, _ := ret[0].(db.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWorkspaceList indicates an expected call of AddWorkspaceList.
+func (mr *MockStoreMockRecorder) AddWorkspaceList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWorkspaceList", reflect.TypeOf((*MockStore)(nil).AddWorkspaceList), arg0, arg1)
+}
+
+// AddWorkspaceMember mocks base method.
+func (m *MockStore) AddWorkspaceMember(arg0 context.Context, arg1 db.AddWorkspaceMemberParams) (db.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWorkspaceMember", arg0, arg1)
+	ret0, _ := ret[0].(db.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWorkspaceMember indicates an expected call of AddWorkspaceMember.
+func (mr *MockStoreMockRecorder) AddWorkspaceMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWorkspaceMember", reflect.TypeOf((*MockStore)(nil).AddWorkspaceMember), arg0, arg1)
+}
+
+// BlockOtherUserSessions mocks base method.
+func (m *MockStore) BlockOtherUserSessions(arg0 context.Context, arg1 db.BlockOtherUserSessionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockOtherUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockOtherUserSessions indicates an expected call of BlockOtherUserSessions.
+func (mr *MockStoreMockRecorder) BlockOtherUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockOtherUserSessions", reflect.TypeOf((*MockStore)(nil).BlockOtherUserSessions), arg0, arg1)
+}
+
+// BlockSessionFamily mocks base method.
+func (m *MockStore) BlockSessionFamily(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockStoreMockRecorder) BlockSessionFamily(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockStore)(nil).BlockSessionFamily), arg0, arg1)
+}
+
+// BlockUserSession mocks base method.
+func (m *MockStore) BlockUserSession(arg0 context.Context, arg1 db.BlockUserSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSession indicates an expected call of BlockUserSession.
+func (mr *MockStoreMockRecorder) BlockUserSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSession", reflect.TypeOf((*MockStore)(nil).BlockUserSession), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// ChangeEmailTx mocks base method.
+func (m *MockStore) ChangeEmailTx(arg0 context.Context, arg1 db.ChangeEmailTxParams) (db.ChangeEmailTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ChangeEmailTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeEmailTx indicates an expected call of ChangeEmailTx.
+func (mr *MockStoreMockRecorder) ChangeEmailTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmailTx", reflect.TypeOf((*MockStore)(nil).ChangeEmailTx), arg0, arg1)
+}
+
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 db.ChangePasswordTxParams) (db.ChangePasswordTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ChangePasswordTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
+}
+
+// CreateEmailVerificationToken mocks base method.
+func (m *MockStore) CreateEmailVerificationToken(arg0 context.Context, arg1 db.CreateEmailVerificationTokenParams) (db.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerificationToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerificationToken indicates an expected call of CreateEmailVerificationToken.
+func (mr *MockStoreMockRecorder) CreateEmailVerificationToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerificationToken", reflect.TypeOf((*MockStore)(nil).CreateEmailVerificationToken), arg0, arg1)
+}
+
+// CreateIdentityUserTx mocks base method.
+func (m *MockStore) CreateIdentityUserTx(arg0 context.Context, arg1 db.CreateIdentityUserTxParams) (db.CreateIdentityUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdentityUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateIdentityUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdentityUserTx indicates an expected call of CreateIdentityUserTx.
+func (mr *MockStoreMockRecorder) CreateIdentityUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdentityUserTx", reflect.TypeOf((*MockStore)(nil).CreateIdentityUserTx), arg0, arg1)
+}
+
+// CreateListShare mocks base method.
+func (m *MockStore) CreateListShare(arg0 context.Context, arg1 db.CreateListShareParams) (db.ListShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateListShare", arg0, arg1)
+	ret0, _ := ret[0].(db.ListShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateListShare indicates an expected call of CreateListShare.
+func (mr *MockStoreMockRecorder) CreateListShare(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListShare", reflect.TypeOf((*MockStore)(nil).CreateListShare), arg0, arg1)
+}
+
+// CreateLoginAttempts mocks base method.
+func (m *MockStore) CreateLoginAttempts(arg0 context.Context, arg1 db.CreateLoginAttemptsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginAttempts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginAttempts indicates an expected call of CreateLoginAttempts.
+func (mr *MockStoreMockRecorder) CreateLoginAttempts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginAttempts", reflect.TypeOf((*MockStore)(nil).CreateLoginAttempts), arg0, arg1)
+}
+
+// CreateLoginChallenge mocks base method.
+func (m *MockStore) CreateLoginChallenge(arg0 context.Context, arg1 db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge.
+func (mr *MockStoreMockRecorder) CreateLoginChallenge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockStore)(nil).CreateLoginChallenge), arg0, arg1)
+}
+
+// CreateOidcAuthRequest mocks base method.
+func (m *MockStore) CreateOidcAuthRequest(arg0 context.Context, arg1 db.CreateOidcAuthRequestParams) (db.OidcAuthRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOidcAuthRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcAuthRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOidcAuthRequest indicates an expected call of CreateOidcAuthRequest.
+func (mr *MockStoreMockRecorder) CreateOidcAuthRequest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOidcAuthRequest", reflect.TypeOf((*MockStore)(nil).CreateOidcAuthRequest), arg0, arg1)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockStore) CreatePasswordResetToken(arg0 context.Context, arg1 db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockStoreMockRecorder) CreatePasswordResetToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetToken), arg0, arg1)
+}
+
+// CreatePersonalAccessToken mocks base method.
+func (m *MockStore) CreatePersonalAccessToken(arg0 context.Context, arg1 db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken.
+func (mr *MockStoreMockRecorder) CreatePersonalAccessToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).CreatePersonalAccessToken), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -100,163 +381,1464 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
-// CreateUserTx mocks base method.
-func (m *MockStore) CreateUserTx(arg0 context.Context, arg1 db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(arg0 context.Context, arg1 db.CreateUserIdentityParams) (db.UserIdentity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserTx", arg0, arg1)
-	ret0, _ := ret[0].(db.CreateUserTxResult)
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUserTx indicates an expected call of CreateUserTx.
-func (mr *MockStoreMockRecorder) CreateUserTx(arg0, arg1 any) *gomock.Call {
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTx", reflect.TypeOf((*MockStore)(nil).CreateUserTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), arg0, arg1)
 }
 
-// DeleteList mocks base method.
-func (m *MockStore) DeleteList(arg0 context.Context, arg1 int32) error {
+// CreateUserTOTP mocks base method.
+func (m *MockStore) CreateUserTOTP(arg0 context.Context, arg1 db.CreateUserTOTPParams) (db.UserTotp, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteList", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteList indicates an expected call of DeleteList.
-func (mr *MockStoreMockRecorder) DeleteList(arg0, arg1 any) *gomock.Call {
+// CreateUserTOTP indicates an expected call of CreateUserTOTP.
+func (mr *MockStoreMockRecorder) CreateUserTOTP(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockStore)(nil).DeleteList), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTOTP", reflect.TypeOf((*MockStore)(nil).CreateUserTOTP), arg0, arg1)
 }
 
-// DeleteTask mocks base method.
-func (m *MockStore) DeleteTask(arg0 context.Context, arg1 int32) error {
+// CreateUserTx mocks base method.
+func (m *MockStore) CreateUserTx(arg0 context.Context, arg1 db.CreateUserTxParams) (db.CreateUserTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTask", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteTask indicates an expected call of DeleteTask.
-func (mr *MockStoreMockRecorder) DeleteTask(arg0, arg1 any) *gomock.Call {
+// CreateUserTx indicates an expected call of CreateUserTx.
+func (mr *MockStoreMockRecorder) CreateUserTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockStore)(nil).DeleteTask), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTx", reflect.TypeOf((*MockStore)(nil).CreateUserTx), arg0, arg1)
 }
 
-// DeleteUser mocks base method.
-func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int32) (db.DeleteUserRow, error) {
+// CreateWorkspace mocks base method.
+func (m *MockStore) CreateWorkspace(arg0 context.Context, arg1 string) (db.Workspace, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
-	ret0, _ := ret[0].(db.DeleteUserRow)
+	ret := m.ctrl.Call(m, "CreateWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(db.Workspace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 any) *gomock.Call {
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockStoreMockRecorder) CreateWorkspace(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockStore)(nil).CreateWorkspace), arg0, arg1)
 }
 
-// GetLists mocks base method.
-func (m *MockStore) GetLists(arg0 context.Context, arg1 int32) ([]db.GetListsRow, error) {
+// CreateWorkspaceInvitation mocks base method.
+func (m *MockStore) CreateWorkspaceInvitation(arg0 context.Context, arg1 db.CreateWorkspaceInvitationParams) (db.WorkspaceInvitation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLists", arg0, arg1)
-	ret0, _ := ret[0].([]db.GetListsRow)
+	ret := m.ctrl.Call(m, "CreateWorkspaceInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.WorkspaceInvitation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetLists indicates an expected call of GetLists.
-func (mr *MockStoreMockRecorder) GetLists(arg0, arg1 any) *gomock.Call {
+// CreateWorkspaceInvitation indicates an expected call of CreateWorkspaceInvitation.
+func (mr *MockStoreMockRecorder) CreateWorkspaceInvitation(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockStore)(nil).GetLists), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspaceInvitation", reflect.TypeOf((*MockStore)(nil).CreateWorkspaceInvitation), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// CreateWorkspaceTx mocks base method.
+func (m *MockStore) CreateWorkspaceTx(arg0 context.Context, arg1 db.CreateWorkspaceTxParams) (db.CreateWorkspaceTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "CreateWorkspaceTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CreateWorkspaceTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+// CreateWorkspaceTx indicates an expected call of CreateWorkspaceTx.
+func (mr *MockStoreMockRecorder) CreateWorkspaceTx(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspaceTx", reflect.TypeOf((*MockStore)(nil).CreateWorkspaceTx), arg0, arg1)
 }
 
-// GetTasks mocks base method.
-func (m *MockStore) GetTasks(arg0 context.Context, arg1 int32) ([]db.Task, error) {
+// DeleteEmailVerificationTokens mocks base method.
+func (m *MockStore) DeleteEmailVerificationTokens(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTasks", arg0, arg1)
-	ret0, _ := ret[0].([]db.Task)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteEmailVerificationTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetTasks indicates an expected call of GetTasks.
-func (mr *MockStoreMockRecorder) GetTasks(arg0, arg1 any) *gomock.Call {
+// DeleteEmailVerificationTokens indicates an expected call of DeleteEmailVerificationTokens.
+func (mr *MockStoreMockRecorder) DeleteEmailVerificationTokens(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockStore)(nil).GetTasks), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailVerificationTokens", reflect.TypeOf((*MockStore)(nil).DeleteEmailVerificationTokens), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// DeleteList mocks base method.
+func (m *MockStore) DeleteList(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockStoreMockRecorder) DeleteList(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockStore)(nil).DeleteList), arg0, arg1)
 }
 
-// RehashUser mocks base method.
-func (m *MockStore) RehashUser(arg0 context.Context, arg1 db.RehashUserParams) (db.User, error) {
+// DeleteListShare mocks base method.
+func (m *MockStore) DeleteListShare(arg0 context.Context, arg1 db.DeleteListShareParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RehashUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "DeleteListShare", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RehashUser indicates an expected call of RehashUser.
-func (mr *MockStoreMockRecorder) RehashUser(arg0, arg1 any) *gomock.Call {
+// DeleteListShare indicates an expected call of DeleteListShare.
+func (mr *MockStoreMockRecorder) DeleteListShare(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUser", reflect.TypeOf((*MockStore)(nil).RehashUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteListShare", reflect.TypeOf((*MockStore)(nil).DeleteListShare), arg0, arg1)
 }
 
-// ToggleTask mocks base method.
-func (m *MockStore) ToggleTask(arg0 context.Context, arg1 int32) error {
+// DeleteListShares mocks base method.
+func (m *MockStore) DeleteListShares(arg0 context.Context, arg1 int32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ToggleTask", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteListShares", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ToggleTask indicates an expected call of ToggleTask.
-func (mr *MockStoreMockRecorder) ToggleTask(arg0, arg1 any) *gomock.Call {
+// DeleteListShares indicates an expected call of DeleteListShares.
+func (mr *MockStoreMockRecorder) DeleteListShares(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleTask", reflect.TypeOf((*MockStore)(nil).ToggleTask), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteListShares", reflect.TypeOf((*MockStore)(nil).DeleteListShares), arg0, arg1)
 }
 
-// UpdateTaskText mocks base method.
-func (m *MockStore) UpdateTaskText(arg0 context.Context, arg1 db.UpdateTaskTextParams) error {
+// DeleteLoginAttempts mocks base method.
+func (m *MockStore) DeleteLoginAttempts(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTaskText", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateTaskText indicates an expected call of UpdateTaskText.
-func (mr *MockStoreMockRecorder) UpdateTaskText(arg0, arg1 any) *gomock.Call {
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockStoreMockRecorder) DeleteLoginAttempts(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskText", reflect.TypeOf((*MockStore)(nil).UpdateTaskText), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockStore)(nil).DeleteLoginAttempts), arg0, arg1)
+}
+
+// DeleteLoginChallenge mocks base method.
+func (m *MockStore) DeleteLoginChallenge(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLoginChallenge indicates an expected call of DeleteLoginChallenge.
+func (mr *MockStoreMockRecorder) DeleteLoginChallenge(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginChallenge", reflect.TypeOf((*MockStore)(nil).DeleteLoginChallenge), arg0, arg1)
+}
+
+// DeleteOwnedWorkspaces mocks base method.
+func (m *MockStore) DeleteOwnedWorkspaces(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOwnedWorkspaces", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOwnedWorkspaces indicates an expected call of DeleteOwnedWorkspaces.
+func (mr *MockStoreMockRecorder) DeleteOwnedWorkspaces(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnedWorkspaces", reflect.TypeOf((*MockStore)(nil).DeleteOwnedWorkspaces), arg0, arg1)
+}
+
+// DeletePasswordResetTokens mocks base method.
+func (m *MockStore) DeletePasswordResetTokens(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetTokens indicates an expected call of DeletePasswordResetTokens.
+func (mr *MockStoreMockRecorder) DeletePasswordResetTokens(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetTokens", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetTokens), arg0, arg1)
+}
+
+// DeletePersonalAccessToken mocks base method.
+func (m *MockStore) DeletePersonalAccessToken(arg0 context.Context, arg1 db.DeletePersonalAccessTokenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePersonalAccessToken indicates an expected call of DeletePersonalAccessToken.
+func (mr *MockStoreMockRecorder) DeletePersonalAccessToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).DeletePersonalAccessToken), arg0, arg1)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockStore) DeleteRecoveryCodes(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodes), arg0, arg1)
+}
+
+// DeleteTask mocks base method.
+func (m *MockStore) DeleteTask(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTask indicates an expected call of DeleteTask.
+func (mr *MockStoreMockRecorder) DeleteTask(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockStore)(nil).DeleteTask), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int32) (db.DeleteUserRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteUserRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserLists mocks base method.
+func (m *MockStore) DeleteUserLists(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserLists", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserLists indicates an expected call of DeleteUserLists.
+func (mr *MockStoreMockRecorder) DeleteUserLists(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserLists", reflect.TypeOf((*MockStore)(nil).DeleteUserLists), arg0, arg1)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockStore) DeleteUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockStoreMockRecorder) DeleteUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), arg0, arg1)
+}
+
+// DeleteUserTOTP mocks base method.
+func (m *MockStore) DeleteUserTOTP(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTP indicates an expected call of DeleteUserTOTP.
+func (mr *MockStoreMockRecorder) DeleteUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTP", reflect.TypeOf((*MockStore)(nil).DeleteUserTOTP), arg0, arg1)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 db.DeleteUserTxParams) (db.DeleteUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1)
+}
+
+// DeleteWorkspace mocks base method.
+func (m *MockStore) DeleteWorkspace(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspace indicates an expected call of DeleteWorkspace.
+func (mr *MockStoreMockRecorder) DeleteWorkspace(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspace", reflect.TypeOf((*MockStore)(nil).DeleteWorkspace), arg0, arg1)
+}
+
+// DeleteWorkspaceInvitation mocks base method.
+func (m *MockStore) DeleteWorkspaceInvitation(arg0 context.Context, arg1 db.DeleteWorkspaceInvitationParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceInvitation", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWorkspaceInvitation indicates an expected call of DeleteWorkspaceInvitation.
+func (mr *MockStoreMockRecorder) DeleteWorkspaceInvitation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceInvitation", reflect.TypeOf((*MockStore)(nil).DeleteWorkspaceInvitation), arg0, arg1)
+}
+
+// DeleteWorkspaceMember mocks base method.
+func (m *MockStore) DeleteWorkspaceMember(arg0 context.Context, arg1 db.DeleteWorkspaceMemberParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceMember", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWorkspaceMember indicates an expected call of DeleteWorkspaceMember.
+func (mr *MockStoreMockRecorder) DeleteWorkspaceMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceMember", reflect.TypeOf((*MockStore)(nil).DeleteWorkspaceMember), arg0, arg1)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), arg0, arg1)
+}
+
+// DisableUser mocks base method.
+func (m *MockStore) DisableUser(arg0 context.Context, arg1 int32) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockStoreMockRecorder) DisableUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockStore)(nil).DisableUser), arg0, arg1)
+}
+
+// DisableUserTx mocks base method.
+func (m *MockStore) DisableUserTx(arg0 context.Context, arg1 int32) (db.DisableUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DisableUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUserTx indicates an expected call of DisableUserTx.
+func (mr *MockStoreMockRecorder) DisableUserTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUserTx", reflect.TypeOf((*MockStore)(nil).DisableUserTx), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 db.EnableTOTPTxParams) (db.EnableTOTPTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(db.EnableTOTPTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1)
+}
+
+// EnableUser mocks base method.
+func (m *MockStore) EnableUser(arg0 context.Context, arg1 int32) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUser indicates an expected call of EnableUser.
+func (mr *MockStoreMockRecorder) EnableUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockStore)(nil).EnableUser), arg0, arg1)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockStore) EnableUserTOTP(arg0 context.Context, arg1 db.EnableUserTOTPParams) (db.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockStoreMockRecorder) EnableUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockStore)(nil).EnableUserTOTP), arg0, arg1)
+}
+
+// GetAuthoredLists mocks base method.
+func (m *MockStore) GetAuthoredLists(arg0 context.Context, arg1 int32) ([]db.GetAuthoredListsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthoredLists", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetAuthoredListsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthoredLists indicates an expected call of GetAuthoredLists.
+func (mr *MockStoreMockRecorder) GetAuthoredLists(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthoredLists", reflect.TypeOf((*MockStore)(nil).GetAuthoredLists), arg0, arg1)
+}
+
+// GetList mocks base method.
+func (m *MockStore) GetList(arg0 context.Context, arg1 int32) (db.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", arg0, arg1)
+	ret0, _ := ret[0].(db.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockStoreMockRecorder) GetList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockStore)(nil).GetList), arg0, arg1)
+}
+
+// GetListShares mocks base method.
+func (m *MockStore) GetListShares(arg0 context.Context, arg1 int32) ([]db.GetListSharesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListShares", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetListSharesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListShares indicates an expected call of GetListShares.
+func (mr *MockStoreMockRecorder) GetListShares(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListShares", reflect.TypeOf((*MockStore)(nil).GetListShares), arg0, arg1)
+}
+
+// GetLists mocks base method.
+func (m *MockStore) GetLists(arg0 context.Context, arg1 int32) ([]db.GetListsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetListsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockStoreMockRecorder) GetLists(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockStore)(nil).GetLists), arg0, arg1)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockStore) GetLoginAttempts(arg0 context.Context, arg1 string) (db.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockStoreMockRecorder) GetLoginAttempts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockStore)(nil).GetLoginAttempts), arg0, arg1)
+}
+
+// GetNextListPosition mocks base method.
+func (m *MockStore) GetNextListPosition(arg0 context.Context, arg1 db.GetNextListPositionParams) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextListPosition", arg0, arg1)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextListPosition indicates an expected call of GetNextListPosition.
+func (mr *MockStoreMockRecorder) GetNextListPosition(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextListPosition", reflect.TypeOf((*MockStore)(nil).GetNextListPosition), arg0, arg1)
+}
+
+// GetNextTaskPosition mocks base method.
+func (m *MockStore) GetNextTaskPosition(arg0 context.Context, arg1 db.GetNextTaskPositionParams) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextTaskPosition", arg0, arg1)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextTaskPosition indicates an expected call of GetNextTaskPosition.
+func (mr *MockStoreMockRecorder) GetNextTaskPosition(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextTaskPosition", reflect.TypeOf((*MockStore)(nil).GetNextTaskPosition), arg0, arg1)
+}
+
+// GetPersonalAccessTokenByHash mocks base method.
+func (m *MockStore) GetPersonalAccessTokenByHash(arg0 context.Context, arg1 []byte) (db.GetPersonalAccessTokenByHashRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokenByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.GetPersonalAccessTokenByHashRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokenByHash indicates an expected call of GetPersonalAccessTokenByHash.
+func (mr *MockStoreMockRecorder) GetPersonalAccessTokenByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokenByHash", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessTokenByHash), arg0, arg1)
+}
+
+// GetPersonalAccessTokens mocks base method.
+func (m *MockStore) GetPersonalAccessTokens(arg0 context.Context, arg1 int32) ([]db.GetPersonalAccessTokensRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetPersonalAccessTokensRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokens indicates an expected call of GetPersonalAccessTokens.
+func (mr *MockStoreMockRecorder) GetPersonalAccessTokens(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokens", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessTokens), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSharedList mocks base method.
+func (m *MockStore) GetSharedList(arg0 context.Context, arg1 []byte) (db.GetSharedListRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedList", arg0, arg1)
+	ret0, _ := ret[0].(db.GetSharedListRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharedList indicates an expected call of GetSharedList.
+func (mr *MockStoreMockRecorder) GetSharedList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedList", reflect.TypeOf((*MockStore)(nil).GetSharedList), arg0, arg1)
+}
+
+// GetTask mocks base method.
+func (m *MockStore) GetTask(arg0 context.Context, arg1 db.GetTaskParams) (db.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTask", arg0, arg1)
+	ret0, _ := ret[0].(db.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTask indicates an expected call of GetTask.
+func (mr *MockStoreMockRecorder) GetTask(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockStore)(nil).GetTask), arg0, arg1)
+}
+
+// GetTaskTree mocks base method.
+func (m *MockStore) GetTaskTree(arg0 context.Context, arg1 db.GetTaskTreeParams) ([]db.GetTaskTreeRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskTree", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetTaskTreeRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskTree indicates an expected call of GetTaskTree.
+func (mr *MockStoreMockRecorder) GetTaskTree(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskTree", reflect.TypeOf((*MockStore)(nil).GetTaskTree), arg0, arg1)
+}
+
+// GetTasks mocks base method.
+func (m *MockStore) GetTasks(arg0 context.Context, arg1 int32) ([]db.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTasks indicates an expected call of GetTasks.
+func (mr *MockStoreMockRecorder) GetTasks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockStore)(nil).GetTasks), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(arg0 context.Context, arg1 int32) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), arg0, arg1)
+}
+
+// GetUserByIdentity mocks base method.
+func (m *MockStore) GetUserByIdentity(arg0 context.Context, arg1 db.GetUserByIdentityParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByIdentity indicates an expected call of GetUserByIdentity.
+func (mr *MockStoreMockRecorder) GetUserByIdentity(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIdentity", reflect.TypeOf((*MockStore)(nil).GetUserByIdentity), arg0, arg1)
+}
+
+// GetUserSession mocks base method.
+func (m *MockStore) GetUserSession(arg0 context.Context, arg1 db.GetUserSessionParams) (db.GetUserSessionRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSession", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserSessionRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSession indicates an expected call of GetUserSession.
+func (mr *MockStoreMockRecorder) GetUserSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSession", reflect.TypeOf((*MockStore)(nil).GetUserSession), arg0, arg1)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockStore) GetUserSessions(arg0 context.Context, arg1 db.GetUserSessionsParams) ([]db.GetUserSessionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetUserSessionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockStoreMockRecorder) GetUserSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockStore)(nil).GetUserSessions), arg0, arg1)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockStore) GetUserTOTP(arg0 context.Context, arg1 int32) (db.UserTotp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTotp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockStoreMockRecorder) GetUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockStore)(nil).GetUserTOTP), arg0, arg1)
+}
+
+// GetUserUsage mocks base method.
+func (m *MockStore) GetUserUsage(arg0 context.Context, arg1 int32) (db.GetUserUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserUsage", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserUsage indicates an expected call of GetUserUsage.
+func (mr *MockStoreMockRecorder) GetUserUsage(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserUsage", reflect.TypeOf((*MockStore)(nil).GetUserUsage), arg0, arg1)
+}
+
+// GetUserWorkspaceInvitations mocks base method.
+func (m *MockStore) GetUserWorkspaceInvitations(arg0 context.Context, arg1 int32) ([]db.GetUserWorkspaceInvitationsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserWorkspaceInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetUserWorkspaceInvitationsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserWorkspaceInvitations indicates an expected call of GetUserWorkspaceInvitations.
+func (mr *MockStoreMockRecorder) GetUserWorkspaceInvitations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWorkspaceInvitations", reflect.TypeOf((*MockStore)(nil).GetUserWorkspaceInvitations), arg0, arg1)
+}
+
+// GetUserWorkspaces mocks base method.
+func (m *MockStore) GetUserWorkspaces(arg0 context.Context, arg1 int32) ([]db.GetUserWorkspacesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserWorkspaces", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetUserWorkspacesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserWorkspaces indicates an expected call of GetUserWorkspaces.
+func (mr *MockStoreMockRecorder) GetUserWorkspaces(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWorkspaces", reflect.TypeOf((*MockStore)(nil).GetUserWorkspaces), arg0, arg1)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockStore) GetWorkspace(arg0 context.Context, arg1 int32) (db.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(db.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockStoreMockRecorder) GetWorkspace(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockStore)(nil).GetWorkspace), arg0, arg1)
+}
+
+// GetWorkspaceInvitations mocks base method.
+func (m *MockStore) GetWorkspaceInvitations(arg0 context.Context, arg1 int32) ([]db.WorkspaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.WorkspaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceInvitations indicates an expected call of GetWorkspaceInvitations.
+func (mr *MockStoreMockRecorder) GetWorkspaceInvitations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceInvitations", reflect.TypeOf((*MockStore)(nil).GetWorkspaceInvitations), arg0, arg1)
+}
+
+// GetWorkspaceLists mocks base method.
+func (m *MockStore) GetWorkspaceLists(arg0 context.Context, arg1 int32) ([]db.GetWorkspaceListsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceLists", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetWorkspaceListsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceLists indicates an expected call of GetWorkspaceLists.
+func (mr *MockStoreMockRecorder) GetWorkspaceLists(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceLists", reflect.TypeOf((*MockStore)(nil).GetWorkspaceLists), arg0, arg1)
+}
+
+// GetWorkspaceMember mocks base method.
+func (m *MockStore) GetWorkspaceMember(arg0 context.Context, arg1 db.GetWorkspaceMemberParams) (db.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceMember", arg0, arg1)
+	ret0, _ := ret[0].(db.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceMember indicates an expected call of GetWorkspaceMember.
+func (mr *MockStoreMockRecorder) GetWorkspaceMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceMember", reflect.TypeOf((*MockStore)(nil).GetWorkspaceMember), arg0, arg1)
+}
+
+// GetWorkspaceMembers mocks base method.
+func (m *MockStore) GetWorkspaceMembers(arg0 context.Context, arg1 int32) ([]db.GetWorkspaceMembersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetWorkspaceMembersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceMembers indicates an expected call of GetWorkspaceMembers.
+func (mr *MockStoreMockRecorder) GetWorkspaceMembers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceMembers", reflect.TypeOf((*MockStore)(nil).GetWorkspaceMembers), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// LockList mocks base method.
+func (m *MockStore) LockList(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockList", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockList indicates an expected call of LockList.
+func (mr *MockStoreMockRecorder) LockList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockList", reflect.TypeOf((*MockStore)(nil).LockList), arg0, arg1)
+}
+
+// LockUser mocks base method.
+func (m *MockStore) LockUser(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockStoreMockRecorder) LockUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockStore)(nil).LockUser), arg0, arg1)
+}
+
+// LockWorkspace mocks base method.
+func (m *MockStore) LockWorkspace(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockWorkspace indicates an expected call of LockWorkspace.
+func (mr *MockStoreMockRecorder) LockWorkspace(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockWorkspace", reflect.TypeOf((*MockStore)(nil).LockWorkspace), arg0, arg1)
+}
+
+// MoveListTx mocks base method.
+func (m *MockStore) MoveListTx(arg0 context.Context, arg1 db.MoveListTxParams) (db.MoveListTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveListTx", arg0, arg1)
+	ret0, _ := ret[0].(db.MoveListTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveListTx indicates an expected call of MoveListTx.
+func (mr *MockStoreMockRecorder) MoveListTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveListTx", reflect.TypeOf((*MockStore)(nil).MoveListTx), arg0, arg1)
+}
+
+// MoveTaskTx mocks base method.
+func (m *MockStore) MoveTaskTx(arg0 context.Context, arg1 db.MoveTaskTxParams) (db.MoveTaskTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTaskTx", arg0, arg1)
+	ret0, _ := ret[0].(db.MoveTaskTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveTaskTx indicates an expected call of MoveTaskTx.
+func (mr *MockStoreMockRecorder) MoveTaskTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTaskTx", reflect.TypeOf((*MockStore)(nil).MoveTaskTx), arg0, arg1)
+}
+
+// ReassignWorkspaceLists mocks base method.
+func (m *MockStore) ReassignWorkspaceLists(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignWorkspaceLists", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReassignWorkspaceLists indicates an expected call of ReassignWorkspaceLists.
+func (mr *MockStoreMockRecorder) ReassignWorkspaceLists(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignWorkspaceLists", reflect.TypeOf((*MockStore)(nil).ReassignWorkspaceLists), arg0, arg1)
+}
+
+// RebalanceListPositions mocks base method.
+func (m *MockStore) RebalanceListPositions(arg0 context.Context, arg1 db.RebalanceListPositionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebalanceListPositions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebalanceListPositions indicates an expected call of RebalanceListPositions.
+func (mr *MockStoreMockRecorder) RebalanceListPositions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebalanceListPositions", reflect.TypeOf((*MockStore)(nil).RebalanceListPositions), arg0, arg1)
+}
+
+// RebalanceTaskPositions mocks base method.
+func (m *MockStore) RebalanceTaskPositions(arg0 context.Context, arg1 db.RebalanceTaskPositionsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebalanceTaskPositions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebalanceTaskPositions indicates an expected call of RebalanceTaskPositions.
+func (mr *MockStoreMockRecorder) RebalanceTaskPositions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebalanceTaskPositions", reflect.TypeOf((*MockStore)(nil).RebalanceTaskPositions), arg0, arg1)
+}
+
+// RehashUser mocks base method.
+func (m *MockStore) RehashUser(arg0 context.Context, arg1 db.RehashUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashUser indicates an expected call of RehashUser.
+func (mr *MockStoreMockRecorder) RehashUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUser", reflect.TypeOf((*MockStore)(nil).RehashUser), arg0, arg1)
+}
+
+// ReleaseLoginAttempt mocks base method.
+func (m *MockStore) ReleaseLoginAttempt(arg0 context.Context, arg1 db.ReleaseLoginAttemptParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockStoreMockRecorder) ReleaseLoginAttempt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockStore)(nil).ReleaseLoginAttempt), arg0, arg1)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ResetPasswordTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// RotateSession mocks base method.
+func (m *MockStore) RotateSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoreMockRecorder) RotateSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStore)(nil).RotateSession), arg0, arg1)
+}
+
+// RotateSessionTx mocks base method.
+func (m *MockStore) RotateSessionTx(arg0 context.Context, arg1 db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSessionTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RotateSessionTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSessionTx indicates an expected call of RotateSessionTx.
+func (mr *MockStoreMockRecorder) RotateSessionTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionTx", reflect.TypeOf((*MockStore)(nil).RotateSessionTx), arg0, arg1)
+}
+
+// SetListPosition mocks base method.
+func (m *MockStore) SetListPosition(arg0 context.Context, arg1 db.SetListPositionParams) (db.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListPosition", arg0, arg1)
+	ret0, _ := ret[0].(db.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetListPosition indicates an expected call of SetListPosition.
+func (mr *MockStoreMockRecorder) SetListPosition(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListPosition", reflect.TypeOf((*MockStore)(nil).SetListPosition), arg0, arg1)
+}
+
+// SetListWorkspace mocks base method.
+func (m *MockStore) SetListWorkspace(arg0 context.Context, arg1 db.SetListWorkspaceParams) (db.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(db.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetListWorkspace indicates an expected call of SetListWorkspace.
+func (mr *MockStoreMockRecorder) SetListWorkspace(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListWorkspace", reflect.TypeOf((*MockStore)(nil).SetListWorkspace), arg0, arg1)
+}
+
+// SetListWorkspaceTx mocks base method.
+func (m *MockStore) SetListWorkspaceTx(arg0 context.Context, arg1 db.SetListWorkspaceParams) (db.SetListWorkspaceTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListWorkspaceTx", arg0, arg1)
+	ret0, _ := ret[0].(db.SetListWorkspaceTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetListWorkspaceTx indicates an expected call of SetListWorkspaceTx.
+func (mr *MockStoreMockRecorder) SetListWorkspaceTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListWorkspaceTx", reflect.TypeOf((*MockStore)(nil).SetListWorkspaceTx), arg0, arg1)
+}
+
+// SetTaskParent mocks base method.
+func (m *MockStore) SetTaskParent(arg0 context.Context, arg1 db.SetTaskParentParams) (db.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTaskParent", arg0, arg1)
+	ret0, _ := ret[0].(db.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTaskParent indicates an expected call of SetTaskParent.
+func (mr *MockStoreMockRecorder) SetTaskParent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTaskParent", reflect.TypeOf((*MockStore)(nil).SetTaskParent), arg0, arg1)
+}
+
+// SetTaskPosition mocks base method.
+func (m *MockStore) SetTaskPosition(arg0 context.Context, arg1 db.SetTaskPositionParams) (db.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTaskPosition", arg0, arg1)
+	ret0, _ := ret[0].(db.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTaskPosition indicates an expected call of SetTaskPosition.
+func (mr *MockStoreMockRecorder) SetTaskPosition(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTaskPosition", reflect.TypeOf((*MockStore)(nil).SetTaskPosition), arg0, arg1)
+}
+
+// SetUserHash mocks base method.
+func (m *MockStore) SetUserHash(arg0 context.Context, arg1 db.SetUserHashParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserHash", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserHash indicates an expected call of SetUserHash.
+func (mr *MockStoreMockRecorder) SetUserHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserHash", reflect.TypeOf((*MockStore)(nil).SetUserHash), arg0, arg1)
+}
+
+// SetUserRole mocks base method.
+func (m *MockStore) SetUserRole(arg0 context.Context, arg1 db.SetUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockStoreMockRecorder) SetUserRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockStore)(nil).SetUserRole), arg0, arg1)
+}
+
+// SetVerifiedUserEmail mocks base method.
+func (m *MockStore) SetVerifiedUserEmail(arg0 context.Context, arg1 db.SetVerifiedUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVerifiedUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVerifiedUserEmail indicates an expected call of SetVerifiedUserEmail.
+func (mr *MockStoreMockRecorder) SetVerifiedUserEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVerifiedUserEmail", reflect.TypeOf((*MockStore)(nil).SetVerifiedUserEmail), arg0, arg1)
+}
+
+// TakeLoginAttempt mocks base method.
+func (m *MockStore) TakeLoginAttempt(arg0 context.Context, arg1 db.TakeLoginAttemptParams) (db.TakeLoginAttemptRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.TakeLoginAttemptRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeLoginAttempt indicates an expected call of TakeLoginAttempt.
+func (mr *MockStoreMockRecorder) TakeLoginAttempt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeLoginAttempt", reflect.TypeOf((*MockStore)(nil).TakeLoginAttempt), arg0, arg1)
+}
+
+// TakeLoginChallengeAttempt mocks base method.
+func (m *MockStore) TakeLoginChallengeAttempt(arg0 context.Context, arg1 db.TakeLoginChallengeAttemptParams) (db.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeLoginChallengeAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeLoginChallengeAttempt indicates an expected call of TakeLoginChallengeAttempt.
+func (mr *MockStoreMockRecorder) TakeLoginChallengeAttempt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeLoginChallengeAttempt", reflect.TypeOf((*MockStore)(nil).TakeLoginChallengeAttempt), arg0, arg1)
+}
+
+// TakeOidcAuthRequest mocks base method.
+func (m *MockStore) TakeOidcAuthRequest(arg0 context.Context, arg1 string) (db.OidcAuthRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeOidcAuthRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcAuthRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeOidcAuthRequest indicates an expected call of TakeOidcAuthRequest.
+func (mr *MockStoreMockRecorder) TakeOidcAuthRequest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeOidcAuthRequest", reflect.TypeOf((*MockStore)(nil).TakeOidcAuthRequest), arg0, arg1)
+}
+
+// TakeWorkspaceInvitation mocks base method.
+func (m *MockStore) TakeWorkspaceInvitation(arg0 context.Context, arg1 db.TakeWorkspaceInvitationParams) (db.WorkspaceInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeWorkspaceInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.WorkspaceInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeWorkspaceInvitation indicates an expected call of TakeWorkspaceInvitation.
+func (mr *MockStoreMockRecorder) TakeWorkspaceInvitation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeWorkspaceInvitation", reflect.TypeOf((*MockStore)(nil).TakeWorkspaceInvitation), arg0, arg1)
+}
+
+// ToggleTask mocks base method.
+func (m *MockStore) ToggleTask(arg0 context.Context, arg1 int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToggleTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ToggleTask indicates an expected call of ToggleTask.
+func (mr *MockStoreMockRecorder) ToggleTask(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleTask", reflect.TypeOf((*MockStore)(nil).ToggleTask), arg0, arg1)
+}
+
+// UpdateList mocks base method.
+func (m *MockStore) UpdateList(arg0 context.Context, arg1 db.UpdateListParams) (db.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateList", arg0, arg1)
+	ret0, _ := ret[0].(db.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateList indicates an expected call of UpdateList.
+func (mr *MockStoreMockRecorder) UpdateList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateList", reflect.TypeOf((*MockStore)(nil).UpdateList), arg0, arg1)
+}
+
+// UpdatePersonalAccessTokenUsage mocks base method.
+func (m *MockStore) UpdatePersonalAccessTokenUsage(arg0 context.Context, arg1 db.UpdatePersonalAccessTokenUsageParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePersonalAccessTokenUsage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePersonalAccessTokenUsage indicates an expected call of UpdatePersonalAccessTokenUsage.
+func (mr *MockStoreMockRecorder) UpdatePersonalAccessTokenUsage(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePersonalAccessTokenUsage", reflect.TypeOf((*MockStore)(nil).UpdatePersonalAccessTokenUsage), arg0, arg1)
+}
+
+// UpdateProfileTx mocks base method.
+func (m *MockStore) UpdateProfileTx(arg0 context.Context, arg1 db.UpdateUserProfileParams) (db.UpdateProfileTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfileTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateProfileTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfileTx indicates an expected call of UpdateProfileTx.
+func (mr *MockStoreMockRecorder) UpdateProfileTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfileTx", reflect.TypeOf((*MockStore)(nil).UpdateProfileTx), arg0, arg1)
+}
+
+// UpdateTaskText mocks base method.
+func (m *MockStore) UpdateTaskText(arg0 context.Context, arg1 db.UpdateTaskTextParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskText", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTaskText indicates an expected call of UpdateTaskText.
+func (mr *MockStoreMockRecorder) UpdateTaskText(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskText", reflect.TypeOf((*MockStore)(nil).UpdateTaskText), arg0, arg1)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockStore) UpdateUserProfile(arg0 context.Context, arg1 db.UpdateUserProfileParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockStoreMockRecorder) UpdateUserProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockStore)(nil).UpdateUserProfile), arg0, arg1)
+}
+
+// UpdateWorkspace mocks base method.
+func (m *MockStore) UpdateWorkspace(arg0 context.Context, arg1 db.UpdateWorkspaceParams) (db.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(db.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWorkspace indicates an expected call of UpdateWorkspace.
+func (mr *MockStoreMockRecorder) UpdateWorkspace(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspace", reflect.TypeOf((*MockStore)(nil).UpdateWorkspace), arg0, arg1)
+}
+
+// UpdateWorkspaceMemberRole mocks base method.
+func (m *MockStore) UpdateWorkspaceMemberRole(arg0 context.Context, arg1 db.UpdateWorkspaceMemberRoleParams) (db.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceMemberRole", arg0, arg1)
+	ret0, _ := ret[0].(db.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWorkspaceMemberRole indicates an expected call of UpdateWorkspaceMemberRole.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceMemberRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceMemberRole", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceMemberRole), arg0, arg1)
+}
+
+// UseEmailVerificationToken mocks base method.
+func (m *MockStore) UseEmailVerificationToken(arg0 context.Context, arg1 []byte) (db.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailVerificationToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailVerificationToken indicates an expected call of UseEmailVerificationToken.
+func (mr *MockStoreMockRecorder) UseEmailVerificationToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailVerificationToken", reflect.TypeOf((*MockStore)(nil).UseEmailVerificationToken), arg0, arg1)
+}
+
+// UsePasswordResetToken mocks base method.
+func (m *MockStore) UsePasswordResetToken(arg0 context.Context, arg1 []byte) (db.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordResetToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordResetToken indicates an expected call of UsePasswordResetToken.
+func (mr *MockStoreMockRecorder) UsePasswordResetToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordResetToken", reflect.TypeOf((*MockStore)(nil).UsePasswordResetToken), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStore) UseTOTPStep(arg0 context.Context, arg1 db.UseTOTPStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStoreMockRecorder) UseTOTPStep(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStore)(nil).UseTOTPStep), arg0, arg1)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockStore) VerifyEmailTx(arg0 context.Context, arg1 []byte) (db.VerifyEmailTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(db.VerifyEmailTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTx indicates an expected call of VerifyEmailTx.
+func (mr *MockStoreMockRecorder) VerifyEmailTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockStore)(nil).VerifyEmailTx), arg0, arg1)
 }
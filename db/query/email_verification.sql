@@ -0,0 +1,18 @@
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    token_hash,
+    user_id,
+    email,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseEmailVerificationToken :one
+DELETE FROM email_verification_tokens
+WHERE token_hash = $1 and expires_at > now()
+RETURNING *;
+
+-- name: DeleteEmailVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
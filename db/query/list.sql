@@ -1,14 +1,97 @@
 -- name: GetLists :many
-SELECT id, header FROM lists
-WHERE author = $1;
+SELECT id, header, description, color, icon, archived FROM lists
+WHERE author = $1 and workspace_id IS NULL
+ORDER BY position, id;
+
+-- name: GetWorkspaceLists :many
+SELECT id, header, description, color, icon, archived FROM lists
+WHERE workspace_id = sqlc.arg(workspace_id)::int
+ORDER BY position, id;
+
+-- name: GetAuthoredLists :many
+-- Returns personal lists of the user and lists the user has authored in workspaces
+SELECT id, header, description, color, icon, archived, workspace_id FROM lists
+WHERE author = $1
+ORDER BY workspace_id NULLS FIRST, position, id;
+
+-- name: GetList :one
+SELECT * FROM lists
+WHERE id = $1;
+
+-- name: LockList :exec
+-- Serializes changes of the list tasks order until the end of transaction
+SELECT id FROM lists
+WHERE id = $1
+FOR NO KEY UPDATE;
 
 -- name: AddList :one
 INSERT INTO lists (
-	author, header
+	author, header, position
+) VALUES (
+	$1, $2, (SELECT COALESCE(max(position), 0) + 1024 FROM lists WHERE author = $1 and workspace_id IS NULL)
+) RETURNING *;
+
+-- name: AddWorkspaceList :one
+INSERT INTO lists (
+	author, workspace_id, header, position
 ) VALUES (
-	$1, $2
+	sqlc.arg(author), sqlc.arg(workspace_id)::int, sqlc.arg(header),
+	(SELECT COALESCE(max(position), 0) + 1024 FROM lists WHERE workspace_id = sqlc.arg(workspace_id)::int)
 ) RETURNING *;
 
+-- name: UpdateList :one
+UPDATE lists
+	set header = COALESCE(sqlc.narg(header), header),
+	description = COALESCE(sqlc.narg(description), description),
+	color = COALESCE(sqlc.narg(color), color),
+	icon = COALESCE(sqlc.narg(icon), icon),
+	archived = COALESCE(sqlc.narg(archived), archived)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetListWorkspace :one
+-- List is placed after the last list of its new space
+UPDATE lists
+	set workspace_id = $2, author = $3, position = (
+		SELECT COALESCE(max(other.position), 0) + 1024 FROM lists AS other
+		WHERE other.workspace_id IS NOT DISTINCT FROM $2 and ($2 IS NOT NULL or other.author = $3)
+	)
+WHERE id = $1
+RETURNING *;
+
+-- name: GetNextListPosition :one
+-- Returns position of the list following the given one by position and id among lists of the same space
+SELECT position FROM lists
+WHERE workspace_id IS NOT DISTINCT FROM sqlc.narg(workspace_id) and (workspace_id IS NOT NULL or author = sqlc.arg(author))
+	and id <> sqlc.arg(id) and (sqlc.narg(after)::float8 IS NULL or (position, id) > (sqlc.narg(after)::float8, sqlc.arg(after_id)::int))
+ORDER BY position, id
+LIMIT 1;
+
+-- name: SetListPosition :one
+UPDATE lists
+	set position = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: RebalanceListPositions :exec
+UPDATE lists
+	set position = ranked.rank * 1024
+FROM (
+	SELECT id, row_number() OVER (ORDER BY position, id) AS rank FROM lists
+	WHERE workspace_id IS NOT DISTINCT FROM sqlc.narg(workspace_id) and (workspace_id IS NOT NULL or author = sqlc.arg(author))
+) AS ranked
+WHERE lists.id = ranked.id;
+
+-- name: ReassignWorkspaceLists :exec
+UPDATE lists
+	set author = workspace_members.user_id
+FROM workspace_members
+WHERE lists.author = $1 and workspace_members.workspace_id = lists.workspace_id and workspace_members.role = 'owner';
+
 -- name: DeleteList :exec
 DELETE FROM lists
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: DeleteUserLists :exec
+DELETE FROM lists
+WHERE author = $1;
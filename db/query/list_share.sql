@@ -0,0 +1,29 @@
+-- name: CreateListShare :one
+INSERT INTO list_shares (
+    id,
+    list_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetListShares :many
+SELECT id, list_id, expires_at, created_at FROM list_shares
+WHERE list_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetSharedList :one
+SELECT lists.id, lists.header, lists.description, lists.color, lists.icon, lists.archived FROM list_shares
+JOIN lists ON lists.id = list_shares.list_id
+JOIN users ON users.id = lists.author
+WHERE token_hash = $1 and (expires_at IS NULL or expires_at > now()) and users.disabled_at IS NULL
+LIMIT 1;
+
+-- name: DeleteListShare :execrows
+DELETE FROM list_shares
+WHERE id = $1 and list_id = $2;
+
+-- name: DeleteListShares :exec
+DELETE FROM list_shares
+WHERE list_id = $1;
@@ -0,0 +1,59 @@
+-- name: AddLoginFailure :one
+INSERT INTO login_attempts (
+    key,
+    failures,
+    last_failed_at
+) VALUES (
+    $1, 1, $2
+)
+ON CONFLICT (key) DO UPDATE
+    set failures = CASE
+            WHEN login_attempts.last_failed_at < sqlc.arg(since) THEN 1
+            ELSE login_attempts.failures + 1
+        END,
+        last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: GetLoginAttempts :one
+SELECT * FROM login_attempts
+WHERE key = $1 LIMIT 1;
+
+-- name: DeleteLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = $1;
+
+-- name: CreateLoginAttempts :exec
+INSERT INTO login_attempts (
+    key,
+    failures,
+    last_failed_at
+) VALUES (
+    $1, 0, $2
+)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: TakeLoginAttempt :one
+-- Registers failure in advance of the attempt and returns attempts before it
+UPDATE login_attempts
+    set failures = CASE
+            WHEN previous.last_failed_at < sqlc.arg(since)::timestamptz THEN 1
+            ELSE previous.failures + 1
+        END,
+        last_failed_at = sqlc.arg(taken_at)
+FROM (
+    SELECT key, failures, last_failed_at FROM login_attempts
+    WHERE key = sqlc.arg(key)
+    FOR UPDATE
+) AS previous
+WHERE login_attempts.key = previous.key
+RETURNING previous.failures, previous.last_failed_at;
+
+-- name: ReleaseLoginAttempt :exec
+-- Undoes failure registered in advance of the attempt, its time is restored unless there was a later attempt
+UPDATE login_attempts
+    set failures = GREATEST(failures - 1, 0),
+        last_failed_at = CASE
+            WHEN last_failed_at = sqlc.arg(taken_at) THEN sqlc.arg(previous_failed_at)::timestamptz
+            ELSE last_failed_at
+        END
+WHERE key = sqlc.arg(key);
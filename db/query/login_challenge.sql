@@ -0,0 +1,19 @@
+-- name: CreateLoginChallenge :one
+INSERT INTO login_challenges (
+    id,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: TakeLoginChallengeAttempt :one
+-- Reserves attempt to answer the challenge, returns no rows if challenge is used, expired or out of attempts
+UPDATE login_challenges
+    set attempts = attempts + 1
+WHERE id = $1 and user_id = $2 and attempts < sqlc.arg(max_attempts) and expires_at > now()
+RETURNING *;
+
+-- name: DeleteLoginChallenge :execrows
+DELETE FROM login_challenges
+WHERE id = $1;
@@ -0,0 +1,15 @@
+-- name: CreateOidcAuthRequest :one
+INSERT INTO oidc_auth_requests (
+    state,
+    nonce,
+    code_verifier,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: TakeOidcAuthRequest :one
+DELETE FROM oidc_auth_requests
+WHERE state = $1
+RETURNING *;
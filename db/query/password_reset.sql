@@ -0,0 +1,18 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+    token_hash,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UsePasswordResetToken :one
+UPDATE password_reset_tokens
+    set used_at = now()
+WHERE token_hash = $1 and used_at IS NULL and expires_at > now()
+RETURNING *;
+
+-- name: DeletePasswordResetTokens :exec
+DELETE FROM password_reset_tokens
+WHERE user_id = $1;
@@ -0,0 +1,31 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    id,
+    user_id,
+    name,
+    token_hash,
+    expires_at,
+    scopes,
+    list_ids
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT personal_access_tokens.*, users.username, users.disabled_at FROM personal_access_tokens
+JOIN users ON users.id = personal_access_tokens.user_id
+WHERE token_hash = $1 LIMIT 1;
+
+-- name: GetPersonalAccessTokens :many
+SELECT id, name, expires_at, last_used_at, last_used_ip, created_at, scopes, list_ids FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpdatePersonalAccessTokenUsage :exec
+UPDATE personal_access_tokens
+    set last_used_at = now(), last_used_ip = sqlc.arg(last_used_ip)::text
+WHERE id = $1;
+
+-- name: DeletePersonalAccessToken :execrows
+DELETE FROM personal_access_tokens
+WHERE id = $1 and user_id = $2;
@@ -6,11 +6,57 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    family_id,
+    parent_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: RotateSession :one
+UPDATE sessions
+    set is_rotated = true
+WHERE id = $1 and is_rotated = false
+RETURNING *;
+
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+    set is_blocked = true
+WHERE family_id = $1;
+
+-- name: BlockUserSession :one
+UPDATE sessions
+    set is_blocked = true
+WHERE id = $1 and username = $2
+RETURNING *;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+    set is_blocked = true
+WHERE username = $1 and is_blocked = false;
+
+-- name: BlockOtherUserSessions :exec
+UPDATE sessions
+    set is_blocked = true
+WHERE username = $1 and is_blocked = false and family_id IS DISTINCT FROM (
+    SELECT family_id FROM sessions WHERE id = sqlc.arg(current_session_id)
+);
+
+-- name: GetUserSession :one
+SELECT id, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE id = $1 and username = $2 and is_rotated = false LIMIT 1;
+
+-- name: GetUserSessions :many
+SELECT id, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE username = $1 and is_rotated = false
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
+WHERE username = $1;
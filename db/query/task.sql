@@ -1,12 +1,34 @@
 -- name: GetTasks :many
 SELECT * FROM tasks
-WHERE list_id = $1;
+WHERE list_id = $1
+ORDER BY parent_task NULLS FIRST, position, id;
+
+-- name: GetTask :one
+SELECT * FROM tasks
+WHERE id = $1 and list_id = $2;
+
+-- name: GetTaskTree :many
+-- Returns tasks of the subtree ordered by depth, so parent goes before its children.
+-- Whole list is returned if root task is null
+WITH RECURSIVE tree AS (
+	SELECT tasks.id, tasks.list_id, tasks.parent_task, tasks.task, tasks.complete, tasks.position, 0 AS depth, ARRAY[tasks.id] AS path FROM tasks
+	WHERE tasks.list_id = sqlc.arg(list_id) and (
+		(sqlc.narg(root_task)::int IS NULL and tasks.parent_task IS NULL) or tasks.id = sqlc.narg(root_task)::int
+	)
+	UNION ALL
+	SELECT tasks.id, tasks.list_id, tasks.parent_task, tasks.task, tasks.complete, tasks.position, tree.depth + 1, tree.path || tasks.id FROM tasks
+	JOIN tree ON tasks.parent_task = tree.id and tasks.list_id = tree.list_id
+	-- path stops recursion even if tasks have formed a cycle
+	WHERE tasks.id <> ALL(tree.path)
+)
+SELECT id, list_id, parent_task, task, complete, position, depth FROM tree
+ORDER BY depth, position, id;
 
 -- name: AddTask :one
 INSERT INTO tasks (
-	list_id, parent_task, task
+	list_id, parent_task, task, position
 ) VALUES (
-	$1, $2, $3
+	$1, $2, $3, (SELECT COALESCE(max(position), 0) + 1024 FROM tasks WHERE list_id = $1 and parent_task IS NOT DISTINCT FROM $2)
 ) RETURNING *;
 
 -- name: ToggleTask :exec
@@ -21,6 +43,39 @@ UPDATE tasks
 WHERE id = $1
 RETURNING *;
 
+-- name: SetTaskParent :one
+-- Task is placed after the last child of its new parent
+UPDATE tasks
+	set parent_task = sqlc.narg(parent_task), position = (
+		SELECT COALESCE(max(siblings.position), 0) + 1024 FROM tasks AS siblings
+		WHERE siblings.list_id = tasks.list_id and siblings.parent_task IS NOT DISTINCT FROM sqlc.narg(parent_task)
+	)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetNextTaskPosition :one
+-- Returns position of the task following the given one by position and id among tasks of the same parent
+SELECT position FROM tasks
+WHERE list_id = sqlc.arg(list_id) and parent_task IS NOT DISTINCT FROM sqlc.narg(parent_task)
+	and id <> sqlc.arg(id) and (sqlc.narg(after)::float8 IS NULL or (position, id) > (sqlc.narg(after)::float8, sqlc.arg(after_id)::int))
+ORDER BY position, id
+LIMIT 1;
+
+-- name: SetTaskPosition :one
+UPDATE tasks
+	set position = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: RebalanceTaskPositions :exec
+UPDATE tasks
+	set position = ranked.rank * 1024
+FROM (
+	SELECT id, row_number() OVER (ORDER BY position, id) AS rank FROM tasks
+	WHERE list_id = sqlc.arg(list_id) and parent_task IS NOT DISTINCT FROM sqlc.narg(parent_task)
+) AS ranked
+WHERE tasks.id = ranked.id;
+
 -- name: DeleteTask :exec
 DELETE FROM tasks
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
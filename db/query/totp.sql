@@ -0,0 +1,47 @@
+-- name: CreateUserTOTP :one
+INSERT INTO user_totp (
+    user_id,
+    secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+    set secret = EXCLUDED.secret, created_at = now()
+    WHERE user_totp.is_enabled = false
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE user_id = $1 LIMIT 1;
+
+-- name: EnableUserTOTP :one
+UPDATE user_totp
+    set is_enabled = true, last_used_step = $2
+WHERE user_id = $1 and is_enabled = false
+RETURNING *;
+
+-- name: UseTOTPStep :execrows
+UPDATE user_totp
+    set last_used_step = $2
+WHERE user_id = $1 and is_enabled = true and last_used_step < $2;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+    set used_at = now()
+WHERE user_id = $1 and code_hash = $2 and used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
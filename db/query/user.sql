@@ -2,12 +2,28 @@
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: LockUser :exec
+-- Serializes changes of the user's personal lists order until the end of transaction
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
 -- name: CreateUser :one
 INSERT INTO users (
 	username, 
-	hash
+	hash,
+	email,
+	email_verified
 ) VALUES (
-	$1, $2
+	$1, $2, $3, $4
 ) RETURNING *;
 
 -- name: RehashUser :one
@@ -16,7 +32,67 @@ UPDATE users
 WHERE id = $1 and hash = sqlc.arg(old_hash)
 RETURNING *;
 
+-- name: SetUserHash :one
+UPDATE users
+	set hash = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: SetVerifiedUserEmail :one
+UPDATE users
+	set email = $2, email_verified = true
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+	set username = COALESCE(sqlc.narg(username), username),
+	display_name = COALESCE(sqlc.narg(display_name), display_name),
+	time_zone = COALESCE(sqlc.narg(time_zone), time_zone),
+	locale = COALESCE(sqlc.narg(locale), locale)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteUser :one
 DELETE FROM users
 WHERE id = $1
-RETURNING id, username;
\ No newline at end of file
+RETURNING id, username;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE sqlc.narg(search)::text IS NULL
+	or username ILIKE '%' || sqlc.narg(search) || '%'
+	or email ILIKE '%' || sqlc.narg(search) || '%'
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SetUserRole :one
+UPDATE users
+	set role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableUser :one
+UPDATE users
+	set disabled_at = COALESCE(disabled_at, now())
+WHERE id = $1
+RETURNING *;
+
+-- name: EnableUser :one
+UPDATE users
+	set disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserUsage :one
+SELECT
+	(SELECT count(*) FROM lists WHERE lists.author = users.id) AS lists,
+	(SELECT count(*) FROM tasks JOIN lists ON lists.id = tasks.list_id WHERE lists.author = users.id) AS tasks,
+	(SELECT count(*) FROM sessions
+		WHERE sessions.username = users.username
+		and sessions.is_blocked = false and sessions.is_rotated = false and sessions.expires_at > now()
+	) AS active_sessions,
+	(SELECT count(*) FROM personal_access_tokens WHERE personal_access_tokens.user_id = users.id) AS personal_access_tokens
+FROM users
+WHERE users.id = $1;
@@ -0,0 +1,15 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    issuer,
+    subject,
+    user_id,
+    email
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetUserByIdentity :one
+SELECT users.* FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.issuer = $1 and user_identities.subject = $2
+LIMIT 1;
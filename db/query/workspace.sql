@@ -0,0 +1,39 @@
+-- name: CreateWorkspace :one
+INSERT INTO workspaces (
+    name
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: GetWorkspace :one
+SELECT * FROM workspaces
+WHERE id = $1 LIMIT 1;
+
+-- name: LockWorkspace :exec
+-- Serializes changes of the workspace lists order until the end of transaction
+SELECT id FROM workspaces
+WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: GetUserWorkspaces :many
+SELECT workspaces.id, workspaces.name, workspaces.created_at, workspace_members.role FROM workspaces
+JOIN workspace_members ON workspace_members.workspace_id = workspaces.id
+WHERE workspace_members.user_id = $1
+ORDER BY workspaces.id;
+
+-- name: UpdateWorkspace :one
+UPDATE workspaces
+    set name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWorkspace :exec
+DELETE FROM workspaces
+WHERE id = $1;
+
+-- name: DeleteOwnedWorkspaces :exec
+DELETE FROM workspaces
+WHERE id IN (
+    SELECT workspace_id FROM workspace_members
+    WHERE user_id = $1 and role = 'owner'
+);
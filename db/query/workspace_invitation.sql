@@ -0,0 +1,33 @@
+-- name: CreateWorkspaceInvitation :one
+INSERT INTO workspace_invitations (
+    id,
+    workspace_id,
+    user_id,
+    role,
+    invited_by,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) ON CONFLICT (workspace_id, user_id) DO UPDATE
+    set id = EXCLUDED.id, role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, expires_at = EXCLUDED.expires_at, created_at = now()
+RETURNING *;
+
+-- name: GetWorkspaceInvitations :many
+SELECT * FROM workspace_invitations
+WHERE workspace_id = $1 and expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: GetUserWorkspaceInvitations :many
+SELECT workspace_invitations.id, workspace_invitations.workspace_id, workspaces.name AS workspace_name, workspace_invitations.role, workspace_invitations.invited_by, workspace_invitations.expires_at, workspace_invitations.created_at FROM workspace_invitations
+JOIN workspaces ON workspaces.id = workspace_invitations.workspace_id
+WHERE workspace_invitations.user_id = $1 and workspace_invitations.expires_at > now()
+ORDER BY workspace_invitations.created_at DESC;
+
+-- name: TakeWorkspaceInvitation :one
+DELETE FROM workspace_invitations
+WHERE id = $1 and user_id = $2 and expires_at > now()
+RETURNING *;
+
+-- name: DeleteWorkspaceInvitation :execrows
+DELETE FROM workspace_invitations
+WHERE id = $1 and workspace_id = $2;
@@ -0,0 +1,28 @@
+-- name: AddWorkspaceMember :one
+INSERT INTO workspace_members (
+    workspace_id,
+    user_id,
+    role
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetWorkspaceMember :one
+SELECT * FROM workspace_members
+WHERE workspace_id = $1 and user_id = $2 LIMIT 1;
+
+-- name: GetWorkspaceMembers :many
+SELECT workspace_members.user_id, users.username, workspace_members.role, workspace_members.created_at FROM workspace_members
+JOIN users ON users.id = workspace_members.user_id
+WHERE workspace_members.workspace_id = $1
+ORDER BY workspace_members.created_at;
+
+-- name: UpdateWorkspaceMemberRole :one
+UPDATE workspace_members
+    set role = $3
+WHERE workspace_id = $1 and user_id = $2 and role <> 'owner'
+RETURNING *;
+
+-- name: DeleteWorkspaceMember :execrows
+DELETE FROM workspace_members
+WHERE workspace_id = $1 and user_id = $2 and role <> 'owner';
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailVerificationToken(t *testing.T, u *User, duration time.Duration) *EmailVerificationToken {
+	params := CreateEmailVerificationTokenParams{
+		TokenHash: util.HashMailToken(util.RandomString(32)),
+		UserID:    u.ID,
+		Email:     u.Email.String,
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	verificationToken, err := testQueries.CreateEmailVerificationToken(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.TokenHash, verificationToken.TokenHash)
+	require.Equal(t, params.UserID, verificationToken.UserID)
+	require.Equal(t, params.Email, verificationToken.Email)
+	require.WithinDuration(t, params.ExpiresAt, verificationToken.ExpiresAt, time.Second)
+
+	return &verificationToken
+}
+
+func TestCreateEmailVerificationToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomEmailVerificationToken(t, newUser, time.Hour)
+}
+
+func TestUseEmailVerificationToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	verificationToken := createRandomEmailVerificationToken(t, newUser, time.Hour)
+
+	usedToken, err := testQueries.UseEmailVerificationToken(context.Background(), verificationToken.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, verificationToken.Email, usedToken.Email)
+
+	// token can be used only once
+	_, err = testQueries.UseEmailVerificationToken(context.Background(), verificationToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expiredToken := createRandomEmailVerificationToken(t, newUser, -time.Minute)
+
+	_, err = testQueries.UseEmailVerificationToken(context.Background(), expiredToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteEmailVerificationTokens(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	verificationToken := createRandomEmailVerificationToken(t, newUser, time.Hour)
+
+	err := testQueries.DeleteEmailVerificationTokens(context.Background(), newUser.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.UseEmailVerificationToken(context.Background(), verificationToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
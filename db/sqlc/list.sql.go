@@ -7,14 +7,17 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	db "github.com/PYTNAG/simpletodo/db/types"
 )
 
 const addList = `-- name: AddList :one
 INSERT INTO lists (
-	author, header
+	author, header, position
 ) VALUES (
-	$1, $2
-) RETURNING id, author, header
+	$1, $2, (SELECT COALESCE(max(position), 0) + 1024 FROM lists WHERE author = $1 and workspace_id IS NULL)
+) RETURNING id, author, header, description, color, icon, archived, workspace_id, position
 `
 
 type AddListParams struct {
@@ -25,7 +28,49 @@ type AddListParams struct {
 func (q *Queries) AddList(ctx context.Context, arg AddListParams) (List, error) {
 	row := q.db.QueryRowContext(ctx, addList, arg.Author, arg.Header)
 	var i List
-	err := row.Scan(&i.ID, &i.Author, &i.Header)
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
+	return i, err
+}
+
+const addWorkspaceList = `-- name: AddWorkspaceList :one
+INSERT INTO lists (
+	author, workspace_id, header, position
+) VALUES (
+	$1, $2::int, $3,
+	(SELECT COALESCE(max(position), 0) + 1024 FROM lists WHERE workspace_id = $2::int)
+) RETURNING id, author, header, description, color, icon, archived, workspace_id, position
+`
+
+type AddWorkspaceListParams struct {
+	Author      int32  `json:"author"`
+	WorkspaceID int32  `json:"workspace_id"`
+	Header      string `json:"header"`
+}
+
+func (q *Queries) AddWorkspaceList(ctx context.Context, arg AddWorkspaceListParams) (List, error) {
+	row := q.db.QueryRowContext(ctx, addWorkspaceList, arg.Author, arg.WorkspaceID, arg.Header)
+	var i List
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
 	return i, err
 }
 
@@ -39,14 +84,99 @@ func (q *Queries) DeleteList(ctx context.Context, id int32) error {
 	return err
 }
 
-const getLists = `-- name: GetLists :many
-SELECT id, header FROM lists
+const deleteUserLists = `-- name: DeleteUserLists :exec
+DELETE FROM lists
 WHERE author = $1
 `
 
+func (q *Queries) DeleteUserLists(ctx context.Context, author int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserLists, author)
+	return err
+}
+
+const getAuthoredLists = `-- name: GetAuthoredLists :many
+SELECT id, header, description, color, icon, archived, workspace_id FROM lists
+WHERE author = $1
+ORDER BY workspace_id NULLS FIRST, position, id
+`
+
+type GetAuthoredListsRow struct {
+	ID          int32        `json:"id"`
+	Header      string       `json:"header"`
+	Description string       `json:"description"`
+	Color       string       `json:"color"`
+	Icon        string       `json:"icon"`
+	Archived    bool         `json:"archived"`
+	WorkspaceID db.NullInt32 `json:"workspace_id"`
+}
+
+// Returns personal lists of the user and lists the user has authored in workspaces
+func (q *Queries) GetAuthoredLists(ctx context.Context, author int32) ([]GetAuthoredListsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuthoredLists, author)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAuthoredListsRow{}
+	for rows.Next() {
+		var i GetAuthoredListsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Header,
+			&i.Description,
+			&i.Color,
+			&i.Icon,
+			&i.Archived,
+			&i.WorkspaceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getList = `-- name: GetList :one
+SELECT id, author, header, description, color, icon, archived, workspace_id, position FROM lists
+WHERE id = $1
+`
+
+func (q *Queries) GetList(ctx context.Context, id int32) (List, error) {
+	row := q.db.QueryRowContext(ctx, getList, id)
+	var i List
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getLists = `-- name: GetLists :many
+SELECT id, header, description, color, icon, archived FROM lists
+WHERE author = $1 and workspace_id IS NULL
+ORDER BY position, id
+`
+
 type GetListsRow struct {
-	ID     int32  `json:"id"`
-	Header string `json:"header"`
+	ID          int32  `json:"id"`
+	Header      string `json:"header"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+	Icon        string `json:"icon"`
+	Archived    bool   `json:"archived"`
 }
 
 func (q *Queries) GetLists(ctx context.Context, author int32) ([]GetListsRow, error) {
@@ -58,7 +188,89 @@ func (q *Queries) GetLists(ctx context.Context, author int32) ([]GetListsRow, er
 	items := []GetListsRow{}
 	for rows.Next() {
 		var i GetListsRow
-		if err := rows.Scan(&i.ID, &i.Header); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Header,
+			&i.Description,
+			&i.Color,
+			&i.Icon,
+			&i.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextListPosition = `-- name: GetNextListPosition :one
+SELECT position FROM lists
+WHERE workspace_id IS NOT DISTINCT FROM $1 and (workspace_id IS NOT NULL or author = $2)
+	and id <> $3 and ($4::float8 IS NULL or (position, id) > ($4::float8, $5::int))
+ORDER BY position, id
+LIMIT 1
+`
+
+type GetNextListPositionParams struct {
+	WorkspaceID db.NullInt32    `json:"workspace_id"`
+	Author      int32           `json:"author"`
+	ID          int32           `json:"id"`
+	After       sql.NullFloat64 `json:"after"`
+	AfterID     int32           `json:"after_id"`
+}
+
+// Returns position of the list following the given one by position and id among lists of the same space
+func (q *Queries) GetNextListPosition(ctx context.Context, arg GetNextListPositionParams) (float64, error) {
+	row := q.db.QueryRowContext(ctx, getNextListPosition,
+		arg.WorkspaceID,
+		arg.Author,
+		arg.ID,
+		arg.After,
+		arg.AfterID,
+	)
+	var position float64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const getWorkspaceLists = `-- name: GetWorkspaceLists :many
+SELECT id, header, description, color, icon, archived FROM lists
+WHERE workspace_id = $1::int
+ORDER BY position, id
+`
+
+type GetWorkspaceListsRow struct {
+	ID          int32  `json:"id"`
+	Header      string `json:"header"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+	Icon        string `json:"icon"`
+	Archived    bool   `json:"archived"`
+}
+
+func (q *Queries) GetWorkspaceLists(ctx context.Context, workspaceID int32) ([]GetWorkspaceListsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceLists, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetWorkspaceListsRow{}
+	for rows.Next() {
+		var i GetWorkspaceListsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Header,
+			&i.Description,
+			&i.Color,
+			&i.Icon,
+			&i.Archived,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -71,3 +283,154 @@ func (q *Queries) GetLists(ctx context.Context, author int32) ([]GetListsRow, er
 	}
 	return items, nil
 }
+
+const lockList = `-- name: LockList :exec
+SELECT id FROM lists
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// Serializes changes of the list tasks order until the end of transaction
+func (q *Queries) LockList(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, lockList, id)
+	return err
+}
+
+const reassignWorkspaceLists = `-- name: ReassignWorkspaceLists :exec
+UPDATE lists
+	set author = workspace_members.user_id
+FROM workspace_members
+WHERE lists.author = $1 and workspace_members.workspace_id = lists.workspace_id and workspace_members.role = 'owner'
+`
+
+func (q *Queries) ReassignWorkspaceLists(ctx context.Context, author int32) error {
+	_, err := q.db.ExecContext(ctx, reassignWorkspaceLists, author)
+	return err
+}
+
+const rebalanceListPositions = `-- name: RebalanceListPositions :exec
+UPDATE lists
+	set position = ranked.rank * 1024
+FROM (
+	SELECT id, row_number() OVER (ORDER BY position, id) AS rank FROM lists
+	WHERE workspace_id IS NOT DISTINCT FROM $1 and (workspace_id IS NOT NULL or author = $2)
+) AS ranked
+WHERE lists.id = ranked.id
+`
+
+type RebalanceListPositionsParams struct {
+	WorkspaceID db.NullInt32 `json:"workspace_id"`
+	Author      int32        `json:"author"`
+}
+
+func (q *Queries) RebalanceListPositions(ctx context.Context, arg RebalanceListPositionsParams) error {
+	_, err := q.db.ExecContext(ctx, rebalanceListPositions, arg.WorkspaceID, arg.Author)
+	return err
+}
+
+const setListPosition = `-- name: SetListPosition :one
+UPDATE lists
+	set position = $2
+WHERE id = $1
+RETURNING id, author, header, description, color, icon, archived, workspace_id, position
+`
+
+type SetListPositionParams struct {
+	ID       int32   `json:"id"`
+	Position float64 `json:"position"`
+}
+
+func (q *Queries) SetListPosition(ctx context.Context, arg SetListPositionParams) (List, error) {
+	row := q.db.QueryRowContext(ctx, setListPosition, arg.ID, arg.Position)
+	var i List
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
+	return i, err
+}
+
+const setListWorkspace = `-- name: SetListWorkspace :one
+UPDATE lists
+	set workspace_id = $2, author = $3, position = (
+		SELECT COALESCE(max(other.position), 0) + 1024 FROM lists AS other
+		WHERE other.workspace_id IS NOT DISTINCT FROM $2 and ($2 IS NOT NULL or other.author = $3)
+	)
+WHERE id = $1
+RETURNING id, author, header, description, color, icon, archived, workspace_id, position
+`
+
+type SetListWorkspaceParams struct {
+	ID          int32        `json:"id"`
+	WorkspaceID db.NullInt32 `json:"workspace_id"`
+	Author      int32        `json:"author"`
+}
+
+// List is placed after the last list of its new space
+func (q *Queries) SetListWorkspace(ctx context.Context, arg SetListWorkspaceParams) (List, error) {
+	row := q.db.QueryRowContext(ctx, setListWorkspace, arg.ID, arg.WorkspaceID, arg.Author)
+	var i List
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
+	return i, err
+}
+
+const updateList = `-- name: UpdateList :one
+UPDATE lists
+	set header = COALESCE($1, header),
+	description = COALESCE($2, description),
+	color = COALESCE($3, color),
+	icon = COALESCE($4, icon),
+	archived = COALESCE($5, archived)
+WHERE id = $6
+RETURNING id, author, header, description, color, icon, archived, workspace_id, position
+`
+
+type UpdateListParams struct {
+	Header      sql.NullString `json:"header"`
+	Description sql.NullString `json:"description"`
+	Color       sql.NullString `json:"color"`
+	Icon        sql.NullString `json:"icon"`
+	Archived    sql.NullBool   `json:"archived"`
+	ID          int32          `json:"id"`
+}
+
+func (q *Queries) UpdateList(ctx context.Context, arg UpdateListParams) (List, error) {
+	row := q.db.QueryRowContext(ctx, updateList,
+		arg.Header,
+		arg.Description,
+		arg.Color,
+		arg.Icon,
+		arg.Archived,
+		arg.ID,
+	)
+	var i List
+	err := row.Scan(
+		&i.ID,
+		&i.Author,
+		&i.Header,
+		&i.Description,
+		&i.Color,
+		&i.Icon,
+		&i.Archived,
+		&i.WorkspaceID,
+		&i.Position,
+	)
+	return i, err
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomListShare(t *testing.T, l *List, expiresAt sql.NullTime) *ListShare {
+	params := CreateListShareParams{
+		ID:        uuid.New(),
+		ListID:    l.ID,
+		TokenHash: []byte(util.RandomString(32)),
+		ExpiresAt: expiresAt,
+	}
+
+	share, err := testQueries.CreateListShare(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.ID, share.ID)
+	require.Equal(t, params.ListID, share.ListID)
+	require.Equal(t, params.TokenHash, share.TokenHash)
+	require.Equal(t, params.ExpiresAt.Valid, share.ExpiresAt.Valid)
+	require.NotZero(t, share.CreatedAt)
+
+	return &share
+}
+
+func TestGetListShares(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	createRandomListShare(t, defaultList, sql.NullTime{})
+	createRandomListShare(t, defaultList, sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true})
+
+	shares, err := testQueries.GetListShares(context.Background(), defaultList.ID)
+
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestGetSharedList(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	share := createRandomListShare(t, defaultList, sql.NullTime{})
+	expired := createRandomListShare(t, defaultList, sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true})
+
+	list, err := testQueries.GetSharedList(context.Background(), share.TokenHash)
+
+	require.NoError(t, err)
+	require.Equal(t, defaultList.ID, list.ID)
+	require.Equal(t, defaultList.Header, list.Header)
+
+	_, err = testQueries.GetSharedList(context.Background(), expired.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// lists of disabled users aren't shared
+	_, err = testQueries.DisableUser(context.Background(), newUser.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSharedList(context.Background(), share.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestDeleteListShare(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	share := createRandomListShare(t, defaultList, sql.NullTime{})
+
+	deleted, err := testQueries.DeleteListShare(context.Background(), DeleteListShareParams{ID: share.ID, ListID: defaultList.ID + 1})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = testQueries.DeleteListShare(context.Background(), DeleteListShareParams{ID: share.ID, ListID: defaultList.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = testQueries.GetSharedList(context.Background(), share.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, newUser)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/PYTNAG/simpletodo/util"
@@ -50,6 +51,29 @@ func TestGetLists(t *testing.T) {
 	deleteTestUser(t, newUser)
 }
 
+func TestUpdateList(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	params := UpdateListParams{
+		ID:          defaultList.ID,
+		Description: sql.NullString{String: util.RandomString(20), Valid: true},
+		Color:       sql.NullString{String: "#00ff00", Valid: true},
+		Archived:    sql.NullBool{Bool: true, Valid: true},
+	}
+
+	list, err := testQueries.UpdateList(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, defaultList.ID, list.ID)
+	require.Equal(t, defaultList.Header, list.Header)
+	require.Equal(t, params.Description.String, list.Description)
+	require.Equal(t, params.Color.String, list.Color)
+	require.Empty(t, list.Icon)
+	require.True(t, list.Archived)
+
+	deleteTestUser(t, newUser)
+}
+
 func TestDeleteList(t *testing.T) {
 	newUser, defaultList := createRandomUser(t, true)
 
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddLoginFailure(t *testing.T) {
+	key := util.RandomString(16)
+	now := time.Now().UTC()
+
+	params := AddLoginFailureParams{
+		Key:          key,
+		LastFailedAt: now,
+		Since:        now.Add(-time.Minute),
+	}
+
+	attempt, err := testQueries.AddLoginFailure(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, key, attempt.Key)
+	require.Equal(t, int32(1), attempt.Failures)
+	require.WithinDuration(t, now, attempt.LastFailedAt, time.Second)
+
+	attempt, err = testQueries.AddLoginFailure(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempt.Failures)
+
+	// failures before since are forgotten
+	later := now.Add(2 * time.Minute)
+
+	attempt, err = testQueries.AddLoginFailure(context.Background(), AddLoginFailureParams{
+		Key:          key,
+		LastFailedAt: later,
+		Since:        later.Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.Failures)
+
+	gotAttempt, err := testQueries.GetLoginAttempts(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, attempt.Failures, gotAttempt.Failures)
+	require.WithinDuration(t, later, gotAttempt.LastFailedAt, time.Second)
+}
+
+func TestDeleteLoginAttempts(t *testing.T) {
+	key := util.RandomString(16)
+
+	_, err := testQueries.AddLoginFailure(context.Background(), AddLoginFailureParams{
+		Key:          key,
+		LastFailedAt: time.Now(),
+		Since:        time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteLoginAttempts(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginAttempts(context.Background(), key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTakeLoginAttempt(t *testing.T) {
+	key := util.RandomString(16)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	err := testQueries.CreateLoginAttempts(context.Background(), CreateLoginAttemptsParams{Key: key, LastFailedAt: now})
+	require.NoError(t, err)
+
+	takenAt := now.Add(time.Second)
+
+	previous, err := testQueries.TakeLoginAttempt(context.Background(), TakeLoginAttemptParams{
+		Since:   takenAt.Add(-time.Minute),
+		TakenAt: takenAt,
+		Key:     key,
+	})
+	require.NoError(t, err)
+	require.Zero(t, previous.Failures)
+	require.WithinDuration(t, now, previous.LastFailedAt, time.Microsecond)
+
+	attempt, err := testQueries.GetLoginAttempts(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.Failures)
+	require.WithinDuration(t, takenAt, attempt.LastFailedAt, time.Microsecond)
+
+	err = testQueries.ReleaseLoginAttempt(context.Background(), ReleaseLoginAttemptParams{
+		TakenAt:          takenAt,
+		PreviousFailedAt: previous.LastFailedAt,
+		Key:              key,
+	})
+	require.NoError(t, err)
+
+	attempt, err = testQueries.GetLoginAttempts(context.Background(), key)
+	require.NoError(t, err)
+	require.Zero(t, attempt.Failures)
+	require.WithinDuration(t, now, attempt.LastFailedAt, time.Microsecond)
+
+	// missing row isn't taken
+	_, err = testQueries.TakeLoginAttempt(context.Background(), TakeLoginAttemptParams{
+		Since:   takenAt.Add(-time.Minute),
+		TakenAt: takenAt,
+		Key:     util.RandomString(16),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
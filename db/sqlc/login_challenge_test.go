@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomLoginChallenge(t *testing.T, u *User, duration time.Duration) *LoginChallenge {
+	params := CreateLoginChallengeParams{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	challenge, err := testQueries.CreateLoginChallenge(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.ID, challenge.ID)
+	require.Equal(t, params.UserID, challenge.UserID)
+	require.Zero(t, challenge.Attempts)
+	require.WithinDuration(t, params.ExpiresAt, challenge.ExpiresAt, time.Second)
+
+	return &challenge
+}
+
+func TestCreateLoginChallenge(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomLoginChallenge(t, newUser, time.Minute)
+}
+
+func TestTakeLoginChallengeAttempt(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	challenge := createRandomLoginChallenge(t, newUser, time.Minute)
+
+	params := TakeLoginChallengeAttemptParams{
+		ID:          challenge.ID,
+		UserID:      newUser.ID,
+		MaxAttempts: 2,
+	}
+
+	for i := int32(1); i <= params.MaxAttempts; i++ {
+		taken, err := testQueries.TakeLoginChallengeAttempt(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, i, taken.Attempts)
+	}
+
+	_, err := testQueries.TakeLoginChallengeAttempt(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// challenge belongs to its user
+	otherUser, _ := createRandomUser(t, false)
+	otherChallenge := createRandomLoginChallenge(t, otherUser, time.Minute)
+
+	params.ID = otherChallenge.ID
+	_, err = testQueries.TakeLoginChallengeAttempt(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expiredChallenge := createRandomLoginChallenge(t, newUser, -time.Minute)
+
+	params.ID = expiredChallenge.ID
+	_, err = testQueries.TakeLoginChallengeAttempt(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteLoginChallenge(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	challenge := createRandomLoginChallenge(t, newUser, time.Minute)
+
+	deleted, err := testQueries.DeleteLoginChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	// challenge can be consumed only once
+	deleted, err = testQueries.DeleteLoginChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+}
@@ -5,27 +5,104 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	db "github.com/PYTNAG/simpletodo/db/types"
 	"github.com/google/uuid"
 )
 
+type EmailVerificationToken struct {
+	TokenHash []byte    `json:"token_hash"`
+	UserID    int32     `json:"user_id"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type List struct {
-	ID     int32  `json:"id"`
-	Author int32  `json:"author"`
-	Header string `json:"header"`
+	ID          int32        `json:"id"`
+	Author      int32        `json:"author"`
+	Header      string       `json:"header"`
+	Description string       `json:"description"`
+	Color       string       `json:"color"`
+	Icon        string       `json:"icon"`
+	Archived    bool         `json:"archived"`
+	WorkspaceID db.NullInt32 `json:"workspace_id"`
+	Position    float64      `json:"position"`
+}
+
+type ListShare struct {
+	ID        uuid.UUID    `json:"id"`
+	ListID    int32        `json:"list_id"`
+	TokenHash []byte       `json:"token_hash"`
+	ExpiresAt sql.NullTime `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	Key          string    `json:"key"`
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+type LoginChallenge struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    int32     `json:"user_id"`
+	Attempts  int32     `json:"attempts"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OidcAuthRequest struct {
+	State        string        `json:"state"`
+	Nonce        string        `json:"nonce"`
+	CodeVerifier string        `json:"code_verifier"`
+	UserID       sql.NullInt32 `json:"user_id"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	TokenHash []byte       `json:"token_hash"`
+	UserID    int32        `json:"user_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID      `json:"id"`
+	UserID     int32          `json:"user_id"`
+	Name       string         `json:"name"`
+	TokenHash  []byte         `json:"token_hash"`
+	ExpiresAt  sql.NullTime   `json:"expires_at"`
+	LastUsedAt sql.NullTime   `json:"last_used_at"`
+	LastUsedIp sql.NullString `json:"last_used_ip"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Scopes     []string       `json:"scopes"`
+	ListIds    []int32        `json:"list_ids"`
+}
+
+type RecoveryCode struct {
+	ID       int64        `json:"id"`
+	UserID   int32        `json:"user_id"`
+	CodeHash []byte       `json:"code_hash"`
+	UsedAt   sql.NullTime `json:"used_at"`
 }
 
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID     `json:"id"`
+	Username     string        `json:"username"`
+	RefreshToken string        `json:"refresh_token"`
+	UserAgent    string        `json:"user_agent"`
+	ClientIp     string        `json:"client_ip"`
+	IsBlocked    bool          `json:"is_blocked"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	FamilyID     uuid.UUID     `json:"family_id"`
+	ParentID     uuid.NullUUID `json:"parent_id"`
+	IsRotated    bool          `json:"is_rotated"`
 }
 
 type Task struct {
@@ -34,10 +111,57 @@ type Task struct {
 	ParentTask db.NullInt32 `json:"parent_task"`
 	Task       string       `json:"task"`
 	Complete   bool         `json:"complete"`
+	Position   float64      `json:"position"`
 }
 
 type User struct {
-	ID       int32  `json:"id"`
-	Username string `json:"username"`
-	Hash     []byte `json:"hash"`
+	ID            int32          `json:"id"`
+	Username      string         `json:"username"`
+	Hash          []byte         `json:"hash"`
+	Email         sql.NullString `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
+	DisplayName   string         `json:"display_name"`
+	TimeZone      string         `json:"time_zone"`
+	Locale        string         `json:"locale"`
+	Role          string         `json:"role"`
+	DisabledAt    sql.NullTime   `json:"disabled_at"`
+}
+
+type UserIdentity struct {
+	Issuer    string         `json:"issuer"`
+	Subject   string         `json:"subject"`
+	UserID    int32          `json:"user_id"`
+	Email     sql.NullString `json:"email"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       int32     `json:"user_id"`
+	Secret       string    `json:"secret"`
+	IsEnabled    bool      `json:"is_enabled"`
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Workspace struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceInvitation struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID int32     `json:"workspace_id"`
+	UserID      int32     `json:"user_id"`
+	Role        string    `json:"role"`
+	InvitedBy   int32     `json:"invited_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int32     `json:"workspace_id"`
+	UserID      int32     `json:"user_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
 }
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOidcAuthRequest(t *testing.T, userID sql.NullInt32) *OidcAuthRequest {
+	params := CreateOidcAuthRequestParams{
+		State:        util.RandomString(43),
+		Nonce:        util.RandomString(43),
+		CodeVerifier: util.RandomString(43),
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	authRequest, err := testQueries.CreateOidcAuthRequest(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.State, authRequest.State)
+	require.Equal(t, params.Nonce, authRequest.Nonce)
+	require.Equal(t, params.CodeVerifier, authRequest.CodeVerifier)
+	require.Equal(t, params.UserID, authRequest.UserID)
+	require.WithinDuration(t, params.ExpiresAt, authRequest.ExpiresAt, time.Second)
+
+	return &authRequest
+}
+
+func TestCreateOidcAuthRequest(t *testing.T) {
+	createRandomOidcAuthRequest(t, sql.NullInt32{})
+
+	newUser, _ := createRandomUser(t, false)
+	createRandomOidcAuthRequest(t, sql.NullInt32{Int32: newUser.ID, Valid: true})
+}
+
+func TestTakeOidcAuthRequest(t *testing.T) {
+	authRequest := createRandomOidcAuthRequest(t, sql.NullInt32{})
+
+	takenRequest, err := testQueries.TakeOidcAuthRequest(context.Background(), authRequest.State)
+	require.NoError(t, err)
+	require.Equal(t, authRequest.Nonce, takenRequest.Nonce)
+	require.Equal(t, authRequest.CodeVerifier, takenRequest.CodeVerifier)
+
+	// state can be used only once
+	_, err = testQueries.TakeOidcAuthRequest(context.Background(), authRequest.State)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
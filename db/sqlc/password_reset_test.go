@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordResetToken(t *testing.T, u *User, duration time.Duration) *PasswordResetToken {
+	params := CreatePasswordResetTokenParams{
+		TokenHash: util.HashMailToken(util.RandomString(32)),
+		UserID:    u.ID,
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	resetToken, err := testQueries.CreatePasswordResetToken(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.TokenHash, resetToken.TokenHash)
+	require.Equal(t, params.UserID, resetToken.UserID)
+	require.WithinDuration(t, params.ExpiresAt, resetToken.ExpiresAt, time.Second)
+	require.False(t, resetToken.UsedAt.Valid)
+
+	return &resetToken
+}
+
+func TestCreatePasswordResetToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomPasswordResetToken(t, newUser, time.Hour)
+}
+
+func TestUsePasswordResetToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	resetToken := createRandomPasswordResetToken(t, newUser, time.Hour)
+
+	usedToken, err := testQueries.UsePasswordResetToken(context.Background(), resetToken.TokenHash)
+	require.NoError(t, err)
+	require.True(t, usedToken.UsedAt.Valid)
+
+	// token can be used only once
+	_, err = testQueries.UsePasswordResetToken(context.Background(), resetToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	expiredToken := createRandomPasswordResetToken(t, newUser, -time.Minute)
+
+	_, err = testQueries.UsePasswordResetToken(context.Background(), expiredToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeletePasswordResetTokens(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	resetToken := createRandomPasswordResetToken(t, newUser, time.Hour)
+
+	err := testQueries.DeletePasswordResetTokens(context.Background(), newUser.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.UsePasswordResetToken(context.Background(), resetToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPersonalAccessToken(t *testing.T, u *User) *PersonalAccessToken {
+	params := CreatePersonalAccessTokenParams{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		Name:      util.RandomString(8),
+		TokenHash: []byte(util.RandomString(32)),
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour).UTC(), Valid: true},
+		Scopes:    []string{"lists:read", "tasks:read"},
+		ListIds:   []int32{},
+	}
+
+	pat, err := testQueries.CreatePersonalAccessToken(context.Background(), params)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, pat)
+
+	require.Equal(t, params.ID, pat.ID)
+	require.Equal(t, params.UserID, pat.UserID)
+	require.Equal(t, params.Name, pat.Name)
+	require.Equal(t, params.TokenHash, pat.TokenHash)
+	require.WithinDuration(t, params.ExpiresAt.Time, pat.ExpiresAt.Time, time.Second)
+	require.Equal(t, params.Scopes, pat.Scopes)
+	require.Empty(t, pat.ListIds)
+	require.False(t, pat.LastUsedAt.Valid)
+	require.False(t, pat.LastUsedIp.Valid)
+
+	return &pat
+}
+
+func TestCreatePersonalAccessToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomPersonalAccessToken(t, newUser)
+}
+
+func TestGetPersonalAccessTokenByHash(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	pat := createRandomPersonalAccessToken(t, newUser)
+
+	gotPat, err := testQueries.GetPersonalAccessTokenByHash(context.Background(), pat.TokenHash)
+
+	require.NoError(t, err)
+	require.Equal(t, pat.ID, gotPat.ID)
+	require.Equal(t, newUser.Username, gotPat.Username)
+
+	_, err = testQueries.GetPersonalAccessTokenByHash(context.Background(), []byte(util.RandomString(32)))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdatePersonalAccessTokenUsage(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	pat := createRandomPersonalAccessToken(t, newUser)
+
+	params := UpdatePersonalAccessTokenUsageParams{
+		ID:         pat.ID,
+		LastUsedIp: "127.0.0.1",
+	}
+
+	err := testQueries.UpdatePersonalAccessTokenUsage(context.Background(), params)
+	require.NoError(t, err)
+
+	pats, err := testQueries.GetPersonalAccessTokens(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Len(t, pats, 1)
+
+	require.True(t, pats[0].LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), pats[0].LastUsedAt.Time, time.Second)
+	require.Equal(t, sql.NullString{String: params.LastUsedIp, Valid: true}, pats[0].LastUsedIp)
+}
+
+func TestDeletePersonalAccessToken(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	otherUser, _ := createRandomUser(t, false)
+	pat := createRandomPersonalAccessToken(t, newUser)
+
+	// other user can't delete token
+	deleted, err := testQueries.DeletePersonalAccessToken(context.Background(), DeletePersonalAccessTokenParams{ID: pat.ID, UserID: otherUser.ID})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = testQueries.DeletePersonalAccessToken(context.Background(), DeletePersonalAccessTokenParams{ID: pat.ID, UserID: newUser.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = testQueries.GetPersonalAccessTokenByHash(context.Background(), pat.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
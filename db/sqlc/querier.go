@@ -6,25 +6,130 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddList(ctx context.Context, arg AddListParams) (List, error)
+	AddLoginFailure(ctx context.Context, arg AddLoginFailureParams) (LoginAttempt, error)
 	AddTask(ctx context.Context, arg AddTaskParams) (Task, error)
+	AddWorkspaceList(ctx context.Context, arg AddWorkspaceListParams) (List, error)
+	AddWorkspaceMember(ctx context.Context, arg AddWorkspaceMemberParams) (WorkspaceMember, error)
+	BlockOtherUserSessions(ctx context.Context, arg BlockOtherUserSessionsParams) error
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	BlockUserSession(ctx context.Context, arg BlockUserSessionParams) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error)
+	CreateListShare(ctx context.Context, arg CreateListShareParams) (ListShare, error)
+	CreateLoginAttempts(ctx context.Context, arg CreateLoginAttemptsParams) error
+	CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) (LoginChallenge, error)
+	CreateOidcAuthRequest(ctx context.Context, arg CreateOidcAuthRequestParams) (OidcAuthRequest, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserTOTP(ctx context.Context, arg CreateUserTOTPParams) (UserTotp, error)
+	CreateWorkspace(ctx context.Context, name string) (Workspace, error)
+	CreateWorkspaceInvitation(ctx context.Context, arg CreateWorkspaceInvitationParams) (WorkspaceInvitation, error)
+	DeleteEmailVerificationTokens(ctx context.Context, userID int32) error
 	DeleteList(ctx context.Context, id int32) error
+	DeleteListShare(ctx context.Context, arg DeleteListShareParams) (int64, error)
+	DeleteListShares(ctx context.Context, listID int32) error
+	DeleteLoginAttempts(ctx context.Context, key string) error
+	DeleteLoginChallenge(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteOwnedWorkspaces(ctx context.Context, userID int32) error
+	DeletePasswordResetTokens(ctx context.Context, userID int32) error
+	DeletePersonalAccessToken(ctx context.Context, arg DeletePersonalAccessTokenParams) (int64, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int32) error
 	DeleteTask(ctx context.Context, id int32) error
 	DeleteUser(ctx context.Context, id int32) (DeleteUserRow, error)
+	DeleteUserLists(ctx context.Context, author int32) error
+	DeleteUserSessions(ctx context.Context, username string) error
+	DeleteUserTOTP(ctx context.Context, userID int32) error
+	DeleteWorkspace(ctx context.Context, id int32) error
+	DeleteWorkspaceInvitation(ctx context.Context, arg DeleteWorkspaceInvitationParams) (int64, error)
+	DeleteWorkspaceMember(ctx context.Context, arg DeleteWorkspaceMemberParams) (int64, error)
+	DisableUser(ctx context.Context, id int32) (User, error)
+	EnableUser(ctx context.Context, id int32) (User, error)
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (UserTotp, error)
+	// Returns personal lists of the user and lists the user has authored in workspaces
+	GetAuthoredLists(ctx context.Context, author int32) ([]GetAuthoredListsRow, error)
+	GetList(ctx context.Context, id int32) (List, error)
+	GetListShares(ctx context.Context, listID int32) ([]GetListSharesRow, error)
 	GetLists(ctx context.Context, author int32) ([]GetListsRow, error)
+	GetLoginAttempts(ctx context.Context, key string) (LoginAttempt, error)
+	// Returns position of the list following the given position among lists of the same space
+	GetNextListPosition(ctx context.Context, arg GetNextListPositionParams) (float64, error)
+	// Returns position of the task following the given position among tasks of the same parent
+	GetNextTaskPosition(ctx context.Context, arg GetNextTaskPositionParams) (float64, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash []byte) (GetPersonalAccessTokenByHashRow, error)
+	GetPersonalAccessTokens(ctx context.Context, userID int32) ([]GetPersonalAccessTokensRow, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSharedList(ctx context.Context, tokenHash []byte) (GetSharedListRow, error)
+	GetTask(ctx context.Context, arg GetTaskParams) (Task, error)
+	// Returns tasks of the subtree ordered by depth, so parent goes before its children.
+	// Whole list is returned if root task is null
+	GetTaskTree(ctx context.Context, arg GetTaskTreeParams) ([]GetTaskTreeRow, error)
 	GetTasks(ctx context.Context, listID int32) ([]Task, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email sql.NullString) (User, error)
+	GetUserByID(ctx context.Context, id int32) (User, error)
+	GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error)
+	GetUserSession(ctx context.Context, arg GetUserSessionParams) (GetUserSessionRow, error)
+	GetUserSessions(ctx context.Context, arg GetUserSessionsParams) ([]GetUserSessionsRow, error)
+	GetUserTOTP(ctx context.Context, userID int32) (UserTotp, error)
+	GetUserUsage(ctx context.Context, id int32) (GetUserUsageRow, error)
+	GetUserWorkspaceInvitations(ctx context.Context, userID int32) ([]GetUserWorkspaceInvitationsRow, error)
+	GetUserWorkspaces(ctx context.Context, userID int32) ([]GetUserWorkspacesRow, error)
+	GetWorkspace(ctx context.Context, id int32) (Workspace, error)
+	GetWorkspaceInvitations(ctx context.Context, workspaceID int32) ([]WorkspaceInvitation, error)
+	GetWorkspaceLists(ctx context.Context, workspaceID int32) ([]GetWorkspaceListsRow, error)
+	GetWorkspaceMember(ctx context.Context, arg GetWorkspaceMemberParams) (WorkspaceMember, error)
+	GetWorkspaceMembers(ctx context.Context, workspaceID int32) ([]GetWorkspaceMembersRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// Serializes changes of the list tasks order until the end of transaction
+	LockList(ctx context.Context, id int32) error
+	// Serializes changes of the user's personal lists order until the end of transaction
+	LockUser(ctx context.Context, id int32) error
+	// Serializes changes of the workspace lists order until the end of transaction
+	LockWorkspace(ctx context.Context, id int32) error
+	ReassignWorkspaceLists(ctx context.Context, author int32) error
+	RebalanceListPositions(ctx context.Context, arg RebalanceListPositionsParams) error
+	RebalanceTaskPositions(ctx context.Context, arg RebalanceTaskPositionsParams) error
 	RehashUser(ctx context.Context, arg RehashUserParams) (User, error)
+	// Undoes failure registered in advance of the attempt, its time is restored unless there was a later attempt
+	ReleaseLoginAttempt(ctx context.Context, arg ReleaseLoginAttemptParams) error
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	SetListPosition(ctx context.Context, arg SetListPositionParams) (List, error)
+	// List is placed after the last list of its new space
+	SetListWorkspace(ctx context.Context, arg SetListWorkspaceParams) (List, error)
+	// Task is placed after the last child of its new parent
+	SetTaskParent(ctx context.Context, arg SetTaskParentParams) (Task, error)
+	SetTaskPosition(ctx context.Context, arg SetTaskPositionParams) (Task, error)
+	SetUserHash(ctx context.Context, arg SetUserHashParams) (User, error)
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error)
+	SetVerifiedUserEmail(ctx context.Context, arg SetVerifiedUserEmailParams) (User, error)
+	// Registers failure in advance of the attempt and returns attempts before it
+	TakeLoginAttempt(ctx context.Context, arg TakeLoginAttemptParams) (TakeLoginAttemptRow, error)
+	// Reserves attempt to answer the challenge, returns no rows if challenge is used, expired or out of attempts
+	TakeLoginChallengeAttempt(ctx context.Context, arg TakeLoginChallengeAttemptParams) (LoginChallenge, error)
+	TakeOidcAuthRequest(ctx context.Context, state string) (OidcAuthRequest, error)
+	TakeWorkspaceInvitation(ctx context.Context, arg TakeWorkspaceInvitationParams) (WorkspaceInvitation, error)
 	ToggleTask(ctx context.Context, id int32) error
+	UpdateList(ctx context.Context, arg UpdateListParams) (List, error)
+	UpdatePersonalAccessTokenUsage(ctx context.Context, arg UpdatePersonalAccessTokenUsageParams) error
 	UpdateTaskText(ctx context.Context, arg UpdateTaskTextParams) error
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
+	UpdateWorkspaceMemberRole(ctx context.Context, arg UpdateWorkspaceMemberRoleParams) (WorkspaceMember, error)
+	UseEmailVerificationToken(ctx context.Context, tokenHash []byte) (EmailVerificationToken, error)
+	UsePasswordResetToken(ctx context.Context, tokenHash []byte) (PasswordResetToken, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
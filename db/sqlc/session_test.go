@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, u *User) *Session {
+	sessionId := uuid.New()
+
+	params := CreateSessionParams{
+		ID:           sessionId,
+		Username:     u.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(16),
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour).UTC(),
+		FamilyID:     sessionId,
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), params)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, params.ID, session.ID)
+	require.Equal(t, params.Username, session.Username)
+	require.Equal(t, params.RefreshToken, session.RefreshToken)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.IsRotated)
+	require.Equal(t, params.FamilyID, session.FamilyID)
+	require.False(t, session.ParentID.Valid)
+	require.WithinDuration(t, params.ExpiresAt, session.ExpiresAt, time.Second)
+
+	return &session
+}
+
+func TestCreateSession(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomSession(t, newUser)
+}
+
+func TestRotateSession(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	rotatedSession, err := testQueries.RotateSession(context.Background(), session.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, session.ID, rotatedSession.ID)
+	require.True(t, rotatedSession.IsRotated)
+
+	// session can be rotated only once
+	_, err = testQueries.RotateSession(context.Background(), session.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestBlockSessionFamily(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+	otherSession := createRandomSession(t, newUser)
+
+	err := testQueries.BlockSessionFamily(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+
+	blockedSession, err := testQueries.GetSession(context.Background(), session.ID)
+
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	otherSession2, err := testQueries.GetSession(context.Background(), otherSession.ID)
+
+	require.NoError(t, err)
+	require.False(t, otherSession2.IsBlocked)
+}
+
+func TestBlockUserSession(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	otherUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	params := BlockUserSessionParams{
+		ID:       session.ID,
+		Username: otherUser.Username,
+	}
+
+	_, err := testQueries.BlockUserSession(context.Background(), params)
+	require.Error(t, err)
+
+	params.Username = newUser.Username
+
+	blockedSession, err := testQueries.BlockUserSession(context.Background(), params)
+
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	deleteTestUser(t, otherUser)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+
+	const sessionsCount = 3
+	sessions := make([]*Session, sessionsCount)
+	for i := range sessions {
+		sessions[i] = createRandomSession(t, newUser)
+	}
+
+	err := testQueries.BlockUserSessions(context.Background(), newUser.Username)
+	require.NoError(t, err)
+
+	for _, s := range sessions {
+		session, err := testQueries.GetSession(context.Background(), s.ID)
+
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+}
+
+func TestDeleteUserSessions(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	err := testQueries.DeleteUserSessions(context.Background(), newUser.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSession(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestBlockOtherUserSessions(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+
+	currentSession := createRandomSession(t, newUser)
+	otherSession := createRandomSession(t, newUser)
+
+	err := testQueries.BlockOtherUserSessions(context.Background(), BlockOtherUserSessionsParams{
+		Username:         newUser.Username,
+		CurrentSessionID: currentSession.ID,
+	})
+	require.NoError(t, err)
+
+	session, err := testQueries.GetSession(context.Background(), currentSession.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+
+	session, err = testQueries.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	// unknown current session blocks all of them
+	err = testQueries.BlockOtherUserSessions(context.Background(), BlockOtherUserSessionsParams{
+		Username:         newUser.Username,
+		CurrentSessionID: uuid.Nil,
+	})
+	require.NoError(t, err)
+
+	session, err = testQueries.GetSession(context.Background(), currentSession.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+}
+
+func TestGetUserSessions(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+
+	const sessionsCount = 5
+	for i := 0; i < sessionsCount; i++ {
+		createRandomSession(t, newUser)
+	}
+
+	params := GetUserSessionsParams{
+		Username: newUser.Username,
+		Limit:    3,
+		Offset:   2,
+	}
+
+	sessions, err := testQueries.GetUserSessions(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	for i := 1; i < len(sessions); i++ {
+		require.False(t, sessions[i].CreatedAt.After(sessions[i-1].CreatedAt))
+	}
+}
+
+func TestGetUserSession(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	params := GetUserSessionParams{
+		ID:       session.ID,
+		Username: newUser.Username,
+	}
+
+	actualSession, err := testQueries.GetUserSession(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, session.ID, actualSession.ID)
+	require.Equal(t, session.UserAgent, actualSession.UserAgent)
+	require.Equal(t, session.ClientIp, actualSession.ClientIp)
+
+	// rotated session is replaced by its child, so it isn't listed anymore
+	_, err = testQueries.RotateSession(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserSession(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
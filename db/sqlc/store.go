@@ -3,14 +3,74 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const DefaultLIstHeader = "default"
 
+// roles of users
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// roles of workspace members, owner is the only one who can delete workspace
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// PositionGap is the distance between positions of items appended to the end,
+// it leaves room to put moved items in between without touching the others
+const PositionGap = 1024
+
+var ErrSessionReused = errors.New("session has already been rotated")
+
+var ErrNotSibling = errors.New("item can be placed only after item of the same parent")
+
+// IsUniqueViolation reports whether err is caused by unique constraint of db
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation"
+}
+
+// IsTaskParentNotInList reports whether err is caused by parent task which doesn't exist in the list of task
+func IsTaskParentNotInList(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Constraint == "tasks_parent_task_list"
+}
+
+// IsTaskParentCycle reports whether err is caused by task becoming descendant of itself
+func IsTaskParentCycle(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Constraint == "tasks_parent_task_cycle"
+}
+
 // Provides all functions to execute db queries and transactions
 type Store interface {
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (EnableTOTPTxResult, error)
+	DisableTOTPTx(ctx context.Context, userID int32) error
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResult, error)
+	CreateIdentityUserTx(ctx context.Context, arg CreateIdentityUserTxParams) (CreateIdentityUserTxResult, error)
+	ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (ChangeEmailTxResult, error)
+	VerifyEmailTx(ctx context.Context, tokenHash []byte) (VerifyEmailTxResult, error)
+	UpdateProfileTx(ctx context.Context, arg UpdateUserProfileParams) (UpdateProfileTxResult, error)
+	DeleteUserTx(ctx context.Context, arg DeleteUserTxParams) (DeleteUserTxResult, error)
+	DisableUserTx(ctx context.Context, userID int32) (DisableUserTxResult, error)
+	CreateWorkspaceTx(ctx context.Context, arg CreateWorkspaceTxParams) (CreateWorkspaceTxResult, error)
+	AcceptWorkspaceInvitationTx(ctx context.Context, arg TakeWorkspaceInvitationParams) (AcceptWorkspaceInvitationTxResult, error)
+	MoveTaskTx(ctx context.Context, arg MoveTaskTxParams) (MoveTaskTxResult, error)
+	MoveListTx(ctx context.Context, arg MoveListTxParams) (MoveListTxResult, error)
+	SetListWorkspaceTx(ctx context.Context, arg SetListWorkspaceParams) (SetListWorkspaceTxResult, error)
 	Querier
 }
 
@@ -48,8 +108,12 @@ func (store *SQLStore) execTx(ctx context.Context, callback func(*Queries) error
 }
 
 type CreateUserTxParams struct {
-	Username string `json:"username"`
-	Hash     []byte `json:"hash"`
+	Username string         `json:"username"`
+	Hash     []byte         `json:"hash"`
+	Email    sql.NullString `json:"email"`
+	// verification token is created only if email is set
+	VerificationTokenHash []byte    `json:"verification_token_hash"`
+	VerificationExpiresAt time.Time `json:"verification_expires_at"`
 }
 
 type CreateUserTxResult struct {
@@ -57,7 +121,7 @@ type CreateUserTxResult struct {
 	List List `json:"list"`
 }
 
-// Create a new user with one default list
+// Create a new user with one default list and verification token of its email
 func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
 	var result CreateUserTxResult
 
@@ -65,7 +129,11 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 		var err error
 
 		// create user
-		result.User, err = q.CreateUser(ctx, CreateUserParams(arg))
+		result.User, err = q.CreateUser(ctx, CreateUserParams{
+			Username: arg.Username,
+			Hash:     arg.Hash,
+			Email:    arg.Email,
+		})
 
 		if err != nil {
 			return err
@@ -77,8 +145,757 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 			Header: DefaultLIstHeader,
 		})
 
+		if err != nil || !arg.Email.Valid {
+			return err
+		}
+
+		_, err = q.CreateEmailVerificationToken(ctx, CreateEmailVerificationTokenParams{
+			TokenHash: arg.VerificationTokenHash,
+			UserID:    result.User.ID,
+			Email:     arg.Email.String,
+			ExpiresAt: arg.VerificationExpiresAt,
+		})
+
 		return err
 	})
 
 	return result, err
 }
+
+type RotateSessionTxParams struct {
+	SessionID  uuid.UUID           `json:"session_id"`
+	NewSession CreateSessionParams `json:"new_session"`
+}
+
+type RotateSessionTxResult struct {
+	Session Session `json:"session"`
+}
+
+// Mark session as rotated and create the next session of its family.
+// Returns ErrSessionReused if session has been rotated already
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		prevSession, err := q.RotateSession(ctx, arg.SessionID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSessionReused
+			}
+
+			return err
+		}
+
+		// link new session to the previous one
+		arg.NewSession.FamilyID = prevSession.FamilyID
+		arg.NewSession.ParentID = uuid.NullUUID{
+			UUID:  prevSession.ID,
+			Valid: true,
+		}
+
+		result.Session, err = q.CreateSession(ctx, arg.NewSession)
+
+		return err
+	})
+
+	return result, err
+}
+
+type EnableTOTPTxParams struct {
+	UserID             int32    `json:"user_id"`
+	LastUsedStep       int64    `json:"last_used_step"`
+	RecoveryCodeHashes [][]byte `json:"recovery_code_hashes"`
+}
+
+type EnableTOTPTxResult struct {
+	TOTP UserTotp `json:"totp"`
+}
+
+// Enable enrolled TOTP of user and replace its recovery codes.
+// Returns sql.ErrNoRows if TOTP isn't enrolled or is already enabled
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (EnableTOTPTxResult, error) {
+	var result EnableTOTPTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.TOTP, err = q.EnableUserTOTP(ctx, EnableUserTOTPParams{
+			UserID:       arg.UserID,
+			LastUsedStep: arg.LastUsedStep,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteRecoveryCodes(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		for _, hash := range arg.RecoveryCodeHashes {
+			err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				UserID:   arg.UserID,
+				CodeHash: hash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Remove TOTP of user with its recovery codes
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, userID int32) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.DeleteUserTOTP(ctx, userID); err != nil {
+			return err
+		}
+
+		return q.DeleteRecoveryCodes(ctx, userID)
+	})
+}
+
+type ResetPasswordTxParams struct {
+	TokenHash []byte `json:"token_hash"`
+	NewHash   []byte `json:"new_hash"`
+}
+
+type ResetPasswordTxResult struct {
+	User User `json:"user"`
+}
+
+// Use password reset token, set new password hash of its user and block all sessions of the user.
+// Returns sql.ErrNoRows if token doesn't exist, is expired or has been used already
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		resetToken, err := q.UsePasswordResetToken(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.SetUserHash(ctx, SetUserHashParams{
+			ID:   resetToken.UserID,
+			Hash: arg.NewHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		// other requested tokens mustn't outlive the reset
+		if err := q.DeletePasswordResetTokens(ctx, resetToken.UserID); err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, result.User.Username)
+	})
+
+	return result, err
+}
+
+type ChangePasswordTxParams struct {
+	ID               int32     `json:"id"`
+	OldHash          []byte    `json:"old_hash"`
+	NewHash          []byte    `json:"new_hash"`
+	CurrentSessionID uuid.UUID `json:"current_session_id"`
+}
+
+type ChangePasswordTxResult struct {
+	User User `json:"user"`
+}
+
+// Replace password hash of user and block its sessions except the family of current one.
+// Returns sql.ErrNoRows if hash has been changed since it was checked
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResult, error) {
+	var result ChangePasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.RehashUser(ctx, RehashUserParams{
+			ID:      arg.ID,
+			NewHash: arg.NewHash,
+			OldHash: arg.OldHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.BlockOtherUserSessions(ctx, BlockOtherUserSessionsParams{
+			Username:         result.User.Username,
+			CurrentSessionID: arg.CurrentSessionID,
+		})
+	})
+
+	return result, err
+}
+
+type CreateIdentityUserTxParams struct {
+	Username string         `json:"username"`
+	Email    sql.NullString `json:"email"`
+	Issuer   string         `json:"issuer"`
+	Subject  string         `json:"subject"`
+}
+
+type CreateIdentityUserTxResult struct {
+	User     User         `json:"user"`
+	List     List         `json:"list"`
+	Identity UserIdentity `json:"identity"`
+}
+
+// Create a new user without password, with one default list and linked external identity
+func (store *SQLStore) CreateIdentityUserTx(ctx context.Context, arg CreateIdentityUserTxParams) (CreateIdentityUserTxResult, error) {
+	var result CreateIdentityUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// empty hash doesn't match any password, email is verified by provider
+		result.User, err = q.CreateUser(ctx, CreateUserParams{
+			Username:      arg.Username,
+			Hash:          []byte{},
+			Email:         arg.Email,
+			EmailVerified: arg.Email.Valid,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.List, err = q.AddList(ctx, AddListParams{
+			Author: result.User.ID,
+			Header: DefaultLIstHeader,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Identity, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			Issuer:  arg.Issuer,
+			Subject: arg.Subject,
+			UserID:  result.User.ID,
+			Email:   arg.Email,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+type ChangeEmailTxParams struct {
+	UserID                int32     `json:"user_id"`
+	Email                 string    `json:"email"`
+	VerificationTokenHash []byte    `json:"verification_token_hash"`
+	VerificationExpiresAt time.Time `json:"verification_expires_at"`
+}
+
+type ChangeEmailTxResult struct {
+	VerificationToken EmailVerificationToken `json:"verification_token"`
+}
+
+// Replace verification tokens of user with a token for the new email. Email of user is kept
+// until the token is used, the new email is stored only in the token
+func (store *SQLStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (ChangeEmailTxResult, error) {
+	var result ChangeEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := q.DeleteEmailVerificationTokens(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		var err error
+
+		result.VerificationToken, err = q.CreateEmailVerificationToken(ctx, CreateEmailVerificationTokenParams{
+			TokenHash: arg.VerificationTokenHash,
+			UserID:    arg.UserID,
+			Email:     arg.Email,
+			ExpiresAt: arg.VerificationExpiresAt,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+type VerifyEmailTxResult struct {
+	User User `json:"user"`
+}
+
+// Use email verification token and set its email as verified email of the user.
+// Returns sql.ErrNoRows if token doesn't exist, is expired or has been replaced by a newer one,
+// unique violation if the email has been taken by other user since the token was sent
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, tokenHash []byte) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		verificationToken, err := q.UseEmailVerificationToken(ctx, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.SetVerifiedUserEmail(ctx, SetVerifiedUserEmailParams{
+			ID:    verificationToken.UserID,
+			Email: sql.NullString{String: verificationToken.Email, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteEmailVerificationTokens(ctx, verificationToken.UserID)
+	})
+
+	return result, err
+}
+
+type UpdateProfileTxResult struct {
+	User User `json:"user"`
+}
+
+// Update profile fields of user which are set. Username change blocks all sessions of user
+// because their tokens carry the old username
+func (store *SQLStore) UpdateProfileTx(ctx context.Context, arg UpdateUserProfileParams) (UpdateProfileTxResult, error) {
+	var result UpdateProfileTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUserProfile(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if !arg.Username.Valid {
+			return nil
+		}
+
+		// sessions already follow the new username by cascade
+		return q.BlockUserSessions(ctx, result.User.Username)
+	})
+
+	return result, err
+}
+
+type DeleteUserTxParams struct {
+	UserID int32 `json:"user_id"`
+	// lists and tasks authored by user, in workspaces too, are read before they are deleted or reassigned
+	Export bool `json:"export"`
+}
+
+type ExportedList struct {
+	GetAuthoredListsRow
+	Tasks []Task `json:"tasks"`
+}
+
+type DeleteUserTxResult struct {
+	User User `json:"user"`
+	// only if export is requested
+	Lists []ExportedList `json:"lists"`
+}
+
+// Delete user with its sessions, lists and tasks. Everything else of user is deleted by cascade.
+// Workspaces owned by user are deleted too, its lists in other workspaces are passed to their owners.
+// Returns sql.ErrNoRows if user doesn't exist
+func (store *SQLStore) DeleteUserTx(ctx context.Context, arg DeleteUserTxParams) (DeleteUserTxResult, error) {
+	var result DeleteUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUserByID(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Export {
+			lists, err := q.GetAuthoredLists(ctx, arg.UserID)
+			if err != nil {
+				return err
+			}
+
+			result.Lists = make([]ExportedList, 0, len(lists))
+			for _, list := range lists {
+				tasks, err := q.GetTasks(ctx, list.ID)
+				if err != nil {
+					return err
+				}
+
+				result.Lists = append(result.Lists, ExportedList{
+					GetAuthoredListsRow: list,
+					Tasks:               tasks,
+				})
+			}
+		}
+
+		// sessions reference username without delete cascade
+		if err := q.DeleteUserSessions(ctx, result.User.Username); err != nil {
+			return err
+		}
+
+		if err := q.ReassignWorkspaceLists(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		if err := q.DeleteOwnedWorkspaces(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		if err := q.DeleteUserLists(ctx, arg.UserID); err != nil {
+			return err
+		}
+
+		_, err = q.DeleteUser(ctx, arg.UserID)
+		return err
+	})
+
+	return result, err
+}
+
+type DisableUserTxResult struct {
+	User User `json:"user"`
+}
+
+// Mark user as disabled and block all its sessions. Data of user is kept
+func (store *SQLStore) DisableUserTx(ctx context.Context, userID int32) (DisableUserTxResult, error) {
+	var result DisableUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.DisableUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, result.User.Username)
+	})
+
+	return result, err
+}
+
+type CreateWorkspaceTxParams struct {
+	Name    string `json:"name"`
+	OwnerID int32  `json:"owner_id"`
+}
+
+type CreateWorkspaceTxResult struct {
+	Workspace Workspace       `json:"workspace"`
+	Owner     WorkspaceMember `json:"owner"`
+}
+
+// Create workspace with its creator as owner
+func (store *SQLStore) CreateWorkspaceTx(ctx context.Context, arg CreateWorkspaceTxParams) (CreateWorkspaceTxResult, error) {
+	var result CreateWorkspaceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Workspace, err = q.CreateWorkspace(ctx, arg.Name)
+		if err != nil {
+			return err
+		}
+
+		params := AddWorkspaceMemberParams{
+			WorkspaceID: result.Workspace.ID,
+			UserID:      arg.OwnerID,
+			Role:        WorkspaceRoleOwner,
+		}
+
+		result.Owner, err = q.AddWorkspaceMember(ctx, params)
+		return err
+	})
+
+	return result, err
+}
+
+type AcceptWorkspaceInvitationTxResult struct {
+	Member WorkspaceMember `json:"member"`
+}
+
+// Make invited user member of workspace with invited role, invitation can be accepted once.
+// Returns sql.ErrNoRows if user doesn't have such invitation or it is expired
+func (store *SQLStore) AcceptWorkspaceInvitationTx(ctx context.Context, arg TakeWorkspaceInvitationParams) (AcceptWorkspaceInvitationTxResult, error) {
+	var result AcceptWorkspaceInvitationTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		invitation, err := q.TakeWorkspaceInvitation(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		params := AddWorkspaceMemberParams{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      invitation.UserID,
+			Role:        invitation.Role,
+		}
+
+		result.Member, err = q.AddWorkspaceMember(ctx, params)
+		return err
+	})
+
+	return result, err
+}
+
+// positionBetween returns position of item moved between prev and next ones, missing neighbour means
+// the item is moved to the edge. ok is false when there is no room left between neighbours
+func positionBetween(prev sql.NullFloat64, next sql.NullFloat64, current float64) (position float64, ok bool) {
+	switch {
+	case !prev.Valid && !next.Valid:
+		return current, true
+	case !prev.Valid:
+		return next.Float64 - PositionGap, true
+	case !next.Valid:
+		return prev.Float64 + PositionGap, true
+	}
+
+	position = prev.Float64 + (next.Float64-prev.Float64)/2
+	return position, position > prev.Float64 && position < next.Float64
+}
+
+type MoveTaskTxParams struct {
+	ID     int32 `json:"id"`
+	ListID int32 `json:"list_id"`
+	// task becomes the first child of its parent if After is zero
+	After int32 `json:"after"`
+}
+
+type MoveTaskTxResult struct {
+	Task Task `json:"task"`
+}
+
+// Place task after another task of the same parent. Only the moved task changes its position
+// unless there is no room left between neighbours, then all siblings get even positions again.
+// Moves within the same list wait for each other, so they never compute the same position.
+// Returns ErrNotSibling if task to place after isn't a sibling of the moved one
+func (store *SQLStore) MoveTaskTx(ctx context.Context, arg MoveTaskTxParams) (MoveTaskTxResult, error) {
+	var result MoveTaskTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := q.LockList(ctx, arg.ListID); err != nil {
+			return err
+		}
+
+		task, err := q.GetTask(ctx, GetTaskParams{ID: arg.ID, ListID: arg.ListID})
+		if err != nil {
+			return err
+		}
+
+		position, ok, err := q.taskPositionAfter(ctx, task, arg.After)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			rebalanceParams := RebalanceTaskPositionsParams{
+				ListID:     task.ListID,
+				ParentTask: task.ParentTask,
+			}
+
+			if err := q.RebalanceTaskPositions(ctx, rebalanceParams); err != nil {
+				return err
+			}
+
+			position, _, err = q.taskPositionAfter(ctx, task, arg.After)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Task, err = q.SetTaskPosition(ctx, SetTaskPositionParams{ID: task.ID, Position: position})
+		return err
+	})
+
+	return result, err
+}
+
+func (q *Queries) taskPositionAfter(ctx context.Context, task Task, after int32) (float64, bool, error) {
+	var prev sql.NullFloat64
+
+	if after != 0 {
+		afterTask, err := q.GetTask(ctx, GetTaskParams{ID: after, ListID: task.ListID})
+		if err == sql.ErrNoRows || (err == nil && afterTask.ParentTask != task.ParentTask) {
+			return 0, false, ErrNotSibling
+		}
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		prev = sql.NullFloat64{Float64: afterTask.Position, Valid: true}
+	}
+
+	params := GetNextTaskPositionParams{
+		ListID:     task.ListID,
+		ParentTask: task.ParentTask,
+		ID:         task.ID,
+		After:      prev,
+		AfterID:    after,
+	}
+
+	var next sql.NullFloat64
+
+	nextPosition, err := q.GetNextTaskPosition(ctx, params)
+	switch err {
+	case nil:
+		next = sql.NullFloat64{Float64: nextPosition, Valid: true}
+	case sql.ErrNoRows:
+	default:
+		return 0, false, err
+	}
+
+	position, ok := positionBetween(prev, next, task.Position)
+	return position, ok, nil
+}
+
+type MoveListTxParams struct {
+	ID int32 `json:"id"`
+	// list becomes the first one of its space if After is zero
+	After int32 `json:"after"`
+}
+
+type MoveListTxResult struct {
+	List List `json:"list"`
+}
+
+// Place list after another list of the same space, personal lists of the author or lists of the workspace.
+// Rebalances positions and waits for other moves in the same space the same way as MoveTaskTx.
+// Returns ErrNotSibling if list to place after is from another space
+func (store *SQLStore) MoveListTx(ctx context.Context, arg MoveListTxParams) (MoveListTxResult, error) {
+	var result MoveListTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		list, err := q.lockListSpace(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		position, ok, err := q.listPositionAfter(ctx, list, arg.After)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			rebalanceParams := RebalanceListPositionsParams{
+				WorkspaceID: list.WorkspaceID,
+				Author:      list.Author,
+			}
+
+			if err := q.RebalanceListPositions(ctx, rebalanceParams); err != nil {
+				return err
+			}
+
+			position, _, err = q.listPositionAfter(ctx, list, arg.After)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.List, err = q.SetListPosition(ctx, SetListPositionParams{ID: list.ID, Position: position})
+		return err
+	})
+
+	return result, err
+}
+
+func (q *Queries) listPositionAfter(ctx context.Context, list List, after int32) (float64, bool, error) {
+	var prev sql.NullFloat64
+
+	if after != 0 {
+		afterList, err := q.GetList(ctx, after)
+		if err == sql.ErrNoRows || (err == nil && !sameListSpace(afterList, list)) {
+			return 0, false, ErrNotSibling
+		}
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		prev = sql.NullFloat64{Float64: afterList.Position, Valid: true}
+	}
+
+	params := GetNextListPositionParams{
+		WorkspaceID: list.WorkspaceID,
+		Author:      list.Author,
+		ID:          list.ID,
+		After:       prev,
+		AfterID:     after,
+	}
+
+	var next sql.NullFloat64
+
+	nextPosition, err := q.GetNextListPosition(ctx, params)
+	switch err {
+	case nil:
+		next = sql.NullFloat64{Float64: nextPosition, Valid: true}
+	case sql.ErrNoRows:
+	default:
+		return 0, false, err
+	}
+
+	position, ok := positionBetween(prev, next, list.Position)
+	return position, ok, nil
+}
+
+// lockListSpace locks the workspace or the author of personal list until the end of transaction
+// and returns the list read after that, the list is read again if it has left the locked space meanwhile
+func (q *Queries) lockListSpace(ctx context.Context, id int32) (List, error) {
+	list, err := q.GetList(ctx, id)
+
+	for err == nil {
+		if list.WorkspaceID.Valid {
+			err = q.LockWorkspace(ctx, list.WorkspaceID.Int32)
+		} else {
+			err = q.LockUser(ctx, list.Author)
+		}
+
+		if err != nil {
+			return List{}, err
+		}
+
+		var locked List
+		locked, err = q.GetList(ctx, id)
+		if err == nil && sameListSpace(locked, list) {
+			return locked, nil
+		}
+
+		list = locked
+	}
+
+	return List{}, err
+}
+
+// sameListSpace reports whether both lists are in the same workspace or both are personal lists of the same user
+func sameListSpace(a List, b List) bool {
+	if a.WorkspaceID.Valid || b.WorkspaceID.Valid {
+		return a.WorkspaceID == b.WorkspaceID
+	}
+
+	return a.Author == b.Author
+}
+
+type SetListWorkspaceTxResult struct {
+	List List `json:"list"`
+}
+
+// Move list to workspace or to personal space of author. Share links of the list are revoked,
+// otherwise they would keep exposing it while workspace members can neither see nor revoke them
+func (store *SQLStore) SetListWorkspaceTx(ctx context.Context, arg SetListWorkspaceParams) (SetListWorkspaceTxResult, error) {
+	var result SetListWorkspaceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.List, err = q.SetListWorkspace(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteListShares(ctx, arg.ID)
+	})
+
+	return result, err
+}
@@ -2,9 +2,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"math"
 	"testing"
+	"time"
 
+	dbtypes "github.com/PYTNAG/simpletodo/db/types"
 	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -66,3 +71,757 @@ func TestCreateUserTx(t *testing.T) {
 		deleteTestUser(t, &result.User)
 	}
 }
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	newSessionParams := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     newUser.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    session.UserAgent,
+		ClientIp:     session.ClientIp,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	result, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: newSessionParams,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, newSessionParams.ID, result.Session.ID)
+	require.Equal(t, session.FamilyID, result.Session.FamilyID)
+	require.True(t, result.Session.ParentID.Valid)
+	require.Equal(t, session.ID, result.Session.ParentID.UUID)
+	require.False(t, result.Session.IsRotated)
+
+	prevSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, prevSession.IsRotated)
+
+	// second rotation of the same session is a reuse
+	newSessionParams.ID = uuid.New()
+
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: newSessionParams,
+	})
+
+	require.ErrorIs(t, err, ErrSessionReused)
+
+	_, err = store.GetSession(context.Background(), newSessionParams.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestEnableAndDisableTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	createRandomUserTOTP(t, newUser)
+
+	hashes := [][]byte{
+		util.HashRecoveryCode(util.RandomString(16)),
+		util.HashRecoveryCode(util.RandomString(16)),
+	}
+
+	result, err := store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{
+		UserID:             newUser.ID,
+		LastUsedStep:       1,
+		RecoveryCodeHashes: hashes,
+	})
+
+	require.NoError(t, err)
+	require.True(t, result.TOTP.IsEnabled)
+
+	// already enabled
+	_, err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{UserID: newUser.ID, LastUsedStep: 2})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	used, err := store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{UserID: newUser.ID, CodeHash: hashes[0]})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	err = store.DisableTOTPTx(context.Background(), newUser.ID)
+	require.NoError(t, err)
+
+	_, err = store.GetUserTOTP(context.Background(), newUser.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	used, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{UserID: newUser.ID, CodeHash: hashes[1]})
+	require.NoError(t, err)
+	require.Zero(t, used)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+	resetToken := createRandomPasswordResetToken(t, newUser, time.Hour)
+	otherResetToken := createRandomPasswordResetToken(t, newUser, time.Hour)
+
+	newHash, err := util.HashPassword(util.RandomPassword())
+	require.NoError(t, err)
+
+	result, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash: resetToken.TokenHash,
+		NewHash:   newHash,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, newUser.ID, result.User.ID)
+	require.Equal(t, newHash, result.User.Hash)
+
+	blockedSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	// used token and other tokens of the user can't be used anymore
+	for _, tokenHash := range [][]byte{resetToken.TokenHash, otherResetToken.TokenHash} {
+		_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+			TokenHash: tokenHash,
+			NewHash:   newHash,
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	currentSession := createRandomSession(t, newUser)
+	otherSession := createRandomSession(t, newUser)
+
+	newHash, err := util.HashPassword(util.RandomPassword())
+	require.NoError(t, err)
+
+	params := ChangePasswordTxParams{
+		ID:               newUser.ID,
+		OldHash:          newUser.Hash,
+		NewHash:          newHash,
+		CurrentSessionID: currentSession.ID,
+	}
+
+	result, err := store.ChangePasswordTx(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, newHash, result.User.Hash)
+
+	session, err := store.GetSession(context.Background(), currentSession.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+
+	session, err = store.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	// old hash doesn't match anymore
+	_, err = store.ChangePasswordTx(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateIdentityUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	params := CreateIdentityUserTxParams{
+		Username: util.RandomUsername(),
+		Email:    sql.NullString{String: util.RandomEmail(), Valid: true},
+		Issuer:   "https://" + util.RandomUsername() + ".example.com",
+		Subject:  uuid.NewString(),
+	}
+
+	result, err := store.CreateIdentityUserTx(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.Username, result.User.Username)
+	require.Equal(t, params.Email, result.User.Email)
+	require.Equal(t, result.User.ID, result.List.Author)
+	require.Equal(t, DefaultLIstHeader, result.List.Header)
+	require.Equal(t, result.User.ID, result.Identity.UserID)
+
+	// user without password can't log in with any
+	require.Error(t, util.CheckPassword("", result.User.Hash))
+
+	user, err := store.GetUserByIdentity(context.Background(), GetUserByIdentityParams{
+		Issuer:  params.Issuer,
+		Subject: params.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.User, user)
+
+	// username is taken, nothing is created
+	params.Subject = uuid.NewString()
+	params.Email = sql.NullString{}
+
+	_, err = store.CreateIdentityUserTx(context.Background(), params)
+	require.True(t, IsUniqueViolation(err))
+
+	_, err = store.GetUserByIdentity(context.Background(), GetUserByIdentityParams{
+		Issuer:  params.Issuer,
+		Subject: params.Subject,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateUserTxWithEmail(t *testing.T) {
+	store := NewStore(testDB)
+
+	hash, err := util.HashPassword(util.RandomPassword())
+	require.NoError(t, err)
+
+	params := CreateUserTxParams{
+		Username:              util.RandomString(24),
+		Hash:                  hash,
+		Email:                 sql.NullString{String: util.RandomEmail(), Valid: true},
+		VerificationTokenHash: util.HashMailToken(util.RandomString(32)),
+		VerificationExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	result, err := store.CreateUserTx(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.Email, result.User.Email)
+	require.False(t, result.User.EmailVerified)
+
+	verified, err := store.VerifyEmailTx(context.Background(), params.VerificationTokenHash)
+	require.NoError(t, err)
+	require.True(t, verified.User.EmailVerified)
+
+	deleteTestUser(t, &result.User)
+}
+
+func TestChangeEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	oldToken := createRandomEmailVerificationToken(t, newUser, time.Hour)
+
+	params := ChangeEmailTxParams{
+		UserID:                newUser.ID,
+		Email:                 util.RandomEmail(),
+		VerificationTokenHash: util.HashMailToken(util.RandomString(32)),
+		VerificationExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	result, err := store.ChangeEmailTx(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, params.Email, result.VerificationToken.Email)
+
+	// email of user isn't changed until verification
+	user, err := store.GetUser(context.Background(), newUser.Username)
+	require.NoError(t, err)
+	require.Equal(t, newUser.Email, user.Email)
+
+	// token of previous email is replaced
+	_, err = store.UseEmailVerificationToken(context.Background(), oldToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// other user can request the same email while it isn't verified
+	otherUser, _ := createRandomUser(t, false)
+	otherParams := params
+	otherParams.UserID = otherUser.ID
+	otherParams.VerificationTokenHash = util.HashMailToken(util.RandomString(32))
+
+	_, err = store.ChangeEmailTx(context.Background(), otherParams)
+	require.NoError(t, err)
+
+	verifyResult, err := store.VerifyEmailTx(context.Background(), params.VerificationTokenHash)
+	require.NoError(t, err)
+	require.Equal(t, sql.NullString{String: params.Email, Valid: true}, verifyResult.User.Email)
+	require.True(t, verifyResult.User.EmailVerified)
+
+	// but can't take it after it has been verified
+	_, err = store.VerifyEmailTx(context.Background(), otherParams.VerificationTokenHash)
+	require.True(t, IsUniqueViolation(err))
+}
+
+func TestVerifyEmailTxReplacedToken(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	verificationToken := createRandomEmailVerificationToken(t, newUser, time.Hour)
+
+	_, err := store.ChangeEmailTx(context.Background(), ChangeEmailTxParams{
+		UserID:                newUser.ID,
+		Email:                 util.RandomEmail(),
+		VerificationTokenHash: util.HashMailToken(util.RandomString(32)),
+		VerificationExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.VerifyEmailTx(context.Background(), verificationToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	user, err := store.GetUser(context.Background(), newUser.Username)
+	require.NoError(t, err)
+	require.Equal(t, newUser.Email, user.Email)
+	require.False(t, user.EmailVerified)
+}
+
+func TestUpdateProfileTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, _ := createRandomUser(t, false)
+	session := createRandomSession(t, newUser)
+
+	params := UpdateUserProfileParams{
+		ID:       newUser.ID,
+		Username: sql.NullString{String: util.RandomUsername(), Valid: true},
+		Locale:   sql.NullString{String: "de-DE", Valid: true},
+	}
+
+	result, err := store.UpdateProfileTx(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.Username.String, result.User.Username)
+	require.Equal(t, params.Locale.String, result.User.Locale)
+
+	// session follows the new username but is blocked
+	updatedSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Equal(t, params.Username.String, updatedSession.Username)
+	require.True(t, updatedSession.IsBlocked)
+
+	// username of other user is taken
+	otherUser, _ := createRandomUser(t, false)
+
+	_, err = store.UpdateProfileTx(context.Background(), UpdateUserProfileParams{
+		ID:       otherUser.ID,
+		Username: params.Username,
+	})
+	require.True(t, IsUniqueViolation(err))
+
+	deleteTestUser(t, otherUser)
+}
+
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, list := createRandomUser(t, true)
+	task := createRandomTask(t, list, nil)
+	session := createRandomSession(t, newUser)
+
+	result, err := store.DeleteUserTx(context.Background(), DeleteUserTxParams{
+		UserID: newUser.ID,
+		Export: true,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, newUser.Username, result.User.Username)
+	require.Len(t, result.Lists, 1)
+	require.Equal(t, list.ID, result.Lists[0].ID)
+	require.Equal(t, []Task{*task}, result.Lists[0].Tasks)
+
+	_, err = store.GetSession(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	lists, err := store.GetLists(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Empty(t, lists)
+
+	_, err = store.GetUserByID(context.Background(), newUser.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// user is deleted already
+	_, err = store.DeleteUserTx(context.Background(), DeleteUserTxParams{UserID: newUser.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDisableUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, list := createRandomUser(t, true)
+	session := createRandomSession(t, newUser)
+
+	result, err := store.DisableUserTx(context.Background(), newUser.ID)
+
+	require.NoError(t, err)
+	require.True(t, result.User.DisabledAt.Valid)
+
+	blockedSession, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blockedSession.IsBlocked)
+
+	// data is kept
+	lists, err := store.GetLists(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+	require.Equal(t, list.ID, lists[0].ID)
+
+	_, err = store.DisableUserTx(context.Background(), 0)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func createRandomWorkspace(t *testing.T, owner *User) *Workspace {
+	store := NewStore(testDB)
+
+	params := CreateWorkspaceTxParams{
+		Name:    util.RandomString(8),
+		OwnerID: owner.ID,
+	}
+
+	result, err := store.CreateWorkspaceTx(context.Background(), params)
+
+	require.NoError(t, err)
+	require.NotZero(t, result.Workspace.ID)
+	require.Equal(t, params.Name, result.Workspace.Name)
+	require.Equal(t, result.Workspace.ID, result.Owner.WorkspaceID)
+	require.Equal(t, owner.ID, result.Owner.UserID)
+	require.Equal(t, WorkspaceRoleOwner, result.Owner.Role)
+
+	return &result.Workspace
+}
+
+func TestAcceptWorkspaceInvitationTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	owner, _ := createRandomUser(t, false)
+	invitee, _ := createRandomUser(t, false)
+	workspace := createRandomWorkspace(t, owner)
+
+	invitation, err := store.CreateWorkspaceInvitation(context.Background(), CreateWorkspaceInvitationParams{
+		ID:          uuid.New(),
+		WorkspaceID: workspace.ID,
+		UserID:      invitee.ID,
+		Role:        WorkspaceRoleAdmin,
+		InvitedBy:   owner.ID,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	params := TakeWorkspaceInvitationParams{ID: invitation.ID, UserID: invitee.ID}
+
+	result, err := store.AcceptWorkspaceInvitationTx(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, workspace.ID, result.Member.WorkspaceID)
+	require.Equal(t, invitee.ID, result.Member.UserID)
+	require.Equal(t, WorkspaceRoleAdmin, result.Member.Role)
+
+	// invitation can be accepted once
+	_, err = store.AcceptWorkspaceInvitationTx(context.Background(), params)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, invitee)
+	deleteTestUser(t, owner)
+}
+
+func TestDeleteUserTxWithWorkspaces(t *testing.T) {
+	store := NewStore(testDB)
+
+	owner, _ := createRandomUser(t, false)
+	member, _ := createRandomUser(t, false)
+
+	sharedWorkspace := createRandomWorkspace(t, owner)
+	ownWorkspace := createRandomWorkspace(t, member)
+
+	_, err := store.AddWorkspaceMember(context.Background(), AddWorkspaceMemberParams{
+		WorkspaceID: sharedWorkspace.ID,
+		UserID:      member.ID,
+		Role:        WorkspaceRoleMember,
+	})
+	require.NoError(t, err)
+
+	sharedList, err := store.AddWorkspaceList(context.Background(), AddWorkspaceListParams{
+		Author:      member.ID,
+		WorkspaceID: sharedWorkspace.ID,
+		Header:      util.RandomString(8),
+	})
+	require.NoError(t, err)
+
+	result, err := store.DeleteUserTx(context.Background(), DeleteUserTxParams{UserID: member.ID, Export: true})
+	require.NoError(t, err)
+
+	// export has lists which member authored in workspaces
+	require.Len(t, result.Lists, 1)
+	require.Equal(t, sharedList.ID, result.Lists[0].ID)
+	require.Equal(t, sharedWorkspace.ID, result.Lists[0].WorkspaceID.Int32)
+
+	// list of deleted member is kept in workspace and passed to owner
+	lists, err := store.GetWorkspaceLists(context.Background(), sharedWorkspace.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+	require.Equal(t, sharedList.ID, lists[0].ID)
+
+	_, err = store.GetWorkspace(context.Background(), ownWorkspace.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, owner)
+}
+
+func taskIds(t *testing.T, store Store, listId int32) []int32 {
+	tasks, err := store.GetTasks(context.Background(), listId)
+	require.NoError(t, err)
+
+	ids := make([]int32, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	return ids
+}
+
+func TestMoveTaskTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+
+	first := createRandomTask(t, defaultList, nil)
+	second := createRandomTask(t, defaultList, nil)
+	third := createRandomTask(t, defaultList, nil)
+	require.Equal(t, []int32{first.ID, second.ID, third.ID}, taskIds(t, store, defaultList.ID))
+
+	// to the middle
+	result, err := store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: third.ID, ListID: defaultList.ID, After: first.ID})
+	require.NoError(t, err)
+	require.Greater(t, result.Task.Position, first.Position)
+	require.Less(t, result.Task.Position, second.Position)
+	require.Equal(t, []int32{first.ID, third.ID, second.ID}, taskIds(t, store, defaultList.ID))
+
+	// to the beginning
+	_, err = store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: second.ID, ListID: defaultList.ID})
+	require.NoError(t, err)
+	require.Equal(t, []int32{second.ID, first.ID, third.ID}, taskIds(t, store, defaultList.ID))
+
+	// to the end
+	_, err = store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: second.ID, ListID: defaultList.ID, After: third.ID})
+	require.NoError(t, err)
+	require.Equal(t, []int32{first.ID, third.ID, second.ID}, taskIds(t, store, defaultList.ID))
+
+	// subtask isn't a sibling of top level task
+	child := createRandomTask(t, defaultList, first)
+	_, err = store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: child.ID, ListID: defaultList.ID, After: second.ID})
+	require.ErrorIs(t, err, ErrNotSibling)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestMoveTaskTxRebalance(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+
+	first := createRandomTask(t, defaultList, nil)
+	second := createRandomTask(t, defaultList, nil)
+	third := createRandomTask(t, defaultList, nil)
+
+	// the gap after the first task is halved until there is no room left in it
+	moved, other := third, second
+	for i := 0; i < 64; i++ {
+		_, err := store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: moved.ID, ListID: defaultList.ID, After: first.ID})
+		require.NoError(t, err)
+
+		moved, other = other, moved
+	}
+
+	tasks, err := store.GetTasks(context.Background(), defaultList.ID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	require.Equal(t, first.ID, tasks[0].ID)
+
+	for i := 1; i < len(tasks); i++ {
+		require.Greater(t, tasks[i].Position, tasks[i-1].Position)
+	}
+
+	deleteTestUser(t, newUser)
+}
+
+func TestMoveTaskTxTiedPositions(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+
+	first := createRandomTask(t, defaultList, nil)
+	second := createRandomTask(t, defaultList, nil)
+	third := createRandomTask(t, defaultList, nil)
+
+	_, err := store.SetTaskPosition(context.Background(), SetTaskPositionParams{ID: second.ID, Position: first.Position})
+	require.NoError(t, err)
+
+	// the tied task after the first one is the next neighbour, so positions are rebalanced
+	result, err := store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: third.ID, ListID: defaultList.ID, After: first.ID})
+	require.NoError(t, err)
+	require.Equal(t, []int32{first.ID, third.ID, second.ID}, taskIds(t, store, defaultList.ID))
+
+	tasks, err := store.GetTasks(context.Background(), defaultList.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Task.Position, tasks[1].Position)
+
+	for i := 1; i < len(tasks); i++ {
+		require.Greater(t, tasks[i].Position, tasks[i-1].Position)
+	}
+
+	deleteTestUser(t, newUser)
+}
+
+func TestMoveTaskTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+
+	first := createRandomTask(t, defaultList, nil)
+
+	n := 5
+	moved := make([]*Task, n)
+	for i := range moved {
+		moved[i] = createRandomTask(t, defaultList, nil)
+	}
+
+	// every task is put right after the first one at once, moves must not compute the same position
+	errs := make(chan error)
+	for _, task := range moved {
+		go func(task *Task) {
+			_, err := store.MoveTaskTx(context.Background(), MoveTaskTxParams{ID: task.ID, ListID: defaultList.ID, After: first.ID})
+			errs <- err
+		}(task)
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	tasks, err := store.GetTasks(context.Background(), defaultList.ID)
+	require.NoError(t, err)
+	require.Len(t, tasks, n+1)
+	require.Equal(t, first.ID, tasks[0].ID)
+
+	for i := 1; i < len(tasks); i++ {
+		require.Greater(t, tasks[i].Position, tasks[i-1].Position)
+	}
+
+	deleteTestUser(t, newUser)
+}
+
+func TestMoveListTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+
+	n := 5
+	moved := make([]*List, n)
+	for i := range moved {
+		moved[i] = createRandomList(t, newUser)
+	}
+
+	errs := make(chan error)
+	for _, list := range moved {
+		go func(list *List) {
+			_, err := store.MoveListTx(context.Background(), MoveListTxParams{ID: list.ID, After: defaultList.ID})
+			errs <- err
+		}(list)
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	lists, err := store.GetLists(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, n+1)
+	require.Equal(t, defaultList.ID, lists[0].ID)
+
+	positions := make(map[int32]float64, len(lists))
+	for _, list := range append(moved, defaultList) {
+		updated, err := store.GetList(context.Background(), list.ID)
+		require.NoError(t, err)
+		positions[list.ID] = updated.Position
+	}
+
+	for i := 1; i < len(lists); i++ {
+		require.Greater(t, positions[lists[i].ID], positions[lists[i-1].ID])
+	}
+
+	deleteTestUser(t, newUser)
+}
+
+func TestMoveListTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	newUser, defaultList := createRandomUser(t, true)
+	otherUser, otherList := createRandomUser(t, true)
+
+	list := createRandomList(t, newUser)
+
+	_, err := store.MoveListTx(context.Background(), MoveListTxParams{ID: list.ID})
+	require.NoError(t, err)
+
+	lists, err := store.GetLists(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+	require.Equal(t, list.ID, lists[0].ID)
+	require.Equal(t, defaultList.ID, lists[1].ID)
+
+	_, err = store.MoveListTx(context.Background(), MoveListTxParams{ID: list.ID, After: otherList.ID})
+	require.ErrorIs(t, err, ErrNotSibling)
+
+	deleteTestUser(t, newUser)
+	deleteTestUser(t, otherUser)
+}
+
+func TestSetListWorkspaceTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	owner, list := createRandomUser(t, true)
+	workspace := createRandomWorkspace(t, owner)
+
+	share := createRandomListShare(t, list, sql.NullTime{})
+
+	result, err := store.SetListWorkspaceTx(context.Background(), SetListWorkspaceParams{
+		ID:          list.ID,
+		WorkspaceID: dbtypes.NewNullInt32(workspace.ID, true),
+		Author:      owner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, workspace.ID, result.List.WorkspaceID.Int32)
+
+	// share links of the list don't work in workspace
+	shares, err := store.GetListShares(context.Background(), list.ID)
+	require.NoError(t, err)
+	require.Empty(t, shares)
+
+	_, err = store.GetSharedList(context.Background(), share.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.SetListWorkspaceTx(context.Background(), SetListWorkspaceParams{ID: util.RandomID(), Author: owner.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = store.DeleteWorkspace(context.Background(), workspace.ID)
+	require.NoError(t, err)
+
+	deleteTestUser(t, owner)
+}
+
+func TestPositionBetween(t *testing.T) {
+	none := sql.NullFloat64{}
+	at := func(position float64) sql.NullFloat64 {
+		return sql.NullFloat64{Float64: position, Valid: true}
+	}
+
+	testCases := []struct {
+		name     string
+		prev     sql.NullFloat64
+		next     sql.NullFloat64
+		position float64
+		ok       bool
+	}{
+		{"Alone", none, none, 42, true},
+		{"First", none, at(1024), 0, true},
+		{"Last", at(1024), none, 2048, true},
+		{"Between", at(1024), at(2048), 1536, true},
+		{"NoRoom", at(1), at(math.Nextafter(1, 2)), 1, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			position, ok := positionBetween(tc.prev, tc.next, 42)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				require.Equal(t, tc.position, position)
+			}
+		})
+	}
+}
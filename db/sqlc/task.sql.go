@@ -7,16 +7,17 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	db "github.com/PYTNAG/simpletodo/db/types"
 )
 
 const addTask = `-- name: AddTask :one
 INSERT INTO tasks (
-	list_id, parent_task, task
+	list_id, parent_task, task, position
 ) VALUES (
-	$1, $2, $3
-) RETURNING id, list_id, parent_task, task, complete
+	$1, $2, $3, (SELECT COALESCE(max(position), 0) + 1024 FROM tasks WHERE list_id = $1 and parent_task IS NOT DISTINCT FROM $2)
+) RETURNING id, list_id, parent_task, task, complete, position
 `
 
 type AddTaskParams struct {
@@ -34,6 +35,7 @@ func (q *Queries) AddTask(ctx context.Context, arg AddTaskParams) (Task, error)
 		&i.ParentTask,
 		&i.Task,
 		&i.Complete,
+		&i.Position,
 	)
 	return i, err
 }
@@ -48,9 +50,127 @@ func (q *Queries) DeleteTask(ctx context.Context, id int32) error {
 	return err
 }
 
+const getNextTaskPosition = `-- name: GetNextTaskPosition :one
+SELECT position FROM tasks
+WHERE list_id = $1 and parent_task IS NOT DISTINCT FROM $2
+	and id <> $3 and ($4::float8 IS NULL or (position, id) > ($4::float8, $5::int))
+ORDER BY position, id
+LIMIT 1
+`
+
+type GetNextTaskPositionParams struct {
+	ListID     int32           `json:"list_id"`
+	ParentTask db.NullInt32    `json:"parent_task"`
+	ID         int32           `json:"id"`
+	After      sql.NullFloat64 `json:"after"`
+	AfterID    int32           `json:"after_id"`
+}
+
+// Returns position of the task following the given one by position and id among tasks of the same parent
+func (q *Queries) GetNextTaskPosition(ctx context.Context, arg GetNextTaskPositionParams) (float64, error) {
+	row := q.db.QueryRowContext(ctx, getNextTaskPosition,
+		arg.ListID,
+		arg.ParentTask,
+		arg.ID,
+		arg.After,
+		arg.AfterID,
+	)
+	var position float64
+	err := row.Scan(&position)
+	return position, err
+}
+
+const getTask = `-- name: GetTask :one
+SELECT id, list_id, parent_task, task, complete, position FROM tasks
+WHERE id = $1 and list_id = $2
+`
+
+type GetTaskParams struct {
+	ID     int32 `json:"id"`
+	ListID int32 `json:"list_id"`
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg GetTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTask, arg.ID, arg.ListID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ListID,
+		&i.ParentTask,
+		&i.Task,
+		&i.Complete,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getTaskTree = `-- name: GetTaskTree :many
+WITH RECURSIVE tree AS (
+	SELECT tasks.id, tasks.list_id, tasks.parent_task, tasks.task, tasks.complete, tasks.position, 0 AS depth, ARRAY[tasks.id] AS path FROM tasks
+	WHERE tasks.list_id = $1 and (
+		($2::int IS NULL and tasks.parent_task IS NULL) or tasks.id = $2::int
+	)
+	UNION ALL
+	SELECT tasks.id, tasks.list_id, tasks.parent_task, tasks.task, tasks.complete, tasks.position, tree.depth + 1, tree.path || tasks.id FROM tasks
+	JOIN tree ON tasks.parent_task = tree.id and tasks.list_id = tree.list_id
+	WHERE tasks.id <> ALL(tree.path)
+)
+SELECT id, list_id, parent_task, task, complete, position, depth FROM tree
+ORDER BY depth, position, id
+`
+
+type GetTaskTreeParams struct {
+	ListID   int32        `json:"list_id"`
+	RootTask db.NullInt32 `json:"root_task"`
+}
+
+type GetTaskTreeRow struct {
+	ID         int32        `json:"id"`
+	ListID     int32        `json:"list_id"`
+	ParentTask db.NullInt32 `json:"parent_task"`
+	Task       string       `json:"task"`
+	Complete   bool         `json:"complete"`
+	Position   float64      `json:"position"`
+	Depth      int32        `json:"depth"`
+}
+
+// Returns tasks of the subtree ordered by depth, so parent goes before its children.
+// Whole list is returned if root task is null
+func (q *Queries) GetTaskTree(ctx context.Context, arg GetTaskTreeParams) ([]GetTaskTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTaskTree, arg.ListID, arg.RootTask)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTaskTreeRow{}
+	for rows.Next() {
+		var i GetTaskTreeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ListID,
+			&i.ParentTask,
+			&i.Task,
+			&i.Complete,
+			&i.Position,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTasks = `-- name: GetTasks :many
-SELECT id, list_id, parent_task, task, complete FROM tasks
+SELECT id, list_id, parent_task, task, complete, position FROM tasks
 WHERE list_id = $1
+ORDER BY parent_task NULLS FIRST, position, id
 `
 
 func (q *Queries) GetTasks(ctx context.Context, listID int32) ([]Task, error) {
@@ -68,6 +188,7 @@ func (q *Queries) GetTasks(ctx context.Context, listID int32) ([]Task, error) {
 			&i.ParentTask,
 			&i.Task,
 			&i.Complete,
+			&i.Position,
 		); err != nil {
 			return nil, err
 		}
@@ -82,11 +203,87 @@ func (q *Queries) GetTasks(ctx context.Context, listID int32) ([]Task, error) {
 	return items, nil
 }
 
+const rebalanceTaskPositions = `-- name: RebalanceTaskPositions :exec
+UPDATE tasks
+	set position = ranked.rank * 1024
+FROM (
+	SELECT id, row_number() OVER (ORDER BY position, id) AS rank FROM tasks
+	WHERE list_id = $1 and parent_task IS NOT DISTINCT FROM $2
+) AS ranked
+WHERE tasks.id = ranked.id
+`
+
+type RebalanceTaskPositionsParams struct {
+	ListID     int32        `json:"list_id"`
+	ParentTask db.NullInt32 `json:"parent_task"`
+}
+
+func (q *Queries) RebalanceTaskPositions(ctx context.Context, arg RebalanceTaskPositionsParams) error {
+	_, err := q.db.ExecContext(ctx, rebalanceTaskPositions, arg.ListID, arg.ParentTask)
+	return err
+}
+
+const setTaskParent = `-- name: SetTaskParent :one
+UPDATE tasks
+	set parent_task = $1, position = (
+		SELECT COALESCE(max(siblings.position), 0) + 1024 FROM tasks AS siblings
+		WHERE siblings.list_id = tasks.list_id and siblings.parent_task IS NOT DISTINCT FROM $1
+	)
+WHERE id = $2
+RETURNING id, list_id, parent_task, task, complete, position
+`
+
+type SetTaskParentParams struct {
+	ParentTask db.NullInt32 `json:"parent_task"`
+	ID         int32        `json:"id"`
+}
+
+// Task is placed after the last child of its new parent
+func (q *Queries) SetTaskParent(ctx context.Context, arg SetTaskParentParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, setTaskParent, arg.ParentTask, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ListID,
+		&i.ParentTask,
+		&i.Task,
+		&i.Complete,
+		&i.Position,
+	)
+	return i, err
+}
+
+const setTaskPosition = `-- name: SetTaskPosition :one
+UPDATE tasks
+	set position = $2
+WHERE id = $1
+RETURNING id, list_id, parent_task, task, complete, position
+`
+
+type SetTaskPositionParams struct {
+	ID       int32   `json:"id"`
+	Position float64 `json:"position"`
+}
+
+func (q *Queries) SetTaskPosition(ctx context.Context, arg SetTaskPositionParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, setTaskPosition, arg.ID, arg.Position)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ListID,
+		&i.ParentTask,
+		&i.Task,
+		&i.Complete,
+		&i.Position,
+	)
+	return i, err
+}
+
 const toggleTask = `-- name: ToggleTask :exec
 UPDATE tasks
 	set complete = not complete
 WHERE id = $1
-RETURNING id, list_id, parent_task, task, complete
+RETURNING id, list_id, parent_task, task, complete, position
 `
 
 func (q *Queries) ToggleTask(ctx context.Context, id int32) error {
@@ -98,7 +295,7 @@ const updateTaskText = `-- name: UpdateTaskText :exec
 UPDATE tasks
 	set task = $2
 WHERE id = $1
-RETURNING id, list_id, parent_task, task, complete
+RETURNING id, list_id, parent_task, task, complete, position
 `
 
 type UpdateTaskTextParams struct {
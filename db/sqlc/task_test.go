@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	db "github.com/PYTNAG/simpletodo/db/types"
 	"github.com/PYTNAG/simpletodo/util"
@@ -57,6 +58,41 @@ func TestAddChildTask(t *testing.T) {
 	deleteTestUser(t, newUser)
 }
 
+func TestGetTaskTree(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	root := createRandomTask(t, defaultList, nil)
+	child := createRandomTask(t, defaultList, root)
+	grandChild := createRandomTask(t, defaultList, child)
+	other := createRandomTask(t, defaultList, nil)
+
+	tree, err := testQueries.GetTaskTree(context.Background(), GetTaskTreeParams{
+		ListID:   defaultList.ID,
+		RootTask: db.NewNullInt32(0, false),
+	})
+	require.NoError(t, err)
+	require.Len(t, tree, 4)
+
+	require.Equal(t, root.ID, tree[0].ID)
+	require.Equal(t, other.ID, tree[1].ID)
+	require.Zero(t, tree[1].Depth)
+	require.Equal(t, child.ID, tree[2].ID)
+	require.Equal(t, int32(1), tree[2].Depth)
+	require.Equal(t, grandChild.ID, tree[3].ID)
+	require.Equal(t, int32(2), tree[3].Depth)
+
+	subtree, err := testQueries.GetTaskTree(context.Background(), GetTaskTreeParams{
+		ListID:   defaultList.ID,
+		RootTask: db.NewNullInt32(child.ID, true),
+	})
+	require.NoError(t, err)
+	require.Len(t, subtree, 2)
+	require.Equal(t, child.ID, subtree[0].ID)
+	require.Equal(t, grandChild.ID, subtree[1].ID)
+
+	deleteTestUser(t, newUser)
+}
+
 func TestToggleTask(t *testing.T) {
 	newUser, defaultList := createRandomUser(t, true)
 
@@ -98,6 +134,115 @@ func TestUpdateTaskText(t *testing.T) {
 	deleteTestUser(t, newUser)
 }
 
+func TestSetTaskParent(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	parent := createRandomTask(t, defaultList, nil)
+	task := createRandomTask(t, defaultList, nil)
+
+	movedTask, err := testQueries.SetTaskParent(context.Background(), SetTaskParentParams{
+		ID:         task.ID,
+		ParentTask: db.NewNullInt32(parent.ID, true),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, task.ID, movedTask.ID)
+	require.Equal(t, db.NewNullInt32(parent.ID, true), movedTask.ParentTask)
+
+	movedTask, err = testQueries.SetTaskParent(context.Background(), SetTaskParentParams{
+		ID:         task.ID,
+		ParentTask: db.NewNullInt32(0, false),
+	})
+
+	require.NoError(t, err)
+	require.False(t, movedTask.ParentTask.Valid)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestTaskParentOfOtherList(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+	otherUser, otherList := createRandomUser(t, true)
+
+	otherTask := createRandomTask(t, otherList, nil)
+	task := createRandomTask(t, defaultList, nil)
+
+	_, err := testQueries.AddTask(context.Background(), AddTaskParams{
+		ListID:     defaultList.ID,
+		ParentTask: db.NewNullInt32(otherTask.ID, true),
+		Task:       util.RandomString(24),
+	})
+
+	require.Error(t, err)
+	require.True(t, IsTaskParentNotInList(err))
+
+	_, err = testQueries.SetTaskParent(context.Background(), SetTaskParentParams{
+		ID:         task.ID,
+		ParentTask: db.NewNullInt32(otherTask.ID, true),
+	})
+
+	require.Error(t, err)
+	require.True(t, IsTaskParentNotInList(err))
+
+	deleteTestUser(t, newUser)
+	deleteTestUser(t, otherUser)
+}
+
+func TestTaskParentCycle(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	root := createRandomTask(t, defaultList, nil)
+	child := createRandomTask(t, defaultList, root)
+	grandChild := createRandomTask(t, defaultList, child)
+
+	for _, parent := range []*Task{root, grandChild} {
+		_, err := testQueries.SetTaskParent(context.Background(), SetTaskParentParams{
+			ID:         root.ID,
+			ParentTask: db.NewNullInt32(parent.ID, true),
+		})
+
+		require.Error(t, err)
+		require.True(t, IsTaskParentCycle(err))
+	}
+
+	deleteTestUser(t, newUser)
+}
+
+func TestTaskParentConcurrentCycle(t *testing.T) {
+	newUser, defaultList := createRandomUser(t, true)
+
+	first := createRandomTask(t, defaultList, nil)
+	second := createRandomTask(t, defaultList, nil)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = New(tx).SetTaskParent(context.Background(), SetTaskParentParams{
+		ID:         first.ID,
+		ParentTask: db.NewNullInt32(second.ID, true),
+	})
+	require.NoError(t, err)
+
+	// the other re-parent waits for lock of the list, so it sees the first one after commit
+	errs := make(chan error)
+	go func() {
+		_, err := testQueries.SetTaskParent(context.Background(), SetTaskParentParams{
+			ID:         second.ID,
+			ParentTask: db.NewNullInt32(first.ID, true),
+		})
+		errs <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, tx.Commit())
+
+	err = <-errs
+	require.Error(t, err)
+	require.True(t, IsTaskParentCycle(err))
+
+	deleteTestUser(t, newUser)
+}
+
 func TestDeleteTask(t *testing.T) {
 	newUser, defaultList := createRandomUser(t, true)
 
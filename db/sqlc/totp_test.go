@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserTOTP(t *testing.T, u *User) *UserTotp {
+	params := CreateUserTOTPParams{
+		UserID: u.ID,
+		Secret: util.RandomString(32),
+	}
+
+	totp, err := testQueries.CreateUserTOTP(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.UserID, totp.UserID)
+	require.Equal(t, params.Secret, totp.Secret)
+	require.False(t, totp.IsEnabled)
+	require.Zero(t, totp.LastUsedStep)
+
+	return &totp
+}
+
+func TestCreateUserTOTP(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	createRandomUserTOTP(t, newUser)
+
+	// pending secret can be replaced
+	totp := createRandomUserTOTP(t, newUser)
+
+	gotTotp, err := testQueries.GetUserTOTP(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Equal(t, totp.Secret, gotTotp.Secret)
+}
+
+func TestEnableUserTOTP(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	totp := createRandomUserTOTP(t, newUser)
+
+	enabledTotp, err := testQueries.EnableUserTOTP(context.Background(), EnableUserTOTPParams{UserID: newUser.ID, LastUsedStep: 10})
+	require.NoError(t, err)
+	require.True(t, enabledTotp.IsEnabled)
+	require.Equal(t, int64(10), enabledTotp.LastUsedStep)
+
+	// enabled secret can't be replaced
+	_, err = testQueries.CreateUserTOTP(context.Background(), CreateUserTOTPParams{UserID: newUser.ID, Secret: util.RandomString(32)})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	gotTotp, err := testQueries.GetUserTOTP(context.Background(), newUser.ID)
+	require.NoError(t, err)
+	require.Equal(t, totp.Secret, gotTotp.Secret)
+
+	// step can be used only once and only forward
+	used, err := testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{UserID: newUser.ID, LastUsedStep: 10})
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	used, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{UserID: newUser.ID, LastUsedStep: 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+
+	hash := util.HashRecoveryCode(util.RandomString(16))
+
+	err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{UserID: newUser.ID, CodeHash: hash})
+	require.NoError(t, err)
+
+	params := UseRecoveryCodeParams{UserID: newUser.ID, CodeHash: hash}
+
+	used, err := testQueries.UseRecoveryCode(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	// code is one-time
+	used, err = testQueries.UseRecoveryCode(context.Background(), params)
+	require.NoError(t, err)
+	require.Zero(t, used)
+}
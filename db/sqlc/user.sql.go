@@ -7,26 +7,47 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
 	username, 
-	hash
+	hash,
+	email,
+	email_verified
 ) VALUES (
-	$1, $2
-) RETURNING id, username, hash
+	$1, $2, $3, $4
+) RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
 `
 
 type CreateUserParams struct {
-	Username string `json:"username"`
-	Hash     []byte `json:"hash"`
+	Username      string         `json:"username"`
+	Hash          []byte         `json:"hash"`
+	Email         sql.NullString `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.Hash)
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Username,
+		arg.Hash,
+		arg.Email,
+		arg.EmailVerified,
+	)
 	var i User
-	err := row.Scan(&i.ID, &i.Username, &i.Hash)
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
 	return i, err
 }
 
@@ -48,23 +69,224 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) (DeleteUserRow, erro
 	return i, err
 }
 
+const disableUser = `-- name: DisableUser :one
+UPDATE users
+	set disabled_at = COALESCE(disabled_at, now())
+WHERE id = $1
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+func (q *Queries) DisableUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, disableUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const enableUser = `-- name: EnableUser :one
+UPDATE users
+	set disabled_at = NULL
+WHERE id = $1
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+func (q *Queries) EnableUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, username, hash FROM users
+SELECT id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at FROM users
 WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUser, username)
 	var i User
-	err := row.Scan(&i.ID, &i.Username, &i.Hash)
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getUserUsage = `-- name: GetUserUsage :one
+SELECT
+	(SELECT count(*) FROM lists WHERE lists.author = users.id) AS lists,
+	(SELECT count(*) FROM tasks JOIN lists ON lists.id = tasks.list_id WHERE lists.author = users.id) AS tasks,
+	(SELECT count(*) FROM sessions
+		WHERE sessions.username = users.username
+		and sessions.is_blocked = false and sessions.is_rotated = false and sessions.expires_at > now()
+	) AS active_sessions,
+	(SELECT count(*) FROM personal_access_tokens WHERE personal_access_tokens.user_id = users.id) AS personal_access_tokens
+FROM users
+WHERE users.id = $1
+`
+
+type GetUserUsageRow struct {
+	Lists                int64 `json:"lists"`
+	Tasks                int64 `json:"tasks"`
+	ActiveSessions       int64 `json:"active_sessions"`
+	PersonalAccessTokens int64 `json:"personal_access_tokens"`
+}
+
+func (q *Queries) GetUserUsage(ctx context.Context, id int32) (GetUserUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserUsage, id)
+	var i GetUserUsageRow
+	err := row.Scan(
+		&i.Lists,
+		&i.Tasks,
+		&i.ActiveSessions,
+		&i.PersonalAccessTokens,
+	)
 	return i, err
 }
 
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at FROM users
+WHERE $1::text IS NULL
+	or username ILIKE '%' || $1 || '%'
+	or email ILIKE '%' || $1 || '%'
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListUsersParams struct {
+	Search sql.NullString `json:"search"`
+	Limit  int32          `json:"limit"`
+	Offset int32          `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.Search, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Hash,
+			&i.Email,
+			&i.EmailVerified,
+			&i.DisplayName,
+			&i.TimeZone,
+			&i.Locale,
+			&i.Role,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// Serializes changes of the user's personal lists order until the end of transaction
+func (q *Queries) LockUser(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, lockUser, id)
+	return err
+}
+
 const rehashUser = `-- name: RehashUser :one
 UPDATE users
 	set hash = $2
 WHERE id = $1 and hash = $3
-RETURNING id, username, hash
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
 `
 
 type RehashUserParams struct {
@@ -76,6 +298,149 @@ type RehashUserParams struct {
 func (q *Queries) RehashUser(ctx context.Context, arg RehashUserParams) (User, error) {
 	row := q.db.QueryRowContext(ctx, rehashUser, arg.ID, arg.NewHash, arg.OldHash)
 	var i User
-	err := row.Scan(&i.ID, &i.Username, &i.Hash)
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const setUserHash = `-- name: SetUserHash :one
+UPDATE users
+	set hash = $2
+WHERE id = $1
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+type SetUserHashParams struct {
+	ID   int32  `json:"id"`
+	Hash []byte `json:"hash"`
+}
+
+func (q *Queries) SetUserHash(ctx context.Context, arg SetUserHashParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserHash, arg.ID, arg.Hash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+	set role = $2
+WHERE id = $1
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+type SetUserRoleParams struct {
+	ID   int32  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const setVerifiedUserEmail = `-- name: SetVerifiedUserEmail :one
+UPDATE users
+	set email = $2, email_verified = true
+WHERE id = $1
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+type SetVerifiedUserEmailParams struct {
+	ID    int32          `json:"id"`
+	Email sql.NullString `json:"email"`
+}
+
+func (q *Queries) SetVerifiedUserEmail(ctx context.Context, arg SetVerifiedUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setVerifiedUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+	set username = COALESCE($1, username),
+	display_name = COALESCE($2, display_name),
+	time_zone = COALESCE($3, time_zone),
+	locale = COALESCE($4, locale)
+WHERE id = $5
+RETURNING id, username, hash, email, email_verified, display_name, time_zone, locale, role, disabled_at
+`
+
+type UpdateUserProfileParams struct {
+	Username    sql.NullString `json:"username"`
+	DisplayName sql.NullString `json:"display_name"`
+	TimeZone    sql.NullString `json:"time_zone"`
+	Locale      sql.NullString `json:"locale"`
+	ID          int32          `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Username,
+		arg.DisplayName,
+		arg.TimeZone,
+		arg.Locale,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Hash,
+		&i.Email,
+		&i.EmailVerified,
+		&i.DisplayName,
+		&i.TimeZone,
+		&i.Locale,
+		&i.Role,
+		&i.DisabledAt,
+	)
 	return i, err
 }
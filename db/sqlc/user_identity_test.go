@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserIdentity(t *testing.T, u *User) *UserIdentity {
+	params := CreateUserIdentityParams{
+		Issuer:  "https://" + util.RandomUsername() + ".example.com",
+		Subject: uuid.NewString(),
+		UserID:  u.ID,
+		Email:   u.Email,
+	}
+
+	identity, err := testQueries.CreateUserIdentity(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.Issuer, identity.Issuer)
+	require.Equal(t, params.Subject, identity.Subject)
+	require.Equal(t, params.UserID, identity.UserID)
+	require.Equal(t, params.Email, identity.Email)
+	require.NotZero(t, identity.CreatedAt)
+
+	return &identity
+}
+
+func TestCreateUserIdentity(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	identity := createRandomUserIdentity(t, newUser)
+
+	// identity can be linked only once
+	otherUser, _ := createRandomUser(t, false)
+
+	_, err := testQueries.CreateUserIdentity(context.Background(), CreateUserIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		UserID:  otherUser.ID,
+	})
+	require.True(t, IsUniqueViolation(err))
+}
+
+func TestGetUserByIdentity(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	identity := createRandomUserIdentity(t, newUser)
+
+	user, err := testQueries.GetUserByIdentity(context.Background(), GetUserByIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, *newUser, user)
+
+	_, err = testQueries.GetUserByIdentity(context.Background(), GetUserByIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: uuid.NewString(),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
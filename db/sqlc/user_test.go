@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/PYTNAG/simpletodo/util"
 	"github.com/stretchr/testify/require"
@@ -16,6 +17,7 @@ func createRandomUser(t *testing.T, withDefaultList bool) (*User, *List) {
 	createUserParams := CreateUserParams{
 		Username: util.RandomUsername(),
 		Hash:     hash,
+		Email:    sql.NullString{String: util.RandomEmail(), Valid: true},
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), createUserParams)
@@ -25,6 +27,7 @@ func createRandomUser(t *testing.T, withDefaultList bool) (*User, *List) {
 
 	require.Equal(t, createUserParams.Username, user.Username)
 	require.Equal(t, createUserParams.Hash, user.Hash)
+	require.Equal(t, createUserParams.Email, user.Email)
 
 	require.Greater(t, user.ID, int32(0))
 
@@ -65,6 +68,28 @@ func TestGetUser(t *testing.T) {
 	deleteTestUser(t, expectedUser)
 }
 
+func TestGetUserByID(t *testing.T) {
+	expectedUser, _ := createRandomUser(t, false)
+
+	actualUser, err := testQueries.GetUserByID(context.Background(), expectedUser.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, *expectedUser, actualUser)
+
+	deleteTestUser(t, expectedUser)
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	expectedUser, _ := createRandomUser(t, false)
+
+	actualUser, err := testQueries.GetUserByEmail(context.Background(), expectedUser.Email)
+
+	require.NoError(t, err)
+	require.Equal(t, expectedUser.ID, actualUser.ID)
+
+	deleteTestUser(t, expectedUser)
+}
+
 func TestDeleteUser(t *testing.T) {
 	newUser, _ := createRandomUser(t, false)
 
@@ -96,3 +121,120 @@ func TestRehashUser(t *testing.T) {
 
 	deleteTestUser(t, &actualUser)
 }
+
+func TestUpdateUserProfile(t *testing.T) {
+	expectedUser, _ := createRandomUser(t, false)
+
+	require.Equal(t, "", expectedUser.DisplayName)
+	require.Equal(t, "UTC", expectedUser.TimeZone)
+	require.Equal(t, "en", expectedUser.Locale)
+
+	params := UpdateUserProfileParams{
+		ID:          expectedUser.ID,
+		DisplayName: sql.NullString{String: util.RandomString(8), Valid: true},
+		TimeZone:    sql.NullString{String: "Europe/Berlin", Valid: true},
+	}
+
+	actualUser, err := testQueries.UpdateUserProfile(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Equal(t, params.DisplayName.String, actualUser.DisplayName)
+	require.Equal(t, params.TimeZone.String, actualUser.TimeZone)
+
+	// unset fields are kept
+	require.Equal(t, expectedUser.Username, actualUser.Username)
+	require.Equal(t, expectedUser.Locale, actualUser.Locale)
+
+	deleteTestUser(t, &actualUser)
+}
+
+func TestListUsers(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+
+	params := ListUsersParams{
+		Search: sql.NullString{String: newUser.Username[1:], Valid: true},
+		Limit:  5,
+		Offset: 0,
+	}
+
+	users, err := testQueries.ListUsers(context.Background(), params)
+
+	require.NoError(t, err)
+	require.Contains(t, users, *newUser)
+
+	// escaped wildcard matches only itself, random usernames have no "%"
+	params.Search.String = newUser.Username[:1] + `\%` + newUser.Username[2:]
+
+	users, err = testQueries.ListUsers(context.Background(), params)
+
+	require.NoError(t, err)
+	require.NotContains(t, users, *newUser)
+
+	users, err = testQueries.ListUsers(context.Background(), ListUsersParams{Limit: 5})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestSetUserRole(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	require.Equal(t, RoleUser, newUser.Role)
+
+	admin, err := testQueries.SetUserRole(context.Background(), SetUserRoleParams{
+		ID:   newUser.ID,
+		Role: RoleAdmin,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, RoleAdmin, admin.Role)
+
+	_, err = testQueries.SetUserRole(context.Background(), SetUserRoleParams{
+		ID:   newUser.ID,
+		Role: "owner",
+	})
+	require.Error(t, err)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestDisableAndEnableUser(t *testing.T) {
+	newUser, _ := createRandomUser(t, false)
+	require.False(t, newUser.DisabledAt.Valid)
+
+	disabledUser, err := testQueries.DisableUser(context.Background(), newUser.ID)
+
+	require.NoError(t, err)
+	require.True(t, disabledUser.DisabledAt.Valid)
+	require.WithinDuration(t, time.Now(), disabledUser.DisabledAt.Time, time.Minute)
+
+	// time of the first disabling is kept
+	disabledAgain, err := testQueries.DisableUser(context.Background(), newUser.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, disabledUser.DisabledAt.Time, disabledAgain.DisabledAt.Time)
+
+	enabledUser, err := testQueries.EnableUser(context.Background(), newUser.ID)
+
+	require.NoError(t, err)
+	require.False(t, enabledUser.DisabledAt.Valid)
+
+	deleteTestUser(t, newUser)
+}
+
+func TestGetUserUsage(t *testing.T) {
+	newUser, list := createRandomUser(t, true)
+	createRandomTask(t, list, nil)
+	createRandomTask(t, list, nil)
+
+	usage, err := testQueries.GetUserUsage(context.Background(), newUser.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), usage.Lists)
+	require.Equal(t, int64(2), usage.Tasks)
+	require.Zero(t, usage.ActiveSessions)
+	require.Zero(t, usage.PersonalAccessTokens)
+
+	deleteTestUser(t, newUser)
+}
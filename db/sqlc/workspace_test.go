@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	dbtypes "github.com/PYTNAG/simpletodo/db/types"
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserWorkspaces(t *testing.T) {
+	owner, _ := createRandomUser(t, false)
+	workspace := createRandomWorkspace(t, owner)
+
+	workspaces, err := testQueries.GetUserWorkspaces(context.Background(), owner.ID)
+
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, workspace.ID, workspaces[0].ID)
+	require.Equal(t, workspace.Name, workspaces[0].Name)
+	require.Equal(t, WorkspaceRoleOwner, workspaces[0].Role)
+
+	err = testQueries.DeleteWorkspace(context.Background(), workspace.ID)
+	require.NoError(t, err)
+
+	deleteTestUser(t, owner)
+}
+
+func TestWorkspaceMemberOwnerIsProtected(t *testing.T) {
+	owner, _ := createRandomUser(t, false)
+	workspace := createRandomWorkspace(t, owner)
+
+	_, err := testQueries.UpdateWorkspaceMemberRole(context.Background(), UpdateWorkspaceMemberRoleParams{
+		WorkspaceID: workspace.ID,
+		UserID:      owner.ID,
+		Role:        WorkspaceRoleMember,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleted, err := testQueries.DeleteWorkspaceMember(context.Background(), DeleteWorkspaceMemberParams{
+		WorkspaceID: workspace.ID,
+		UserID:      owner.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	err = testQueries.DeleteOwnedWorkspaces(context.Background(), owner.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWorkspace(context.Background(), workspace.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleteTestUser(t, owner)
+}
+
+func TestSetListWorkspace(t *testing.T) {
+	owner, list := createRandomUser(t, true)
+	workspace := createRandomWorkspace(t, owner)
+
+	moved, err := testQueries.SetListWorkspace(context.Background(), SetListWorkspaceParams{
+		ID:          list.ID,
+		WorkspaceID: dbtypes.NewNullInt32(workspace.ID, true),
+		Author:      owner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, workspace.ID, moved.WorkspaceID.Int32)
+
+	// list of workspace isn't personal list anymore
+	lists, err := testQueries.GetLists(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Empty(t, lists)
+
+	workspaceLists, err := testQueries.GetWorkspaceLists(context.Background(), workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, workspaceLists, 1)
+
+	_, err = testQueries.SetListWorkspace(context.Background(), SetListWorkspaceParams{
+		ID:     list.ID,
+		Author: owner.ID,
+	})
+	require.NoError(t, err)
+
+	lists, err = testQueries.GetLists(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+
+	_, err = testQueries.AddWorkspaceList(context.Background(), AddWorkspaceListParams{
+		Author:      owner.ID,
+		WorkspaceID: workspace.ID,
+		Header:      util.RandomString(8),
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteWorkspace(context.Background(), workspace.ID)
+	require.NoError(t, err)
+
+	deleteTestUser(t, owner)
+}
@@ -25,3 +25,17 @@ func (i NullInt32) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(nil)
 }
+
+func (i *NullInt32) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*i = NewNullInt32(0, false)
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &i.Int32); err != nil {
+		return err
+	}
+
+	i.Valid = true
+	return nil
+}
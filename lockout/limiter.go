@@ -0,0 +1,167 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// Policy defines delays which failed attempts are followed by
+type Policy struct {
+	FreeAttempts    int32         // failures allowed without delay
+	BackoffBase     time.Duration // delay after the first failure over free ones, doubled by each next failure
+	MaxAttempts     int32         // failures which key is locked out after
+	LockoutDuration time.Duration // also period after which failures are forgotten
+}
+
+var DefaultPolicy = Policy{
+	FreeAttempts:    3,
+	BackoffBase:     time.Second,
+	MaxAttempts:     10,
+	LockoutDuration: 15 * time.Minute,
+}
+
+// Delay returns duration which attempts are rejected for after last of failures
+func (p Policy) Delay(failures int32) time.Duration {
+	if failures < p.FreeAttempts {
+		return 0
+	}
+
+	if failures >= p.MaxAttempts {
+		return p.LockoutDuration
+	}
+
+	delay := p.BackoffBase
+	for i := p.FreeAttempts; i < failures && delay < p.LockoutDuration; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.LockoutDuration)
+}
+
+// UsernameKey returns key which attempts to log in as username are tracked by
+func UsernameKey(username string) string {
+	return "user:" + username
+}
+
+// IPKey returns key which attempts from client IP are tracked by
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Limiter rejects attempts of keys with recent failures according to policy
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+// NewLimiter creates limiter, zero fields of policy are taken from DefaultPolicy
+func NewLimiter(store Store, policy Policy) *Limiter {
+	if policy.FreeAttempts == 0 {
+		policy.FreeAttempts = DefaultPolicy.FreeAttempts
+	}
+
+	if policy.BackoffBase == 0 {
+		policy.BackoffBase = DefaultPolicy.BackoffBase
+	}
+
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+
+	if policy.LockoutDuration == 0 {
+		policy.LockoutDuration = DefaultPolicy.LockoutDuration
+	}
+
+	return &Limiter{
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Check returns how long attempts are rejected for, the largest delay across keys, zero if none of them is delayed
+func (l *Limiter) Check(ctx context.Context, keys ...string) (time.Duration, error) {
+	now := l.now()
+
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		attempts, err := l.store.GetAttempts(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+
+		wait := attempts.LastFailedAt.Add(l.policy.Delay(attempts.Failures)).Sub(now)
+		retryAfter = max(retryAfter, wait)
+	}
+
+	return retryAfter, nil
+}
+
+// Attempt is counted as failed for each of its keys until it's released
+type Attempt struct {
+	at       time.Time
+	keys     []string
+	previous []Attempts
+}
+
+// Take counts attempt of keys as failed before it's made, so parallel attempts can't pass the check
+// before their failures are registered. Returns how long attempts are rejected for, the largest delay
+// across keys. Rejected attempt is released right away, allowed one must be released if it succeeds
+func (l *Limiter) Take(ctx context.Context, keys ...string) (*Attempt, time.Duration, error) {
+	attempt := &Attempt{at: l.now()}
+
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		previous, err := l.store.TakeAttempt(ctx, key, attempt.at, attempt.at.Add(-l.policy.LockoutDuration))
+		if err != nil {
+			l.Release(ctx, attempt)
+			return nil, 0, err
+		}
+
+		attempt.keys = append(attempt.keys, key)
+		attempt.previous = append(attempt.previous, previous)
+
+		wait := previous.LastFailedAt.Add(l.policy.Delay(previous.Failures)).Sub(attempt.at)
+		retryAfter = max(retryAfter, wait)
+	}
+
+	if retryAfter > 0 {
+		if err := l.Release(ctx, attempt); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return attempt, retryAfter, nil
+}
+
+// Release undoes failures which attempt is counted as by Take
+func (l *Limiter) Release(ctx context.Context, attempt *Attempt) error {
+	for i, key := range attempt.keys {
+		if err := l.store.ReleaseAttempt(ctx, key, attempt.at, attempt.previous[i].LastFailedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Fail registers failed attempt for each of keys
+func (l *Limiter) Fail(ctx context.Context, keys ...string) error {
+	now := l.now()
+
+	for _, key := range keys {
+		if _, err := l.store.AddFailure(ctx, key, now, now.Add(-l.policy.LockoutDuration)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset forgets failures of key, used after successful attempt or to unlock key manually
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.ResetAttempts(ctx, key)
+}
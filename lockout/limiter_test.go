@@ -0,0 +1,158 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{
+		FreeAttempts:    3,
+		BackoffBase:     time.Second,
+		MaxAttempts:     6,
+		LockoutDuration: 5 * time.Second,
+	}
+
+	testCases := []struct {
+		failures int32
+		delay    time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.delay, policy.Delay(tc.failures), "failures %d", tc.failures)
+	}
+
+	// backoff never exceeds lockout
+	policy.MaxAttempts = 100
+	require.Equal(t, policy.LockoutDuration, policy.Delay(99))
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), Policy{
+		FreeAttempts:    2,
+		BackoffBase:     time.Second,
+		MaxAttempts:     4,
+		LockoutDuration: time.Minute,
+	})
+	limiter.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	userKey, ipKey := UsernameKey("user"), IPKey("127.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, userKey, ipKey))
+	}
+
+	// one failure over free ones
+	retryAfter, err := limiter.Check(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, retryAfter)
+
+	now = now.Add(time.Second)
+	retryAfter, err = limiter.Check(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// locked out
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Fail(ctx, userKey))
+	}
+
+	retryAfter, err = limiter.Check(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, retryAfter)
+
+	// other keys aren't affected
+	retryAfter, err = limiter.Check(ctx, ipKey)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	require.NoError(t, limiter.Reset(ctx, userKey))
+
+	retryAfter, err = limiter.Check(ctx, userKey)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+}
+
+func TestMemoryStoreForgetsFailures(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	attempts, err := store.AddFailure(ctx, "key", now, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempts.Failures)
+
+	attempts, err = store.AddFailure(ctx, "key", now.Add(time.Second), now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts.Failures)
+
+	// previous failures are older than since
+	later := now.Add(2 * time.Minute)
+	attempts, err = store.AddFailure(ctx, "key", later, later.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempts.Failures)
+	require.Equal(t, later, attempts.LastFailedAt)
+}
+
+func TestLimiterTake(t *testing.T) {
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), Policy{
+		FreeAttempts:    2,
+		BackoffBase:     time.Second,
+		MaxAttempts:     4,
+		LockoutDuration: time.Minute,
+	})
+	limiter.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	userKey, ipKey := UsernameKey("user"), IPKey("127.0.0.1")
+
+	// attempts in progress are already counted
+	for i := 0; i < 2; i++ {
+		_, retryAfter, err := limiter.Take(ctx, userKey, ipKey)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	}
+
+	_, retryAfter, err := limiter.Take(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, retryAfter)
+
+	// rejected attempt isn't counted
+	retryAfter, err = limiter.Check(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, retryAfter)
+
+	now = now.Add(time.Second)
+
+	attempt, retryAfter, err := limiter.Take(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	// released attempt restores failures and time of the last one
+	require.NoError(t, limiter.Release(ctx, attempt))
+
+	retryAfter, err = limiter.Check(ctx, userKey, ipKey)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter)
+
+	attempts, err := limiter.store.GetAttempts(ctx, userKey)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts.Failures)
+	require.Equal(t, now.Add(-time.Second), attempts.LastFailedAt)
+}
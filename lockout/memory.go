@@ -0,0 +1,90 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempts in process memory, they are lost on restart and aren't shared between instances
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		attempts: make(map[string]Attempts),
+	}
+}
+
+func (store *MemoryStore) AddFailure(_ context.Context, key string, at, since time.Time) (Attempts, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	attempts := store.attempts[key]
+	if attempts.LastFailedAt.Before(since) {
+		attempts.Failures = 0
+	}
+
+	attempts.Failures++
+	attempts.LastFailedAt = at
+
+	store.attempts[key] = attempts
+
+	return attempts, nil
+}
+
+func (store *MemoryStore) GetAttempts(_ context.Context, key string) (Attempts, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.attempts[key], nil
+}
+
+func (store *MemoryStore) TakeAttempt(_ context.Context, key string, at, since time.Time) (Attempts, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	previous := store.attempts[key]
+
+	attempts := previous
+	if attempts.LastFailedAt.Before(since) {
+		attempts.Failures = 0
+	}
+
+	attempts.Failures++
+	attempts.LastFailedAt = at
+
+	store.attempts[key] = attempts
+
+	return previous, nil
+}
+
+func (store *MemoryStore) ReleaseAttempt(_ context.Context, key string, at, previousFailedAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	attempts, ok := store.attempts[key]
+	if !ok {
+		return nil
+	}
+
+	attempts.Failures = max(attempts.Failures-1, 0)
+	if attempts.LastFailedAt.Equal(at) {
+		attempts.LastFailedAt = previousFailedAt
+	}
+
+	store.attempts[key] = attempts
+
+	return nil
+}
+
+func (store *MemoryStore) ResetAttempts(_ context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.attempts, key)
+
+	return nil
+}
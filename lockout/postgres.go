@@ -0,0 +1,97 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/PYTNAG/simpletodo/db/sqlc"
+)
+
+// PostgresStore keeps attempts in login_attempts table
+type PostgresStore struct {
+	queries db.Querier
+}
+
+func NewPostgresStore(queries db.Querier) *PostgresStore {
+	return &PostgresStore{
+		queries: queries,
+	}
+}
+
+func (store *PostgresStore) AddFailure(ctx context.Context, key string, at, since time.Time) (Attempts, error) {
+	attempt, err := store.queries.AddLoginFailure(ctx, db.AddLoginFailureParams{
+		Key:          key,
+		LastFailedAt: at,
+		Since:        since,
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+
+	return Attempts{
+		Failures:     attempt.Failures,
+		LastFailedAt: attempt.LastFailedAt,
+	}, nil
+}
+
+func (store *PostgresStore) GetAttempts(ctx context.Context, key string) (Attempts, error) {
+	attempt, err := store.queries.GetLoginAttempts(ctx, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Attempts{}, nil
+		}
+
+		return Attempts{}, err
+	}
+
+	return Attempts{
+		Failures:     attempt.Failures,
+		LastFailedAt: attempt.LastFailedAt,
+	}, nil
+}
+
+func (store *PostgresStore) TakeAttempt(ctx context.Context, key string, at, since time.Time) (Attempts, error) {
+	// timestamps are stored with microseconds, release compares the stored one with at
+	at = at.Truncate(time.Microsecond)
+
+	// the row must exist to be locked by the attempt
+	err := store.queries.CreateLoginAttempts(ctx, db.CreateLoginAttemptsParams{
+		Key:          key,
+		LastFailedAt: at,
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+
+	previous, err := store.queries.TakeLoginAttempt(ctx, db.TakeLoginAttemptParams{
+		Since:   since,
+		TakenAt: at,
+		Key:     key,
+	})
+	if err != nil {
+		// attempts have been reset right after the row was created, there is nothing to count
+		if err == sql.ErrNoRows {
+			return Attempts{}, nil
+		}
+
+		return Attempts{}, err
+	}
+
+	return Attempts{
+		Failures:     previous.Failures,
+		LastFailedAt: previous.LastFailedAt,
+	}, nil
+}
+
+func (store *PostgresStore) ReleaseAttempt(ctx context.Context, key string, at, previousFailedAt time.Time) error {
+	return store.queries.ReleaseLoginAttempt(ctx, db.ReleaseLoginAttemptParams{
+		TakenAt:          at.Truncate(time.Microsecond),
+		PreviousFailedAt: previousFailedAt,
+		Key:              key,
+	})
+}
+
+func (store *PostgresStore) ResetAttempts(ctx context.Context, key string) error {
+	return store.queries.DeleteLoginAttempts(ctx, key)
+}
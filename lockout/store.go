@@ -0,0 +1,27 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// Attempts describes failed login attempts of a key
+type Attempts struct {
+	Failures     int32
+	LastFailedAt time.Time
+}
+
+// Store tracks failed login attempts by key, e.g. username or client IP
+type Store interface {
+	// AddFailure registers failed attempt at time at. Failures registered before since are forgotten
+	AddFailure(ctx context.Context, key string, at, since time.Time) (Attempts, error)
+	// GetAttempts returns zero Attempts if there are no failures of key
+	GetAttempts(ctx context.Context, key string) (Attempts, error)
+	// TakeAttempt registers failure at time at like AddFailure, but returns attempts before it.
+	// It's atomic, so parallel attempts of key see failures of each other
+	TakeAttempt(ctx context.Context, key string, at, since time.Time) (Attempts, error)
+	// ReleaseAttempt undoes failure registered by TakeAttempt at time at,
+	// last failure time is restored to previousFailedAt unless there was a later failure
+	ReleaseAttempt(ctx context.Context, key string, at, previousFailedAt time.Time) error
+	ResetAttempts(ctx context.Context, key string) error
+}
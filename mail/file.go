@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"io"
+	"sync"
+)
+
+const messageSeparator = "\r\n----------\r\n"
+
+// FileMailer writes emails to file instead of sending them, useful for local development and tests
+type FileMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewFileMailer(w io.Writer, from string) *FileMailer {
+	return &FileMailer{
+		w:    w,
+		from: from,
+	}
+}
+
+func (mailer *FileMailer) SendEmail(to, subject, body string) error {
+	msg, err := buildMessage(mailer.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	mailer.mu.Lock()
+	defer mailer.mu.Unlock()
+
+	if _, err := mailer.w.Write(msg); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(mailer.w, messageSeparator)
+	return err
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+)
+
+const (
+	KindFile = "file"
+	KindSMTP = "smtp"
+)
+
+var ErrInvalidHeader = errors.New("header value contains line break")
+
+// Mailer sends emails to users
+type Mailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+// NewMailer creates mailer of configured kind, file mailer is used by default
+func NewMailer(config util.Config) (Mailer, error) {
+	switch config.MailerKind {
+	case "", KindFile:
+		if config.MailFile == "" {
+			return NewFileMailer(os.Stdout, config.MailSenderAddress), nil
+		}
+
+		file, err := os.OpenFile(config.MailFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open mail file: %w", err)
+		}
+
+		return NewFileMailer(file, config.MailSenderAddress), nil
+	case KindSMTP:
+		return NewSMTPMailer(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.MailSenderAddress)
+	default:
+		return nil, fmt.Errorf("unsupported mailer %s", config.MailerKind)
+	}
+}
+
+// buildMessage formats plain text email as RFC 5322 message
+func buildMessage(from, to, subject, body string) ([]byte, error) {
+	for _, value := range []string{from, to, subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "From: %s\r\n", from)
+	fmt.Fprintf(&sb, "To: %s\r\n", to)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&sb, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	sb.WriteString("\r\n")
+
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewFileMailer(&buf, "noreply@example.com")
+
+	err := mailer.SendEmail("user@example.com", "Test subject", "first line\nsecond line")
+	require.NoError(t, err)
+
+	msg := buf.String()
+	require.Contains(t, msg, "From: noreply@example.com\r\n")
+	require.Contains(t, msg, "To: user@example.com\r\n")
+	require.Contains(t, msg, "Subject: Test subject\r\n")
+	require.Contains(t, msg, "\r\n\r\nfirst line\r\nsecond line\r\n")
+	require.Contains(t, msg, messageSeparator)
+}
+
+func TestHeaderInjection(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewFileMailer(&buf, "noreply@example.com")
+
+	err := mailer.SendEmail("user@example.com\r\nBcc: other@example.com", "Test subject", "body")
+	require.ErrorIs(t, err, ErrInvalidHeader)
+	require.Zero(t, buf.Len())
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &FileMailer{}, mailer)
+
+	mailer, err = NewMailer(util.Config{
+		MailerKind:        KindSMTP,
+		SMTPAddr:          "localhost:25",
+		MailSenderAddress: "noreply@example.com",
+	})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = NewMailer(util.Config{MailerKind: KindSMTP, SMTPAddr: "localhost"})
+	require.Error(t, err)
+
+	_, err = NewMailer(util.Config{MailerKind: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends emails through SMTP server
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates SMTP mailer, addr is "<host>:<port>" of the server.
+// Authentication is skipped if username is empty
+func NewSMTPMailer(addr, username, password, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address: %w", err)
+	}
+
+	if from == "" {
+		return nil, fmt.Errorf("sender address is required")
+	}
+
+	mailer := &SMTPMailer{
+		addr: addr,
+		from: from,
+	}
+
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return mailer, nil
+}
+
+func (mailer *SMTPMailer) SendEmail(to, subject, body string) error {
+	msg, err := buildMessage(mailer.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(mailer.addr, mailer.auth, mailer.from, []string{to}, msg)
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	// time zones of user profiles are validated without system tzdata
+	_ "time/tzdata"
 
 	"github.com/PYTNAG/simpletodo/api"
 	db "github.com/PYTNAG/simpletodo/db/sqlc"
+	"github.com/PYTNAG/simpletodo/lockout"
+	"github.com/PYTNAG/simpletodo/mail"
+	"github.com/PYTNAG/simpletodo/token"
 	"github.com/PYTNAG/simpletodo/util"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -20,6 +30,11 @@ func main() {
 		log.Fatal("cannot load config: ", err)
 	}
 
+	if len(os.Args) > 1 {
+		runCommand(cfg, os.Args[1:])
+		return
+	}
+
 	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to db: ", err)
@@ -29,7 +44,12 @@ func main() {
 
 	store := db.NewStore(conn)
 
-	server, err := api.NewServer(cfg, store)
+	mailer, err := mail.NewMailer(cfg)
+	if err != nil {
+		log.Fatal("cannot create mailer: ", err)
+	}
+
+	server, err := api.NewServer(cfg, store, mailer)
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
@@ -52,3 +72,185 @@ func runDBMigration(migrationURL, dbSource string) {
 
 	log.Print("db migrated successfully")
 }
+
+// runCommand executes admin command instead of starting the server
+func runCommand(cfg util.Config, args []string) {
+	switch args[0] {
+	case "token-keys":
+		runTokenKeysCommand(cfg, args[1:])
+	case "unlock-login":
+		runUnlockLoginCommand(cfg, args[1:])
+	case "create-admin":
+		runCreateAdminCommand(cfg, args[1:])
+	default:
+		log.Fatalf("unknown command %s", args[0])
+	}
+}
+
+// runTokenKeysCommand prints token keys config after rotation or retirement of key.
+//
+//	token-keys rotate           new primary key is generated, current one is kept for verification
+//	token-keys retire <key id>  verification key is removed, its tokens aren't accepted anymore
+//
+// In public token mode secret key is rotated and public keys are kept for verification.
+func runTokenKeysCommand(cfg util.Config, args []string) {
+	publicMode := cfg.TokenMode == "public"
+
+	verificationKeysEnv, secretKeyEnv := "TOKEN_VERIFICATION_KEYS", "TOKEN_SYMMETRIC_KEY"
+	encodedKeys, primaryKey := cfg.TokenVerificationKeys, cfg.TokenSymmetricKey
+	rotate := token.RotateSymmetricKeys
+	if publicMode {
+		verificationKeysEnv, secretKeyEnv = "TOKEN_PUBLIC_KEYS", "TOKEN_SECRET_KEY"
+		encodedKeys, primaryKey = cfg.TokenPublicKeys, cfg.TokenSecretKey
+		rotate = token.RotateSecretKeys
+	}
+
+	verificationKeys, err := token.ParseKeys(encodedKeys)
+	if err != nil {
+		log.Fatal("cannot parse verification keys: ", err)
+	}
+
+	if len(args) == 0 {
+		log.Fatal("usage: token-keys rotate | token-keys retire <key id>")
+	}
+
+	switch {
+	case args[0] == "rotate" && len(args) == 1:
+		newPrimaryKey, newVerificationKeys, err := rotate(token.Key{ID: cfg.TokenKeyID, Key: primaryKey}, verificationKeys)
+		if err != nil {
+			log.Fatal("cannot rotate keys: ", err)
+		}
+
+		fmt.Printf("TOKEN_KEY_ID=%s\n", newPrimaryKey.ID)
+		fmt.Printf("%s=%s\n", secretKeyEnv, newPrimaryKey.Key)
+		fmt.Printf("%s=%s\n", verificationKeysEnv, token.FormatKeys(newVerificationKeys))
+	case args[0] == "retire" && len(args) == 2:
+		newVerificationKeys, err := token.RetireKey(verificationKeys, args[1])
+		if err != nil {
+			log.Fatal("cannot retire key: ", err)
+		}
+
+		fmt.Printf("%s=%s\n", verificationKeysEnv, token.FormatKeys(newVerificationKeys))
+	default:
+		log.Fatal("usage: token-keys rotate | token-keys retire <key id>")
+	}
+}
+
+// runUnlockLoginCommand forgets failed login attempts of user or client IP.
+//
+//	unlock-login user <username>
+//	unlock-login ip <address>
+//
+// Only attempts kept in postgres can be unlocked, in-memory ones are unlocked by DELETE /admin/login-locks.
+func runUnlockLoginCommand(cfg util.Config, args []string) {
+	if cfg.LoginAttemptStore == "memory" {
+		log.Fatal("login attempts are kept in memory of the server, unlock them by DELETE /admin/login-locks/<user|ip>/<key>")
+	}
+
+	if len(args) != 2 {
+		log.Fatal("usage: unlock-login user <username> | unlock-login ip <address>")
+	}
+
+	var key string
+
+	switch args[0] {
+	case "user":
+		key = lockout.UsernameKey(args[1])
+	case "ip":
+		key = lockout.IPKey(args[1])
+	default:
+		log.Fatal("usage: unlock-login user <username> | unlock-login ip <address>")
+	}
+
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+	defer conn.Close()
+
+	attemptStore := lockout.NewPostgresStore(db.New(conn))
+
+	if err := attemptStore.ResetAttempts(context.Background(), key); err != nil {
+		log.Fatal("cannot unlock login: ", err)
+	}
+
+	fmt.Printf("%s unlocked\n", args[1])
+}
+
+// runCreateAdminCommand gives admin role to user, the user is created if it doesn't exist.
+//
+//	create-admin <username>
+//
+// Password of a new user is read from standard input.
+func runCreateAdminCommand(cfg util.Config, args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: create-admin <username>")
+	}
+
+	username := args[0]
+
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+	defer conn.Close()
+
+	runDBMigration(cfg.MigrationURL, cfg.DBSource)
+
+	store := db.NewStore(conn)
+
+	user, err := store.GetUser(context.Background(), username)
+	if err != nil && err != sql.ErrNoRows {
+		log.Fatal("cannot get user: ", err)
+	}
+
+	if err == sql.ErrNoRows {
+		user = createAdminUser(cfg, store, username)
+	}
+
+	params := db.SetUserRoleParams{
+		ID:   user.ID,
+		Role: db.RoleAdmin,
+	}
+
+	if _, err := store.SetUserRole(context.Background(), params); err != nil {
+		log.Fatal("cannot set role: ", err)
+	}
+
+	fmt.Printf("%s is admin\n", username)
+}
+
+func createAdminUser(cfg util.Config, store db.Store, username string) db.User {
+	passwordPolicy, err := util.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatal("cannot create password policy: ", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "password of %s: ", username)
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatal("cannot read password: ", err)
+	}
+
+	password = strings.TrimRight(password, "\r\n")
+
+	if err := passwordPolicy.Validate(password); err != nil {
+		log.Fatal("invalid password: ", err)
+	}
+
+	hash, err := util.HashPassword(password)
+	if err != nil {
+		log.Fatal("cannot hash password: ", err)
+	}
+
+	result, err := store.CreateUserTx(context.Background(), db.CreateUserTxParams{
+		Username: username,
+		Hash:     hash,
+	})
+	if err != nil {
+		log.Fatal("cannot create user: ", err)
+	}
+
+	return result.User
+}
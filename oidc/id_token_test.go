@@ -0,0 +1,76 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+// keySetCounter counts requests of key set made by provider
+type keySetCounter struct {
+	fetches atomic.Int32
+}
+
+func (c *keySetCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(request.URL.Path, "/jwks") {
+		c.fetches.Add(1)
+	}
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestKeyRefetchIsLimited(t *testing.T) {
+	issuer, err := oidctest.NewIssuer("simpletodo", "")
+	require.NoError(t, err)
+	defer issuer.Close()
+
+	counter := &keySetCounter{}
+
+	provider, err := NewProvider(context.Background(), Config{
+		IssuerURL: issuer.URL(),
+		ClientID:  "simpletodo",
+	}, &http.Client{Transport: counter})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = provider.key(ctx, "test-key")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counter.fetches.Load())
+
+	// unknown key ids don't make provider fetch key set again until interval is over
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := provider.key(ctx, "unknown-key")
+			require.ErrorIs(t, err, ErrUnknownKey)
+		}()
+	}
+
+	wg.Wait()
+	require.Equal(t, int32(1), counter.fetches.Load())
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-keySetRefetchInterval)
+	provider.mu.Unlock()
+
+	_, err = provider.key(ctx, "unknown-key")
+	require.ErrorIs(t, err, ErrUnknownKey)
+	require.Equal(t, int32(2), counter.fetches.Load())
+
+	// known key is still there
+	_, err = provider.key(ctx, "test-key")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), counter.fetches.Load())
+}
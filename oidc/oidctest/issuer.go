@@ -0,0 +1,265 @@
+// Package oidctest provides in-process OpenID Connect provider for tests
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const keyID = "test-key"
+
+// Identity is user which signs in at the issuer
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+type authorization struct {
+	identity      Identity
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+// Issuer is fake provider supporting authorization code flow with S256 PKCE for single client.
+// Its authorization endpoint signs in configured identity without any prompt
+type Issuer struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+
+	mu             sync.Mutex
+	identity       Identity
+	authorizations map[string]authorization
+}
+
+// NewIssuer starts issuer for client, empty secret means public client
+func NewIssuer(clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := &Issuer{
+		key:            key,
+		clientID:       clientID,
+		clientSecret:   clientSecret,
+		authorizations: make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/authorize", issuer.authorize)
+	mux.HandleFunc("/token", issuer.token)
+	mux.HandleFunc("/jwks", issuer.jwks)
+
+	issuer.server = httptest.NewServer(mux)
+
+	return issuer, nil
+}
+
+// URL returns issuer identifier
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+// Close stops the issuer
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+// SetIdentity sets identity signed in by the next authorization requests
+func (i *Issuer) SetIdentity(identity Identity) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.identity = identity
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.URL(),
+		"authorization_endpoint":                i.URL() + "/authorize",
+		"token_endpoint":                        i.URL() + "/token",
+		"jwks_uri":                              i.URL() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (i *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != i.clientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	i.mu.Lock()
+	i.authorizations[code] = authorization{
+		identity:      i.identity,
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+	i.mu.Unlock()
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeTokenError(w, "invalid_request")
+		return
+	}
+
+	clientID := r.PostForm.Get("client_id")
+	if username, password, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(username)
+		password, _ = url.QueryUnescape(password)
+
+		if password != i.clientSecret {
+			writeTokenError(w, "invalid_client")
+			return
+		}
+	} else if i.clientSecret != "" {
+		writeTokenError(w, "invalid_client")
+		return
+	}
+
+	if clientID != i.clientID || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeTokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	i.mu.Lock()
+	auth, ok := i.authorizations[code]
+	delete(i.authorizations, code)
+	i.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") ||
+		auth.codeChallenge != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken, err := i.signIDToken(auth)
+	if err != nil {
+		writeTokenError(w, "server_error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": keyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func (i *Issuer) signIDToken(auth authorization) (string, error) {
+	now := time.Now()
+
+	claims := map[string]any{
+		"iss":   i.URL(),
+		"sub":   auth.identity.Subject,
+		"aud":   i.clientID,
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": auth.nonce,
+	}
+
+	if auth.identity.Email != "" {
+		claims["email"] = auth.identity.Email
+		claims["email_verified"] = auth.identity.EmailVerified
+	}
+
+	if auth.identity.PreferredUsername != "" {
+		claims["preferred_username"] = auth.identity.PreferredUsername
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeTokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
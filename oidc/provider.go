@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var (
+	ErrIssuerMismatch = errors.New("issuer of discovery document doesn't match configured one")
+	ErrExchangeFailed = errors.New("cannot exchange authorization code")
+)
+
+// Config describes client registered at OpenID Connect provider
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string // empty for public clients, PKCE protects code exchange anyway
+	RedirectURL  string
+	Scopes       []string // "openid" is always requested
+}
+
+// Provider performs authorization code flow with PKCE against OpenID Connect provider
+type Provider struct {
+	config Config
+	client *http.Client
+
+	authorizationEndpoint string
+	tokenEndpoint         string
+	jwksURI               string
+
+	mu            sync.Mutex
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+	keysFetching  chan struct{} // closed when key set being fetched is stored, nil if there is no fetch
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewProvider fetches discovery document of issuer and creates provider for the client
+func NewProvider(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	issuer := strings.TrimSuffix(config.IssuerURL, "/")
+
+	var document discoveryDocument
+	if err := getJSON(ctx, client, issuer+discoveryPath, &document); err != nil {
+		return nil, fmt.Errorf("cannot get discovery document: %w", err)
+	}
+
+	if document.Issuer != issuer {
+		return nil, ErrIssuerMismatch
+	}
+
+	config.IssuerURL = issuer
+
+	return &Provider{
+		config:                config,
+		client:                client,
+		authorizationEndpoint: document.AuthorizationEndpoint,
+		tokenEndpoint:         document.TokenEndpoint,
+		jwksURI:               document.JWKSURI,
+		keys:                  make(map[string]*rsa.PublicKey),
+	}, nil
+}
+
+// Issuer returns issuer identifier which ID tokens of provider are bound to
+func (p *Provider) Issuer() string {
+	return p.config.IssuerURL
+}
+
+// AuthCodeURL returns URL of provider where user has to be redirected to authorize.
+// State and nonce are checked on callback, verifier is kept secret until code exchange
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	scopes := []string{"openid"}
+	for _, scope := range p.config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(p.authorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.authorizationEndpoint + separator + query.Encode()
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems authorization code and returns verified claims of its ID token
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, tokens.Error, tokens.ErrorDescription)
+	}
+
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: response has no id_token", ErrExchangeFailed)
+	}
+
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,168 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/PYTNAG/simpletodo/oidc"
+	"github.com/PYTNAG/simpletodo/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID     = "simpletodo"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost:8080/users/oidc/callback"
+)
+
+func newTestProvider(t *testing.T, clientSecret string) (*oidctest.Issuer, *oidc.Provider) {
+	issuer, err := oidctest.NewIssuer(testClientID, clientSecret)
+	require.NoError(t, err)
+	t.Cleanup(issuer.Close)
+
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		IssuerURL:    issuer.URL(),
+		ClientID:     testClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email"},
+	}, nil)
+	require.NoError(t, err)
+
+	return issuer, provider
+}
+
+// authorize follows authorization URL like browser does and returns query of callback
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusFound, response.StatusCode)
+
+	location, err := response.Location()
+	require.NoError(t, err)
+	require.Equal(t, testRedirectURL, location.Scheme+"://"+location.Host+location.Path)
+
+	return location.Query()
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	for _, clientSecret := range []string{testClientSecret, ""} {
+		issuer, provider := newTestProvider(t, clientSecret)
+
+		identity := oidctest.Identity{
+			Subject:           "subject",
+			Email:             "user@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "user",
+		}
+		issuer.SetIdentity(identity)
+
+		state, err := oidc.RandomString()
+		require.NoError(t, err)
+		nonce, err := oidc.RandomString()
+		require.NoError(t, err)
+		verifier, err := oidc.RandomString()
+		require.NoError(t, err)
+
+		callback := authorize(t, provider.AuthCodeURL(state, nonce, verifier))
+		require.Equal(t, state, callback.Get("state"))
+
+		claims, err := provider.Exchange(context.Background(), callback.Get("code"), verifier, nonce)
+		require.NoError(t, err)
+
+		require.Equal(t, issuer.URL(), claims.Issuer)
+		require.Equal(t, provider.Issuer(), claims.Issuer)
+		require.Equal(t, identity.Subject, claims.Subject)
+		require.Equal(t, identity.Email, claims.Email)
+		require.True(t, claims.EmailVerified)
+		require.Equal(t, identity.PreferredUsername, claims.PreferredUsername)
+
+		// code is single use
+		_, err = provider.Exchange(context.Background(), callback.Get("code"), verifier, nonce)
+		require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	issuer, provider := newTestProvider(t, testClientSecret)
+	issuer.SetIdentity(oidctest.Identity{Subject: "subject"})
+
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+
+	callback := authorize(t, provider.AuthCodeURL("state", "nonce", verifier))
+
+	otherVerifier, err := oidc.RandomString()
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(context.Background(), callback.Get("code"), otherVerifier, "nonce")
+	require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+}
+
+func TestExchangeWrongNonce(t *testing.T) {
+	issuer, provider := newTestProvider(t, testClientSecret)
+	issuer.SetIdentity(oidctest.Identity{Subject: "subject"})
+
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+
+	callback := authorize(t, provider.AuthCodeURL("state", "nonce", verifier))
+
+	_, err = provider.Exchange(context.Background(), callback.Get("code"), verifier, "other nonce")
+	require.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestExchangeWrongClientSecret(t *testing.T) {
+	issuer, err := oidctest.NewIssuer(testClientID, testClientSecret)
+	require.NoError(t, err)
+	defer issuer.Close()
+
+	provider, err := oidc.NewProvider(context.Background(), oidc.Config{
+		IssuerURL:    issuer.URL(),
+		ClientID:     testClientID,
+		ClientSecret: "wrong secret",
+		RedirectURL:  testRedirectURL,
+	}, nil)
+	require.NoError(t, err)
+
+	verifier, err := oidc.RandomString()
+	require.NoError(t, err)
+
+	callback := authorize(t, provider.AuthCodeURL("state", "nonce", verifier))
+
+	_, err = provider.Exchange(context.Background(), callback.Get("code"), verifier, "nonce")
+	require.ErrorIs(t, err, oidc.ErrExchangeFailed)
+}
+
+func TestNewProviderIssuerMismatch(t *testing.T) {
+	issuer, err := oidctest.NewIssuer(testClientID, "")
+	require.NoError(t, err)
+	defer issuer.Close()
+
+	issuerURL, err := url.Parse(issuer.URL())
+	require.NoError(t, err)
+
+	// same server under another name
+	issuerURL.Host = "localhost:" + issuerURL.Port()
+
+	_, err = oidc.NewProvider(context.Background(), oidc.Config{
+		IssuerURL: issuerURL.String(),
+		ClientID:  testClientID,
+	}, nil)
+	require.ErrorIs(t, err, oidc.ErrIssuerMismatch)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// base64url of sha256 without padding
+	require.Equal(t, "iMnq5o6zALKXGivsnlom_0F5_WYda32GHkxlV7mq7hQ", oidc.CodeChallenge("verifier"))
+}
@@ -0,0 +1,140 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+const (
+	keyIDLength    = 4
+	symmetricBytes = 16 // hex encoded key takes 32 bytes
+)
+
+// Key is a key of the maker's key ring identified by ID carried in token footer
+type Key struct {
+	ID  string
+	Key string
+}
+
+// GenerateSymmetricKey creates a new key with random ID
+func GenerateSymmetricKey() (Key, error) {
+	id, err := randomHex(keyIDLength)
+	if err != nil {
+		return Key{}, err
+	}
+
+	key, err := randomHex(symmetricBytes)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, Key: key}, nil
+}
+
+// GenerateSecretKey creates a new hex encoded Ed25519 secret key with random ID
+func GenerateSecretKey() (Key, error) {
+	id, err := randomHex(keyIDLength)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, Key: paseto.NewV4AsymmetricSecretKey().ExportHex()}, nil
+}
+
+// PublicKeyOf returns hex encoded public key of hex encoded Ed25519 secret key
+func PublicKeyOf(secretKey Key) (Key, error) {
+	key, err := paseto.NewV4AsymmetricSecretKeyFromHex(secretKey.Key)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: secretKey.ID, Key: key.Public().ExportHex()}, nil
+}
+
+// ParseKeys parses comma separated "<id>:<key>" pairs
+func ParseKeys(keys string) ([]Key, error) {
+	result := []Key{}
+
+	for _, pair := range strings.Split(keys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, key, found := strings.Cut(pair, ":")
+		if !found || id == "" || key == "" {
+			return nil, fmt.Errorf("invalid key pair %q, expected <id>:<key>", pair)
+		}
+
+		result = append(result, Key{ID: id, Key: key})
+	}
+
+	return result, nil
+}
+
+// FormatKeys is the inverse of ParseKeys
+func FormatKeys(keys []Key) string {
+	pairs := make([]string, len(keys))
+
+	for i, key := range keys {
+		pairs[i] = key.ID + ":" + key.Key
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// RotateSymmetricKeys generates a new primary key, the previous one is kept for verification only
+func RotateSymmetricKeys(primaryKey Key, verificationKeys []Key) (Key, []Key, error) {
+	newPrimaryKey, err := GenerateSymmetricKey()
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	return newPrimaryKey, append([]Key{primaryKey}, verificationKeys...), nil
+}
+
+// RotateSecretKeys generates a new secret key, public key of the previous one is kept for verification only
+func RotateSecretKeys(secretKey Key, verificationKeys []Key) (Key, []Key, error) {
+	publicKey, err := PublicKeyOf(secretKey)
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	newSecretKey, err := GenerateSecretKey()
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	return newSecretKey, append([]Key{publicKey}, verificationKeys...), nil
+}
+
+// RetireKey removes verification key, tokens created with it aren't accepted anymore
+func RetireKey(verificationKeys []Key, id string) ([]Key, error) {
+	result := []Key{}
+
+	for _, key := range verificationKeys {
+		if key.ID != id {
+			result = append(result, key)
+		}
+	}
+
+	if len(result) == len(verificationKeys) {
+		return nil, fmt.Errorf("there is no verification key %s", id)
+	}
+
+	return result, nil
+}
+
+func randomHex(n int) (string, error) {
+	bytes := make([]byte, n)
+
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
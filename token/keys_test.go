@@ -0,0 +1,39 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	expected := []Key{
+		{ID: "first", Key: "12345678901234567890123456789012"},
+		{ID: "second", Key: "abcdefabcdefabcdefabcdefabcdefab"},
+	}
+
+	keys, err = ParseKeys(FormatKeys(expected))
+	require.NoError(t, err)
+	require.Equal(t, expected, keys)
+
+	_, err = ParseKeys("first:12345678901234567890123456789012,invalid")
+	require.Error(t, err)
+}
+
+func TestRetireKey(t *testing.T) {
+	keys := []Key{
+		{ID: "first", Key: "12345678901234567890123456789012"},
+		{ID: "second", Key: "abcdefabcdefabcdefabcdefabcdefab"},
+	}
+
+	result, err := RetireKey(keys, "first")
+	require.NoError(t, err)
+	require.Equal(t, keys[1:], result)
+
+	_, err = RetireKey(keys, "unknown")
+	require.Error(t, err)
+}
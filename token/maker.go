@@ -0,0 +1,128 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+)
+
+var ErrUnknownKeyID = errors.New("token is signed with unknown key")
+
+// Maker creates and verifies tokens
+type Maker interface {
+	CreateToken(username string, userID int32, sessionID uuid.UUID, access Access, duration time.Duration) (string, *Payload, error)
+	VerifyToken(token string) (*Payload, error)
+}
+
+type footer struct {
+	KeyID string `json:"kid"`
+}
+
+// newPasetoToken fills token claims by payload and puts key id into footer
+func newPasetoToken(payload *Payload, keyID string) (*paseto.Token, error) {
+	footerBytes, err := json.Marshal(footer{KeyID: keyID})
+	if err != nil {
+		return nil, err
+	}
+
+	token := paseto.NewToken()
+
+	token.SetIssuedAt(payload.IssuedAt)
+	token.SetExpiration(payload.ExpiredAt)
+	token.SetString("username", payload.Username)
+	if err := token.Set("user_id", payload.UserID); err != nil {
+		return nil, err
+	}
+	token.SetString("uuid", payload.ID.String())
+	token.SetString("session_id", payload.SessionID.String())
+	if err := token.Set("scopes", payload.Scopes); err != nil {
+		return nil, err
+	}
+	if len(payload.ListIDs) > 0 {
+		if err := token.Set("list_ids", payload.ListIDs); err != nil {
+			return nil, err
+		}
+	}
+	token.SetFooter(footerBytes)
+
+	return &token, nil
+}
+
+func payloadFromToken(t *paseto.Token) (*Payload, error) {
+	payload := &Payload{}
+
+	var err error
+	payload.Username, err = t.GetString("username")
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens without user id were created before it, zero id means user has to be looked up by username
+	if err := t.Get("user_id", &payload.UserID); err != nil {
+		payload.UserID = 0
+	}
+
+	var uuidString string
+	uuidString, err = t.GetString("uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	payload.ID, err = uuid.Parse(uuidString)
+	if err != nil {
+		return nil, err
+	}
+
+	uuidString, err = t.GetString("session_id")
+	if err != nil {
+		return nil, err
+	}
+
+	payload.SessionID, err = uuid.Parse(uuidString)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens without scopes were created before scopes and could do everything
+	if err := t.Get("scopes", &payload.Scopes); err != nil {
+		payload.Access = FullAccess()
+	}
+
+	if err := t.Get("list_ids", &payload.ListIDs); err != nil {
+		payload.ListIDs = nil
+	}
+
+	payload.IssuedAt, err = t.GetIssuedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	payload.ExpiredAt, err = t.GetExpiration()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// footerKeyID reads key id from token footer, tokens without footer get defaultKeyID
+func footerKeyID(parser paseto.Parser, protocol paseto.Protocol, token, defaultKeyID string) (string, error) {
+	footerBytes, err := parser.UnsafeParseFooter(protocol, token)
+	if err != nil {
+		return "", err
+	}
+
+	if len(footerBytes) == 0 {
+		return defaultKeyID, nil
+	}
+
+	var f footer
+	if err := json.Unmarshal(footerBytes, &f); err != nil {
+		return "", err
+	}
+
+	return f.KeyID, nil
+}
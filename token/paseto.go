@@ -2,89 +2,83 @@ package token
 
 import (
 	"encoding/hex"
+	"fmt"
 	"time"
 
 	"aidanwoods.dev/go-paseto"
 	"github.com/google/uuid"
 )
 
-const RandomSymmetricKey = "\x00"
+const RandomKey = "\x00"
 
+// PasetoMaker creates v4.local tokens encrypted with symmetric key
 type PasetoMaker struct {
-	token        paseto.Token
-	symmetricKey paseto.V4SymmetricKey
+	primaryKeyID string
+	keys         map[string]paseto.V4SymmetricKey
 }
 
-func NewPasetoMaker(symmetricKey string) (*PasetoMaker, error) {
+// NewPasetoMaker creates maker which signs tokens with primary key and verifies them with any key of the ring
+func NewPasetoMaker(primaryKey Key, verificationKeys ...Key) (*PasetoMaker, error) {
 	maker := &PasetoMaker{
-		token: paseto.NewToken(),
+		primaryKeyID: primaryKey.ID,
+		keys:         make(map[string]paseto.V4SymmetricKey, len(verificationKeys)+1),
 	}
 
-	if symmetricKey == RandomSymmetricKey {
-		maker.symmetricKey = paseto.NewV4SymmetricKey()
-	} else {
-		var err error
-		maker.symmetricKey, err = paseto.V4SymmetricKeyFromHex(hex.EncodeToString([]byte(symmetricKey)))
+	for _, key := range append([]Key{primaryKey}, verificationKeys...) {
+		if _, ok := maker.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated key id %s", key.ID)
+		}
+
+		if key.Key == RandomKey {
+			maker.keys[key.ID] = paseto.NewV4SymmetricKey()
+			continue
+		}
+
+		symmetricKey, err := paseto.V4SymmetricKeyFromHex(hex.EncodeToString([]byte(key.Key)))
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid key %s: %w", key.ID, err)
 		}
 
+		maker.keys[key.ID] = symmetricKey
 	}
 
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, userID int32, sessionID uuid.UUID, access Access, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, userID, sessionID, access, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
-	maker.token.SetIssuedAt(payload.IssuedAt)
-	maker.token.SetExpiration(payload.ExpiredAt)
-	maker.token.SetString("username", payload.Username)
-	maker.token.SetString("uuid", payload.ID.String())
+	token, err := newPasetoToken(payload, maker.primaryKeyID)
+	if err != nil {
+		return "", payload, err
+	}
 
-	return maker.token.V4Encrypt(maker.symmetricKey, nil), payload, nil
+	return token.V4Encrypt(maker.keys[maker.primaryKeyID], nil), payload, nil
 }
 
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
-	payload := &Payload{}
-
 	parser := paseto.NewParser()
 
 	parser.AddRule(paseto.NotExpired())
 
-	t, err := parser.ParseV4Local(maker.symmetricKey, token, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	payload.Username, err = t.GetString("username")
-	if err != nil {
-		return nil, err
-	}
-
-	var uuidString string
-	uuidString, err = t.GetString("uuid")
+	// tokens without footer were created before key ring and use primary key
+	keyID, err := footerKeyID(parser, paseto.V4Local, token, maker.primaryKeyID)
 	if err != nil {
 		return nil, err
 	}
 
-	payload.ID, err = uuid.Parse(uuidString)
-	if err != nil {
-		return nil, err
-	}
-
-	payload.IssuedAt, err = t.GetIssuedAt()
-	if err != nil {
-		return nil, err
+	symmetricKey, ok := maker.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
 	}
 
-	payload.ExpiredAt, err = t.GetExpiration()
+	t, err := parser.ParseV4Local(symmetricKey, token, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return payload, nil
+	return payloadFromToken(t)
 }
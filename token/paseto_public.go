@@ -0,0 +1,110 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+)
+
+// PublicPasetoMaker creates v4.public tokens signed with Ed25519 secret key,
+// so any service with public key can verify them
+type PublicPasetoMaker struct {
+	primaryKeyID string
+	secretKey    paseto.V4AsymmetricSecretKey
+	publicKeys   map[string]paseto.V4AsymmetricPublicKey
+}
+
+// NewPublicPasetoMaker creates maker which signs tokens with secret key and verifies them with
+// its public key or any of verification public keys. Keys are hex encoded
+func NewPublicPasetoMaker(secretKey Key, verificationKeys ...Key) (*PublicPasetoMaker, error) {
+	maker := &PublicPasetoMaker{
+		primaryKeyID: secretKey.ID,
+		publicKeys:   make(map[string]paseto.V4AsymmetricPublicKey, len(verificationKeys)+1),
+	}
+
+	if secretKey.Key == RandomKey {
+		maker.secretKey = paseto.NewV4AsymmetricSecretKey()
+	} else {
+		var err error
+		maker.secretKey, err = paseto.NewV4AsymmetricSecretKeyFromHex(secretKey.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret key %s: %w", secretKey.ID, err)
+		}
+	}
+
+	maker.publicKeys[secretKey.ID] = maker.secretKey.Public()
+
+	for _, key := range verificationKeys {
+		if _, ok := maker.publicKeys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated key id %s", key.ID)
+		}
+
+		publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", key.ID, err)
+		}
+
+		maker.publicKeys[key.ID] = publicKey
+	}
+
+	return maker, nil
+}
+
+func (maker *PublicPasetoMaker) CreateToken(username string, userID int32, sessionID uuid.UUID, access Access, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, userID, sessionID, access, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := newPasetoToken(payload, maker.primaryKeyID)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return token.V4Sign(maker.secretKey, nil), payload, nil
+}
+
+func (maker *PublicPasetoMaker) VerifyToken(token string) (*Payload, error) {
+	parser := paseto.NewParser()
+
+	parser.AddRule(paseto.NotExpired())
+
+	keyID, err := footerKeyID(parser, paseto.V4Public, token, maker.primaryKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := maker.publicKeys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	t, err := parser.ParseV4Public(publicKey, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return payloadFromToken(t)
+}
+
+// PublicKeys returns hex encoded public keys which tokens can be verified with, primary key goes first
+func (maker *PublicPasetoMaker) PublicKeys() []Key {
+	keys := make([]Key, 0, len(maker.publicKeys))
+
+	for id, publicKey := range maker.publicKeys {
+		keys = append(keys, Key{ID: id, Key: publicKey.ExportHex()})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ID == maker.primaryKeyID || keys[j].ID == maker.primaryKeyID {
+			return keys[i].ID == maker.primaryKeyID
+		}
+
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys
+}
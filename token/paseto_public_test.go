@@ -0,0 +1,100 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicPasetoMaker(t *testing.T) {
+	maker, err := NewPublicPasetoMaker(Key{ID: "test", Key: RandomKey})
+	require.NoError(t, err)
+
+	username := util.RandomUsername()
+	userID := util.RandomID()
+	sessionID := uuid.New()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, userID, sessionID, FullAccess(), duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+	// local token is not accepted by public maker
+	localMaker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
+	require.NoError(t, err)
+
+	localToken, _, err := localMaker.CreateToken(username, userID, sessionID, FullAccess(), duration)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(localToken)
+	require.Error(t, err)
+	require.Nil(t, payload)
+}
+
+func TestPublicKeyRotation(t *testing.T) {
+	oldKey, err := GenerateSecretKey()
+	require.NoError(t, err)
+
+	oldMaker, err := NewPublicPasetoMaker(oldKey)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), time.Minute)
+	require.NoError(t, err)
+
+	newKey, publicKeys, err := RotateSecretKeys(oldKey, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, oldKey.ID, newKey.ID)
+	require.Equal(t, oldMaker.PublicKeys(), publicKeys)
+
+	newMaker, err := NewPublicPasetoMaker(newKey, publicKeys...)
+	require.NoError(t, err)
+
+	keys := newMaker.PublicKeys()
+	require.Len(t, keys, 2)
+	require.Equal(t, newKey.ID, keys[0].ID)
+	require.Equal(t, oldKey.ID, keys[1].ID)
+
+	// verifier which knows only public keys accepts tokens of both secret keys
+	verifier, err := NewPublicPasetoMaker(Key{ID: "verifier", Key: RandomKey}, keys...)
+	require.NoError(t, err)
+
+	newToken, _, err := newMaker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	maker, err := NewPublicPasetoMaker(Key{ID: "test", Key: "invalid"})
+	require.Error(t, err)
+	require.Nil(t, maker)
+
+	maker, err = NewPublicPasetoMaker(Key{ID: "test", Key: RandomKey}, Key{ID: "other", Key: "invalid"})
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
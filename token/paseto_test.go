@@ -5,20 +5,23 @@ import (
 	"time"
 
 	"github.com/PYTNAG/simpletodo/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPasetoMaker(t *testing.T) {
-	maker, err := NewPasetoMaker(RandomSymmetricKey)
+	maker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
 	require.NoError(t, err)
 
 	username := util.RandomUsername()
+	userID := util.RandomID()
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, duration)
+	token, payload, err := maker.CreateToken(username, userID, sessionID, FullAccess(), duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -29,15 +32,54 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+	require.Equal(t, FullAccess(), payload.Access)
+}
+
+func TestScopedToken(t *testing.T) {
+	maker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
+	require.NoError(t, err)
+
+	access := Access{
+		Scopes:  []string{ScopeListsRead, ScopeTasksRead},
+		ListIDs: []int32{1, 2},
+	}
+
+	token, _, err := maker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), access, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, access, payload.Access)
+	require.False(t, payload.HasScope(ScopeAccountAdmin))
+}
+
+func TestTokenWithoutUserID(t *testing.T) {
+	maker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), time.Minute)
+	require.NoError(t, err)
+
+	legacyToken, err := newPasetoToken(payload, maker.primaryKeyID)
+	require.NoError(t, err)
+
+	// tokens created before user id was added to payload
+	legacyToken.Set("user_id", nil)
+
+	payload, err = maker.VerifyToken(legacyToken.V4Encrypt(maker.keys[maker.primaryKeyID], nil))
+	require.NoError(t, err)
+	require.Zero(t, payload.UserID)
 }
 
 func TestExpiredToken(t *testing.T) {
-	maker, err := NewPasetoMaker(RandomSymmetricKey)
+	maker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomUsername(), -time.Minute)
+	token, payload, err := maker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -46,3 +88,57 @@ func TestExpiredToken(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, payload)
 }
+
+func TestKeyRing(t *testing.T) {
+	oldKey, err := GenerateSymmetricKey()
+	require.NoError(t, err)
+
+	oldMaker, err := NewPasetoMaker(oldKey)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), time.Minute)
+	require.NoError(t, err)
+
+	newKey, verificationKeys, err := RotateSymmetricKeys(oldKey, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, oldKey.ID, newKey.ID)
+	require.Equal(t, []Key{oldKey}, verificationKeys)
+
+	newMaker, err := NewPasetoMaker(newKey, verificationKeys...)
+	require.NoError(t, err)
+
+	// old tokens are still valid until old key is retired
+	payload, err := newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	// new tokens are signed with the new primary key
+	newToken, _, err := newMaker.CreateToken(util.RandomUsername(), util.RandomID(), uuid.New(), FullAccess(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+
+	verificationKeys, err = RetireKey(verificationKeys, oldKey.ID)
+	require.NoError(t, err)
+	require.Empty(t, verificationKeys)
+
+	retiredMaker, err := NewPasetoMaker(newKey, verificationKeys...)
+	require.NoError(t, err)
+
+	payload, err = retiredMaker.VerifyToken(oldToken)
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+	require.Nil(t, payload)
+
+	_, err = retiredMaker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestDuplicatedKeyID(t *testing.T) {
+	key, err := GenerateSymmetricKey()
+	require.NoError(t, err)
+
+	maker, err := NewPasetoMaker(key, key)
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
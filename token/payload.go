@@ -9,11 +9,14 @@ import (
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	UserID    int32     `json:"user_id"`
+	SessionID uuid.UUID `json:"session_id"`
+	Access
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, userID int32, sessionID uuid.UUID, access Access, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -22,6 +25,9 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		UserID:    userID,
+		SessionID: sessionID,
+		Access:    access,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
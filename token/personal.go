@@ -0,0 +1,35 @@
+package token
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+const (
+	// PersonalAccessTokenPrefix distinguishes personal access tokens from PASETO tokens
+	PersonalAccessTokenPrefix = "pat_"
+
+	personalAccessTokenBytes = 32
+)
+
+// GeneratePersonalAccessToken creates a new random personal access token
+func GeneratePersonalAccessToken() (string, error) {
+	secret, err := randomHex(personalAccessTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return PersonalAccessTokenPrefix + secret, nil
+}
+
+// IsPersonalAccessToken reports whether token looks like personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// HashPersonalAccessToken returns hash which personal access token is stored and looked up by.
+// Tokens are random so fast hash is enough
+func HashPersonalAccessToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
@@ -0,0 +1,29 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	token, err := GeneratePersonalAccessToken()
+	require.NoError(t, err)
+	require.True(t, IsPersonalAccessToken(token))
+
+	other, err := GeneratePersonalAccessToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+
+	require.Equal(t, HashPersonalAccessToken(token), HashPersonalAccessToken(token))
+	require.NotEqual(t, HashPersonalAccessToken(token), HashPersonalAccessToken(other))
+
+	maker, err := NewPasetoMaker(Key{ID: "test", Key: RandomKey})
+	require.NoError(t, err)
+
+	pasetoToken, _, err := maker.CreateToken("user", 1, uuid.Nil, FullAccess(), time.Minute)
+	require.NoError(t, err)
+	require.False(t, IsPersonalAccessToken(pasetoToken))
+}
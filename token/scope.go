@@ -0,0 +1,86 @@
+package token
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	ScopeListsRead    = "lists:read"
+	ScopeListsWrite   = "lists:write"
+	ScopeTasksRead    = "tasks:read"
+	ScopeTasksWrite   = "tasks:write"
+	ScopeAccountAdmin = "account:admin"
+)
+
+// ScopeLoginChallenge is given only to the challenge token of login with two factor authentication,
+// it's not accepted as access token
+const ScopeLoginChallenge = "login:2fa"
+
+// Scopes lists all supported scopes
+var Scopes = []string{
+	ScopeListsRead,
+	ScopeListsWrite,
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeAccountAdmin,
+}
+
+// Access restricts what token is allowed to do
+type Access struct {
+	Scopes  []string `json:"scopes"`
+	ListIDs []int32  `json:"list_ids,omitempty"` // empty means all lists of the user
+}
+
+// FullAccess allows everything, it's given to tokens which are created without explicit scopes
+func FullAccess() Access {
+	return Access{Scopes: slices.Clone(Scopes)}
+}
+
+// NewAccess validates scopes, no scopes means full access
+func NewAccess(scopes []string, listIDs []int32) (Access, error) {
+	if len(scopes) == 0 {
+		return Access{Scopes: slices.Clone(Scopes), ListIDs: listIDs}, nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return Access{}, fmt.Errorf("unknown scope %s", scope)
+		}
+	}
+
+	return Access{Scopes: scopes, ListIDs: listIDs}, nil
+}
+
+func (a Access) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+func (a Access) AllowsList(listID int32) bool {
+	return len(a.ListIDs) == 0 || slices.Contains(a.ListIDs, listID)
+}
+
+// Includes reports whether other access doesn't allow anything this one doesn't
+func (a Access) Includes(other Access) bool {
+	for _, scope := range other.Scopes {
+		if !a.HasScope(scope) {
+			return false
+		}
+	}
+
+	if len(a.ListIDs) == 0 {
+		return true
+	}
+
+	if len(other.ListIDs) == 0 {
+		return false
+	}
+
+	for _, listID := range other.ListIDs {
+		if !a.AllowsList(listID) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,39 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccess(t *testing.T) {
+	access, err := NewAccess(nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, FullAccess(), access)
+
+	access, err = NewAccess([]string{ScopeListsRead, ScopeTasksRead}, []int32{1, 2})
+	require.NoError(t, err)
+	require.True(t, access.HasScope(ScopeListsRead))
+	require.False(t, access.HasScope(ScopeAccountAdmin))
+	require.True(t, access.AllowsList(1))
+	require.False(t, access.AllowsList(3))
+
+	_, err = NewAccess([]string{"unknown"}, nil)
+	require.Error(t, err)
+}
+
+func TestAccessIncludes(t *testing.T) {
+	full := FullAccess()
+	readOnly := Access{Scopes: []string{ScopeListsRead, ScopeTasksRead}}
+	restricted := Access{Scopes: []string{ScopeListsRead}, ListIDs: []int32{1, 2}}
+
+	require.True(t, full.Includes(readOnly))
+	require.True(t, full.Includes(restricted))
+	require.False(t, readOnly.Includes(full))
+	require.True(t, readOnly.Includes(restricted))
+
+	// restricted access can't grant access to all lists or other lists
+	require.False(t, restricted.Includes(Access{Scopes: []string{ScopeListsRead}}))
+	require.False(t, restricted.Includes(Access{Scopes: []string{ScopeListsRead}, ListIDs: []int32{3}}))
+	require.True(t, restricted.Includes(Access{Scopes: []string{ScopeListsRead}, ListIDs: []int32{2}}))
+}
@@ -0,0 +1,16 @@
+package token
+
+import "crypto/sha256"
+
+const shareTokenBytes = 32
+
+// GenerateShareToken creates a new random token of list share link
+func GenerateShareToken() (string, error) {
+	return randomHex(shareTokenBytes)
+}
+
+// HashShareToken returns hash which share token is stored and looked up by
+func HashShareToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
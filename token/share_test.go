@@ -0,0 +1,20 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareToken(t *testing.T) {
+	token, err := GenerateShareToken()
+	require.NoError(t, err)
+	require.Len(t, token, 2*shareTokenBytes)
+
+	other, err := GenerateShareToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+
+	require.Equal(t, HashShareToken(token), HashShareToken(token))
+	require.NotEqual(t, HashShareToken(token), HashShareToken(other))
+}
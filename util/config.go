@@ -7,13 +7,46 @@ import (
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	MigrationURL         string        `mapstructure:"MIGRATION_URL"`
-	ServerAddr           string        `mapstructure:"SERVER_ADDR"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstrucutre:"REFRESH_TOKEN_DURATION"`
+	DBDriver              string        `mapstructure:"DB_DRIVER"`
+	DBSource              string        `mapstructure:"DB_SOURCE"`
+	MigrationURL          string        `mapstructure:"MIGRATION_URL"`
+	ServerAddr            string        `mapstructure:"SERVER_ADDR"`
+	TrustedProxies        string        `mapstructure:"TRUSTED_PROXIES"` // comma separated IPs or CIDRs, client IP is taken from forwarding headers only behind them
+	TokenMode             string        `mapstructure:"TOKEN_MODE"`      // "local" (default) or "public"
+	TokenKeyID            string        `mapstructure:"TOKEN_KEY_ID"`
+	TokenSymmetricKey     string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenVerificationKeys string        `mapstructure:"TOKEN_VERIFICATION_KEYS"` // comma separated "<key id>:<key>" pairs
+	TokenSecretKey        string        `mapstructure:"TOKEN_SECRET_KEY"`        // hex encoded Ed25519 secret key, public mode only
+	TokenPublicKeys       string        `mapstructure:"TOKEN_PUBLIC_KEYS"`       // comma separated "<key id>:<hex public key>" pairs, public mode only
+	AccessTokenDuration   time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration  time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+
+	PasswordResetTokenDuration     time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	EmailVerificationTokenDuration time.Duration `mapstructure:"EMAIL_VERIFICATION_TOKEN_DURATION"`
+
+	PasswordMinLength      int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength      int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordMinCharClasses int    `mapstructure:"PASSWORD_MIN_CHAR_CLASSES"` // of lowercase, uppercase, digits and others
+	PasswordBlocklistFile  string `mapstructure:"PASSWORD_BLOCKLIST_FILE"`   // one common password per line, no blocklist if empty
+
+	LoginAttemptStore    string        `mapstructure:"LOGIN_ATTEMPT_STORE"` // "postgres" (default) or "memory"
+	LoginFreeAttempts    int32         `mapstructure:"LOGIN_FREE_ATTEMPTS"`
+	LoginBackoffBase     time.Duration `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginMaxAttempts     int32         `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	MailerKind        string `mapstructure:"MAILER"`    // "file" (default) or "smtp"
+	MailFile          string `mapstructure:"MAIL_FILE"` // file mailer writes to stdout if empty
+	MailSenderAddress string `mapstructure:"MAIL_SENDER_ADDRESS"`
+	SMTPAddr          string `mapstructure:"SMTP_ADDR"` // "<host>:<port>"
+	SMTPUsername      string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword      string `mapstructure:"SMTP_PASSWORD"`
+
+	OIDCIssuerURL           string        `mapstructure:"OIDC_ISSUER_URL"` // OpenID Connect login is disabled if empty
+	OIDCClientID            string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret        string        `mapstructure:"OIDC_CLIENT_SECRET"` // empty for public client
+	OIDCRedirectURL         string        `mapstructure:"OIDC_REDIRECT_URL"`  // "<server>/users/oidc/callback" registered at provider
+	OIDCAuthRequestDuration time.Duration `mapstructure:"OIDC_AUTH_REQUEST_DURATION"`
 }
 
 func LoadConfig(path string) (cfg Config, err error) {
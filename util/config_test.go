@@ -0,0 +1,23 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	env := "ACCESS_TOKEN_DURATION=15m\nREFRESH_TOKEN_DURATION=24h\nLOGIN_FREE_ATTEMPTS=3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.env"), []byte(env), 0o600))
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	require.Equal(t, 15*time.Minute, config.AccessTokenDuration)
+	require.Equal(t, 24*time.Hour, config.RefreshTokenDuration)
+	require.Equal(t, int32(3), config.LoginFreeAttempts)
+}
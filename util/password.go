@@ -1,20 +1,160 @@
 package util
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const mailTokenBytes = 32
+
+// argon2id parameters recommended by OWASP
+const (
+	argon2Memory  = 19 * 1024 // KiB
+	argon2Time    = 2
+	argon2Threads = 1
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+var argon2Prefix = []byte("$argon2id$")
+
+var (
+	ErrMismatchedPassword = errors.New("password doesn't match hash")
+	ErrUnknownHash        = errors.New("unknown password hash format")
+)
+
+var argon2Encoding = base64.RawStdEncoding
+
+// HashPassword returns argon2id hash of password encoded in PHC string format
 func HashPassword(password string) ([]byte, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return hash, nil
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	hash := fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		argon2Encoding.EncodeToString(salt), argon2Encoding.EncodeToString(key),
+	)
+
+	return []byte(hash), nil
 }
 
+// CheckPassword compares password with argon2id or legacy bcrypt hash
 func CheckPassword(password string, hash []byte) error {
-	return bcrypt.CompareHashAndPassword(hash, []byte(password))
+	if !bytes.HasPrefix(hash, argon2Prefix) {
+		err := bcrypt.CompareHashAndPassword(hash, []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return ErrMismatchedPassword
+		}
+
+		return err
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return ErrMismatchedPassword
+	}
+
+	return nil
+}
+
+// NeedsRehash reports whether hash isn't argon2id with current parameters,
+// so it has to be replaced after successful check of password
+func NeedsRehash(hash []byte) bool {
+	if !bytes.HasPrefix(hash, argon2Prefix) {
+		return true
+	}
+
+	params, _, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params != currentArgon2Params || len(key) != argon2KeyLen
+}
+
+type argon2Params struct {
+	version int
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+var currentArgon2Params = argon2Params{
+	version: argon2.Version,
+	memory:  argon2Memory,
+	time:    argon2Time,
+	threads: argon2Threads,
+}
+
+func decodeArgon2Hash(hash []byte) (params argon2Params, salt, key []byte, err error) {
+	parts := bytes.Split(hash[len(argon2Prefix):], []byte("$"))
+	if len(parts) != 4 {
+		err = ErrUnknownHash
+		return
+	}
+
+	if _, err = fmt.Sscanf(string(parts[0]), "v=%d", &params.version); err != nil {
+		err = ErrUnknownHash
+		return
+	}
+
+	if params.version != argon2.Version {
+		err = fmt.Errorf("unsupported argon2 version %d", params.version)
+		return
+	}
+
+	_, err = fmt.Sscanf(string(parts[1]), "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads)
+	if err != nil || params.memory == 0 || params.time == 0 || params.threads == 0 {
+		err = ErrUnknownHash
+		return
+	}
+
+	if salt, err = argon2Encoding.DecodeString(string(parts[2])); err != nil {
+		err = ErrUnknownHash
+		return
+	}
+
+	if key, err = argon2Encoding.DecodeString(string(parts[3])); err != nil || len(key) == 0 {
+		err = ErrUnknownHash
+		return
+	}
+
+	return
+}
+
+// GenerateMailToken creates a new random hex encoded token to be mailed to user,
+// e.g. for password reset or email verification
+func GenerateMailToken() (string, error) {
+	token := make([]byte, mailTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// HashMailToken returns hash which mailed token is stored and looked up by
+func HashMailToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
 }
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultPasswordMinLength      = 8
+	DefaultPasswordMaxLength      = 128
+	DefaultPasswordMinCharClasses = 1
+)
+
+var ErrCommonPassword = errors.New("password is too common")
+
+// PasswordPolicy validates passwords chosen by users
+type PasswordPolicy struct {
+	MinLength      int // in characters
+	MaxLength      int
+	MinCharClasses int // lowercase and uppercase letters, digits and other characters
+	blocklist      map[string]struct{}
+}
+
+// NewPasswordPolicy creates policy from config, zero values are replaced by defaults.
+// Blocklist file contains one common or breached password per line, it's compared case-insensitively
+func NewPasswordPolicy(config Config) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		MinLength:      config.PasswordMinLength,
+		MaxLength:      config.PasswordMaxLength,
+		MinCharClasses: config.PasswordMinCharClasses,
+		blocklist:      make(map[string]struct{}),
+	}
+
+	if policy.MinLength == 0 {
+		policy.MinLength = DefaultPasswordMinLength
+	}
+
+	if policy.MaxLength == 0 {
+		policy.MaxLength = DefaultPasswordMaxLength
+	}
+
+	if policy.MinCharClasses == 0 {
+		policy.MinCharClasses = DefaultPasswordMinCharClasses
+	}
+
+	if policy.MinLength > policy.MaxLength {
+		return nil, fmt.Errorf("minimal password length %d is greater than maximal %d", policy.MinLength, policy.MaxLength)
+	}
+
+	if config.PasswordBlocklistFile == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(config.PasswordBlocklistFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open password blocklist: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			policy.blocklist[strings.ToLower(password)] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read password blocklist: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Validate returns error describing the first rule which password breaks
+func (p *PasswordPolicy) Validate(password string) error {
+	if !utf8.ValidString(password) {
+		return errors.New("password must be valid UTF-8")
+	}
+
+	length := utf8.RuneCountInString(password)
+
+	if length < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	if length > p.MaxLength {
+		return fmt.Errorf("password must be at most %d characters long", p.MaxLength)
+	}
+
+	if classes := charClasses(password); classes < p.MinCharClasses {
+		return fmt.Errorf("password must contain at least %d of: lowercase letters, uppercase letters, digits, other characters", p.MinCharClasses)
+	}
+
+	if _, ok := p.blocklist[strings.ToLower(password)]; ok {
+		return ErrCommonPassword
+	}
+
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	blocklistFile := filepath.Join(t.TempDir(), "blocklist.txt")
+	err := os.WriteFile(blocklistFile, []byte("password123\n\n  Qwerty123  \n"), 0o600)
+	require.NoError(t, err)
+
+	policy, err := NewPasswordPolicy(Config{
+		PasswordMinLength:      8,
+		PasswordMaxLength:      16,
+		PasswordMinCharClasses: 2,
+		PasswordBlocklistFile:  blocklistFile,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"OK", "correct1horse", true},
+		{"Unicode", "пароль-пароль", true},
+		{"TooShort", "abc1", false},
+		{"TooLong", strings.Repeat("a1", 9), false},
+		{"OneCharClass", "correcthorse", false},
+		{"Blocklisted", "password123", false},
+		{"BlocklistedCaseInsensitive", "QWERTY123", false},
+		{"InvalidUTF8", "abcdefg1\xff", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate(tc.password)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestDefaultPasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Validate(strings.Repeat("a", DefaultPasswordMinLength)))
+	require.Error(t, policy.Validate(strings.Repeat("a", DefaultPasswordMinLength-1)))
+	require.Error(t, policy.Validate(strings.Repeat("a", DefaultPasswordMaxLength+1)))
+
+	_, err = NewPasswordPolicy(Config{PasswordBlocklistFile: filepath.Join(t.TempDir(), "missing.txt")})
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(Config{PasswordMinLength: 10, PasswordMaxLength: 9})
+	require.Error(t, err)
+}
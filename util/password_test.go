@@ -19,7 +19,7 @@ func TestPassword(t *testing.T) {
 
 	wrongPassword := RandomPassword()
 	err = CheckPassword(wrongPassword, hash)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.ErrorIs(t, err, ErrMismatchedPassword)
 
 	repeatedHash, err := HashPassword(password)
 	require.NoError(t, err)
@@ -27,10 +27,49 @@ func TestPassword(t *testing.T) {
 	require.NotEqual(t, hash, repeatedHash)
 }
 
-func TestTooLongPassword(t *testing.T) {
-	password := RandomString(73)
+func TestLongPassword(t *testing.T) {
+	password := RandomString(256)
 
 	hash, err := HashPassword(password)
-	require.Error(t, err)
-	require.Empty(t, hash)
+	require.NoError(t, err)
+	require.NoError(t, CheckPassword(password, hash))
+
+	// unlike bcrypt, characters after 72nd byte matter
+	require.ErrorIs(t, CheckPassword(password[:255]+"x", hash), ErrMismatchedPassword)
+}
+
+func TestLegacyBcryptPassword(t *testing.T) {
+	password := RandomPassword()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	require.NoError(t, CheckPassword(password, hash))
+	require.ErrorIs(t, CheckPassword(RandomPassword(), hash), ErrMismatchedPassword)
+	require.True(t, NeedsRehash(hash))
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hash, err := HashPassword(RandomPassword())
+	require.NoError(t, err)
+	require.False(t, NeedsRehash(hash))
+
+	weakerHash := []byte("$argon2id$v=19$m=4096,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$aGFzaGhhc2hoYXNoaGFzaGhhc2hoYXNoaGFzaGhhc2g")
+	require.True(t, NeedsRehash(weakerHash))
+
+	require.True(t, NeedsRehash([]byte("$argon2id$broken")))
+	require.ErrorIs(t, CheckPassword(RandomPassword(), []byte("$argon2id$broken")), ErrUnknownHash)
+}
+
+func TestMailToken(t *testing.T) {
+	token, err := GenerateMailToken()
+	require.NoError(t, err)
+	require.Len(t, token, 2*mailTokenBytes)
+
+	otherToken, err := GenerateMailToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, otherToken)
+
+	require.Equal(t, HashMailToken(token), HashMailToken(token))
+	require.NotEqual(t, HashMailToken(token), HashMailToken(otherToken))
 }
@@ -5,21 +5,28 @@ import (
 	"strings"
 )
 
-const alphabet = "abcdefghijklmnopqrstuvwxyz_()!$"
+const (
+	letters  = "abcdefghijklmnopqrstuvwxyz"
+	alphabet = letters + "_()!$"
+)
 
-// RandomString generates a random string of length n
-func RandomString(n int) string {
+func randomString(chars string, n int) string {
 	var sb strings.Builder
-	k := len(alphabet)
+	k := len(chars)
 
 	for i := 0; i < n; i++ {
-		c := alphabet[rand.Intn(k)]
+		c := chars[rand.Intn(k)]
 		sb.WriteByte(c)
 	}
 
 	return sb.String()
 }
 
+// RandomString generates a random string of length n
+func RandomString(n int) string {
+	return randomString(alphabet, n)
+}
+
 // RandomUsername generates a random string of length 24
 func RandomUsername() string {
 	return RandomString(24)
@@ -30,6 +37,11 @@ func RandomPassword() string {
 	return RandomString(16)
 }
 
+// RandomEmail generates a random email address
+func RandomEmail() string {
+	return randomString(letters, 16) + "@example.com"
+}
+
 // RandomID generates a random positive int32
 func RandomID() int32 {
 	res := rand.Int31()
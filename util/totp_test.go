@@ -0,0 +1,94 @@
+package util
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// secret of RFC 6238 test vectors for SHA1
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, last 6 digits of 8 digit codes
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tc := range testCases {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+
+	_, err := TOTPCode("not base32!", 1)
+	require.Error(t, err)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	// fake clock
+	now := time.Unix(1700000000, 0)
+
+	code, err := TOTPCode(secret, TOTPStep(now))
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(code, secret, now)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now), step)
+
+	// previous and next periods are accepted for clock skew
+	_, ok = ValidateTOTP(code, secret, now.Add(TOTPPeriod))
+	require.True(t, ok)
+
+	_, ok = ValidateTOTP(code, secret, now.Add(-TOTPPeriod))
+	require.True(t, ok)
+
+	_, ok = ValidateTOTP(code, secret, now.Add(3*TOTPPeriod))
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP("000000x", secret, now)
+	require.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("simpletodo", "user", rfcSecret)
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", parsed.Scheme)
+	require.Equal(t, "totp", parsed.Host)
+	require.Equal(t, "/simpletodo:user", parsed.Path)
+	require.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	require.Equal(t, "simpletodo", parsed.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodesCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 2*recoveryCodeBytes+1)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	require.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+	require.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
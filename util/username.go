@@ -0,0 +1,35 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MaxUsernameLength = 64
+
+// ValidateUsername returns error describing the first rule which username breaks.
+// Usernames can't contain spaces or control characters, so different users don't look the same
+func ValidateUsername(username string) error {
+	if !utf8.ValidString(username) {
+		return errors.New("username must be valid UTF-8")
+	}
+
+	length := utf8.RuneCountInString(username)
+
+	if length == 0 {
+		return errors.New("username must not be empty")
+	}
+
+	if length > MaxUsernameLength {
+		return fmt.Errorf("username must be at most %d characters long", MaxUsernameLength)
+	}
+
+	if strings.IndexFunc(username, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return errors.New("username must not contain spaces or control characters")
+	}
+
+	return nil
+}
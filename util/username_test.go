@@ -0,0 +1,21 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUsername(t *testing.T) {
+	require.NoError(t, ValidateUsername(RandomUsername()))
+	require.NoError(t, ValidateUsername("jürgen.müller"))
+	require.NoError(t, ValidateUsername(strings.Repeat("ü", MaxUsernameLength)))
+
+	require.Error(t, ValidateUsername(""))
+	require.Error(t, ValidateUsername(strings.Repeat("a", MaxUsernameLength+1)))
+	require.Error(t, ValidateUsername("john doe"))
+	require.Error(t, ValidateUsername("admin "))
+	require.Error(t, ValidateUsername("admin\x00"))
+	require.Error(t, ValidateUsername("\xff"))
+}